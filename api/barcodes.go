@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// GTIN-14 is the longest barcode form we accept, and every shorter one (UPC-A, EAN-13)
+	// is stored left-padded with zeroes to that length so that they can all be looked up the same way
+	gtinLength = 14
+
+	completeProductRetrievalQueryByUPC  = `SELECT * FROM products WHERE upc = $1 AND archived_on IS NULL`
+	publishedProductRetrievalQueryByUPC = completeProductRetrievalQueryByUPC + ` AND ` + publishedProductCondition
+)
+
+// gtinCheckDigitIsValid checks the last digit of a barcode against the GS1 check digit of the digits before it.
+// Digits are weighted 3 and 1 alternately, starting with 3 from the right, which is why the same calculation
+// works for UPC-A, EAN-13, and GTIN-14, and for any of them padded with leading zeroes.
+func gtinCheckDigitIsValid(code string) bool {
+	sum := 0
+	body := code[:len(code)-1]
+	for i := range body {
+		digit := int(body[len(body)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return int(code[len(code)-1]-'0') == (10-sum%10)%10
+}
+
+// normalizeBarcode validates a UPC-A, EAN-13, or GTIN-14 barcode and returns it in its GTIN-14 form
+func normalizeBarcode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	switch len(code) {
+	case 12, 13, gtinLength:
+	default:
+		return "", fmt.Errorf("The barcode received (%s) isn't a valid UPC-A, EAN-13, or GTIN-14", code)
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("The barcode received (%s) should only contain digits", code)
+		}
+	}
+	if !gtinCheckDigitIsValid(code) {
+		return "", fmt.Errorf("The barcode received (%s) has an invalid check digit", code)
+	}
+	return strings.Repeat("0", gtinLength-len(code)) + code, nil
+}
+
+// normalizeProductUPC validates and normalizes a UPC received in a request, leaving empty ones alone
+func normalizeProductUPC(upc *string) error {
+	if *upc == "" {
+		return nil
+	}
+	normalized, err := normalizeBarcode(*upc)
+	if err != nil {
+		return err
+	}
+	*upc = normalized
+	return nil
+}
+
+func retrieveProductFromDBByBarcode(db *sqlx.DB, gtin string, publishedOnly bool) (Product, error) {
+	var p Product
+	query := completeProductRetrievalQueryByUPC
+	if publishedOnly {
+		query = publishedProductRetrievalQueryByUPC
+	}
+	err := db.Get(&p, query, gtin)
+	return p, err
+}
+
+func buildProductRetrievalByBarcodeHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductRetrievalByBarcodeHandler is a request handler that returns the product with a given UPC-A, EAN-13, or GTIN-14
+	return func(res http.ResponseWriter, req *http.Request) {
+		code := chi.URLParam(req, "code")
+		gtin, err := normalizeBarcode(code)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		currency, err := parseCurrencyParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		product, err := retrieveProductFromDBByBarcode(db, gtin, !sessionBelongsToAdmin(req, store))
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product barcode", gtin)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieving product from database")
+			return
+		}
+
+		products := []Product{product}
+		err = translateProducts(db, products, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product translations from the database")
+			return
+		}
+
+		err = attachReviewSummariesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product reviews from the database")
+			return
+		}
+
+		err = attachPriceTiersToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product price tiers from the database")
+			return
+		}
+
+		err = attachBundlesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundles from the database")
+			return
+		}
+		deriveProductAvailabilities(products)
+
+		err = applyCustomerGroupPrices(db, store, req, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group prices from the database")
+			return
+		}
+
+		err = convertProductPrices(db, products, currency)
+		if err != nil {
+			notifyOfCurrencyConversionFailure(res, err)
+			return
+		}
+
+		json.NewEncoder(res).Encode(products[0])
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setExpectationsForProductRetrievalByBarcode(mock sqlmock.Sqlmock, gtin string, publishedOnly bool, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	query := completeProductRetrievalQueryByUPC
+	if publishedOnly {
+		query = publishedProductRetrievalQueryByUPC
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(query) + "$").
+		WithArgs(gtin).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	t.Parallel()
+	validBarcodes := map[string]string{
+		"036000291452":   "00036000291452", // UPC-A
+		"4006381333931":  "04006381333931", // EAN-13
+		"10036000291459": "10036000291459", // GTIN-14
+		"00036000291452": "00036000291452",
+		" 036000291452 ": "00036000291452",
+	}
+	for in, expected := range validBarcodes {
+		actual, err := normalizeBarcode(in)
+		assert.Nil(t, err, "normalizeBarcode(%s) shouldn't return an error", in)
+		assert.Equal(t, expected, actual, "normalizeBarcode(%s) should return %s", in, expected)
+	}
+
+	invalidBarcodes := []string{
+		"",
+		"1234567890",
+		"036000291453",
+		"4006381333930",
+		"10036000291450",
+		"03600029145a",
+		"036000291452000",
+	}
+	for _, in := range invalidBarcodes {
+		_, err := normalizeBarcode(in)
+		assert.NotNil(t, err, "normalizeBarcode(%s) should return an error", in)
+	}
+}
+
+func TestNormalizeProductUPC(t *testing.T) {
+	t.Parallel()
+
+	upc := ""
+	assert.Nil(t, normalizeProductUPC(&upc), "empty UPCs should be left alone")
+	assert.Equal(t, "", upc)
+
+	upc = "036000291452"
+	assert.Nil(t, normalizeProductUPC(&upc))
+	assert.Equal(t, "00036000291452", upc)
+
+	upc = "036000291453"
+	assert.NotNil(t, normalizeProductUPC(&upc))
+	assert.Equal(t, "036000291453", upc, "invalid UPCs shouldn't be changed")
+}
+
+func TestProductRetrievalByBarcodeHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrievalByBarcode(testUtil.Mock, "00036000291452", true, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/barcode/036000291452", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.SKU, actual.SKU)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalByBarcodeHandlerForAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrievalByBarcode(testUtil.Mock, "10036000291459", false, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/barcode/10036000291459", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalByBarcodeHandlerWithInvalidBarcode(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/barcode/036000291453", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalByBarcodeHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrievalByBarcode(testUtil.Mock, "04006381333931", true, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/barcode/4006381333931", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalByBarcodeHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrievalByBarcode(testUtil.Mock, "04006381333931", true, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/barcode/4006381333931", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerWithInvalidUPC(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"sku": "skateboard", "name": "Skateboard", "price": 12.34, "upc": "1234567890"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerWithInvalidUPC(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPatch, "/v1/product/skateboard", strings.NewReader(`{"upc": "036000291453"}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithInvalidUPC(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+
+	body := `{"name": "Skateboard", "sku": "skateboard", "price": 99.99, "upc": "036000291453"}`
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson", "dry_run": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Failed, "products with invalid UPCs shouldn't be importable")
+	assert.Equal(t, "The barcode received (036000291453) has an invalid check digit", actual.Errors[0].Message)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const blobKeyPattern = `^[a-zA-Z0-9]+$`
+
+var blobKeyValidator = regexp.MustCompile(blobKeyPattern)
+
+// BlobStore is somewhere the files attached to digital products can be kept
+type BlobStore interface {
+	Put(key string, r io.Reader) (int64, error)
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalBlobStore is a BlobStore that keeps files in a directory on local disk
+type LocalBlobStore struct {
+	root string
+}
+
+// NewLocalBlobStore returns a LocalBlobStore rooted at the given directory, creating it if need be
+func NewLocalBlobStore(root string) (*LocalBlobStore, error) {
+	err := os.MkdirAll(root, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating blob directory")
+	}
+	return &LocalBlobStore{root: root}, nil
+}
+
+// keys are generated by us, but since they end up as file names, we refuse to touch anything that could walk out of the root
+func (s *LocalBlobStore) pathFor(key string) (string, error) {
+	if !blobKeyValidator.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key: `%s`", key)
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+// Put writes the contents of r to the blob with the given key, and returns how many bytes were written
+func (s *LocalBlobStore) Put(key string, r io.Reader) (int64, error) {
+	path, err := s.pathFor(key)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error creating blob")
+	}
+
+	size, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return 0, errors.Wrap(err, "Error writing blob")
+	}
+	return size, f.Close()
+}
+
+// Get opens the blob with the given key for reading
+func (s *LocalBlobStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.pathFor(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the blob with the given key. Deleting a blob that doesn't exist is not an error.
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.pathFor(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryBlobStore is a BlobStore for tests that keeps its blobs in a map
+type memoryBlobStore struct {
+	sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: map[string][]byte{}}
+}
+
+func (s *memoryBlobStore) Put(key string, r io.Reader) (int64, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.blobs[key] = b
+	return int64(len(b)), nil
+}
+
+func (s *memoryBlobStore) Get(key string) (io.ReadCloser, error) {
+	s.Lock()
+	defer s.Unlock()
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *memoryBlobStore) Delete(key string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "dairycart-blobs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalBlobStore(dir)
+	assert.Nil(t, err)
+
+	size, err := s.Put("example", strings.NewReader("example file contents"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), size, "Put should report how many bytes were written")
+
+	blob, err := s.Get("example")
+	assert.Nil(t, err)
+	contents, err := ioutil.ReadAll(blob)
+	blob.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "example file contents", string(contents))
+
+	assert.Nil(t, s.Delete("example"))
+	_, err = s.Get("example")
+	assert.True(t, os.IsNotExist(err), "deleted blobs should no longer exist")
+	assert.Nil(t, s.Delete("example"), "deleting a nonexistent blob should not be an error")
+}
+
+func TestLocalBlobStoreRejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "dairycart-blobs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalBlobStore(dir)
+	assert.Nil(t, err)
+
+	for _, key := range []string{"", "../escape", "sub/dir", "."} {
+		_, err = s.Put(key, strings.NewReader("nope"))
+		assert.NotNil(t, err, "`%s` should not be a valid blob key", key)
+		_, err = s.Get(key)
+		assert.NotNil(t, err, "`%s` should not be a valid blob key", key)
+		assert.NotNil(t, s.Delete(key), "`%s` should not be a valid blob key", key)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBaseCurrency = "USD"
+
+	exchangeRatesTableHeaders = `id,
+		currency,
+		rate,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	exchangeRateRetrievalQuery = `SELECT rate FROM exchange_rates WHERE currency = $1 AND archived_on IS NULL`
+	exchangeRateExistenceQuery = `SELECT EXISTS(SELECT 1 FROM exchange_rates WHERE currency = $1 AND archived_on IS NULL)`
+	exchangeRateDeletionQuery  = `UPDATE exchange_rates SET archived_on = NOW() WHERE currency = $1 AND archived_on IS NULL`
+)
+
+// baseCurrency is the currency every price in the products table is denominated in.
+// It's set at startup from the DAIRYCART_BASE_CURRENCY environment variable.
+var baseCurrency = defaultBaseCurrency
+
+// currencyRoundingIncrements maps the currencies we support to the smallest amount a price in that
+// currency can be. Most currencies deal in cents, but some have no minor unit at all (JPY), and
+// some have no coins small enough for a single cent (CHF).
+var currencyRoundingIncrements = map[string]Money{
+	"AUD": 1,
+	"BRL": 1,
+	"CAD": 1,
+	"CHF": 5,
+	"CNY": 1,
+	"CZK": 1,
+	"DKK": 1,
+	"EUR": 1,
+	"GBP": 1,
+	"HKD": 1,
+	"HUF": 100,
+	"INR": 1,
+	"ISK": 100,
+	"JPY": 100,
+	"KRW": 100,
+	"MXN": 1,
+	"NOK": 1,
+	"NZD": 1,
+	"PLN": 1,
+	"SEK": 1,
+	"SGD": 1,
+	"USD": 1,
+	"ZAR": 1,
+}
+
+// ExchangeRate is how much one unit of the base currency is worth in another currency
+type ExchangeRate struct {
+	DBRow
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`
+}
+
+func (r *ExchangeRate) generateScanArgs() []interface{} {
+	return []interface{}{
+		&r.ID,
+		&r.Currency,
+		&r.Rate,
+		&r.CreatedOn,
+		&r.UpdatedOn,
+		&r.ArchivedOn,
+	}
+}
+
+// ExchangeRateInput is a struct to use for setting an exchange rate
+type ExchangeRateInput struct {
+	Rate json.Number `json:"rate" validate:"required"`
+}
+
+// missingExchangeRateError is returned when prices are requested in a currency we have no way to convert to
+type missingExchangeRateError string
+
+func (e missingExchangeRateError) Error() string {
+	return fmt.Sprintf("no exchange rate available for currency `%s`", string(e))
+}
+
+func currencyIsSupported(currency string) bool {
+	_, ok := currencyRoundingIncrements[currency]
+	return ok
+}
+
+func setBaseCurrency(currency string) error {
+	currency = strings.ToUpper(currency)
+	if !currencyIsSupported(currency) {
+		return fmt.Errorf("unsupported base currency: `%s`", currency)
+	}
+	baseCurrency = currency
+	return nil
+}
+
+// parseCurrencyParam returns the currency a client wants prices in, or an empty string if they want the base currency
+func parseCurrencyParam(req *http.Request) (string, error) {
+	currency := strings.ToUpper(req.URL.Query().Get("currency"))
+	if currency == "" || currency == baseCurrency {
+		return "", nil
+	}
+	if !currencyIsSupported(currency) {
+		return "", fmt.Errorf("unsupported currency: `%s`", currency)
+	}
+	return currency, nil
+}
+
+func parseExchangeRate(in string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(in)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate: `%s`", in)
+	}
+	return rate, nil
+}
+
+// convertMoney converts an amount in the base currency with the given rate and rounds it the way the target currency expects
+func convertMoney(amount Money, rate *big.Rat, currency string) Money {
+	return amount.Mul(rate, RoundHalfUp).RoundToIncrement(currencyRoundingIncrements[currency], RoundHalfUp)
+}
+
+func retrieveExchangeRate(db *sqlx.DB, currency string) (*big.Rat, error) {
+	var rawRate string
+	err := db.QueryRow(exchangeRateRetrievalQuery, currency).Scan(&rawRate)
+	if err == sql.ErrNoRows {
+		return nil, missingExchangeRateError(currency)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving exchange rate")
+	}
+	return parseExchangeRate(rawRate)
+}
+
+// convertProductPrices rewrites the prices and cost of the given products in another currency.
+// Products with an explicit price in that currency use it as-is, unless a customer group price has
+// replaced the retail price, and their price tiers keep the same discount off of it; everything else
+// is converted from the base currency with the latest exchange rate.
+func convertProductPrices(db *sqlx.DB, products []Product, currency string) error {
+	if currency == "" || len(products) == 0 {
+		return nil
+	}
+
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+	explicitPrices, err := retrieveProductPricesForProducts(db, productIDs, currency)
+	if err != nil {
+		return err
+	}
+
+	// we only need the exchange rate if there's something without an explicit price
+	var rate *big.Rat
+	convert := func(amount Money) (Money, error) {
+		if rate == nil {
+			if rate, err = retrieveExchangeRate(db, currency); err != nil {
+				return 0, err
+			}
+		}
+		return convertMoney(amount, rate, currency), nil
+	}
+
+	for i := range products {
+		p := &products[i]
+		if explicit, ok := explicitPrices[p.ID]; ok && p.CustomerGroupID == 0 {
+			// tiers are priced relative to the retail price, so they follow the explicit price rather than the exchange rate
+			if p.Price > 0 {
+				ratio := big.NewRat(int64(explicit.Price), int64(p.Price))
+				for j := range p.PriceTiers {
+					p.PriceTiers[j].UnitPrice = convertMoney(p.PriceTiers[j].UnitPrice, ratio, currency)
+				}
+			} else {
+				for j := range p.PriceTiers {
+					if p.PriceTiers[j].UnitPrice, err = convert(p.PriceTiers[j].UnitPrice); err != nil {
+						return err
+					}
+				}
+			}
+			p.Price = explicit.Price
+			p.SalePrice = explicit.SalePrice
+		} else {
+			if p.Price, err = convert(p.Price); err != nil {
+				return err
+			}
+			if p.SalePrice, err = convert(p.SalePrice); err != nil {
+				return err
+			}
+			for j := range p.PriceTiers {
+				if p.PriceTiers[j].UnitPrice, err = convert(p.PriceTiers[j].UnitPrice); err != nil {
+					return err
+				}
+			}
+		}
+		// there's no explicit cost in other currencies, so it's always converted
+		if p.Cost, err = convert(p.Cost); err != nil {
+			return err
+		}
+		p.Currency = currency
+	}
+	return nil
+}
+
+// notifyOfCurrencyConversionFailure responds appropriately to an error from convertProductPrices
+func notifyOfCurrencyConversionFailure(res http.ResponseWriter, err error) {
+	if _, ok := err.(missingExchangeRateError); ok {
+		notifyOfInvalidRequestBody(res, err)
+		return
+	}
+	notifyOfInternalIssue(res, err, "convert product prices")
+}
+
+func buildExchangeRateListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ExchangeRateListHandler is a request handler that returns every exchange rate we know of
+	return func(res http.ResponseWriter, req *http.Request) {
+		rates := []ExchangeRate{}
+		query, args := buildExchangeRateListQuery()
+		err := retrieveListOfRowsFromDB(db, query, args, &rates)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve exchange rates from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(rates)
+	}
+}
+
+func buildExchangeRateUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ExchangeRateUpsertHandler is a request handler that sets the exchange rate for a currency
+	return func(res http.ResponseWriter, req *http.Request) {
+		currency := strings.ToUpper(chi.URLParam(req, "currency"))
+		if !currencyIsSupported(currency) || currency == baseCurrency {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("cannot set an exchange rate for currency `%s`", currency))
+			return
+		}
+
+		rateInput := &ExchangeRateInput{}
+		err := validateRequestInput(req, rateInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if _, err = parseExchangeRate(rateInput.Rate.String()); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		rate := &ExchangeRate{Currency: currency, Rate: rateInput.Rate.String()}
+		query, args := buildExchangeRateUpsertQuery(rate)
+		err = db.QueryRow(query, args...).Scan(rate.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save exchange rate in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(rate)
+	}
+}
+
+func buildExchangeRateDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ExchangeRateDeletionHandler is a request handler that removes the exchange rate for a currency
+	return func(res http.ResponseWriter, req *http.Request) {
+		currency := strings.ToUpper(chi.URLParam(req, "currency"))
+
+		exists, err := rowExistsInDB(db, exchangeRateExistenceQuery, currency)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "exchange rate", currency)
+			return
+		}
+
+		_, err = db.Exec(exchangeRateDeletionQuery, currency)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete exchange rate")
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var exchangeRateHeaders = strings.Split(strings.TrimSpace(exchangeRatesTableHeaders), ",\n\t\t")
+
+func setExpectationsForExchangeRateRetrieval(mock sqlmock.Sqlmock, currency string, rate string, err error) {
+	exampleRows := sqlmock.NewRows([]string{"rate"}).AddRow(rate)
+	mock.ExpectQuery(formatQueryForSQLMock(exchangeRateRetrievalQuery)).
+		WithArgs(currency).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForExchangeRateUpsert(mock sqlmock.Sqlmock, r *ExchangeRate, err error) {
+	exampleRows := sqlmock.NewRows(exchangeRateHeaders).
+		AddRow(1, r.Currency, r.Rate, generateExampleTimeForTests(), nil, nil)
+	query, args := buildExchangeRateUpsertQuery(r)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestParseCurrencyParam(t *testing.T) {
+	t.Parallel()
+	inputOutputMap := map[string]string{
+		"":    "",
+		"usd": "",
+		"eur": "EUR",
+		"JPY": "JPY",
+	}
+
+	for input, expected := range inputOutputMap {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/products?currency=%s", input), nil)
+		assert.Nil(t, err)
+		actual, err := parseCurrencyParam(req)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "currency param `%s` should be parsed as `%s`", input, expected)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?currency=DOGE", nil)
+	assert.Nil(t, err)
+	_, err = parseCurrencyParam(req)
+	assert.NotNil(t, err, "unsupported currencies should be rejected")
+}
+
+func TestParseExchangeRate(t *testing.T) {
+	t.Parallel()
+	actual, err := parseExchangeRate("0.91230000")
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewRat(9123, 10000), actual)
+
+	for _, input := range []string{"", "0", "-1.5", "lots"} {
+		_, err = parseExchangeRate(input)
+		assert.NotNil(t, err, "`%s` shouldn't be a valid exchange rate", input)
+	}
+}
+
+func TestConvertMoney(t *testing.T) {
+	t.Parallel()
+	rate := big.NewRat(9123, 10000)
+	assert.Equal(t, Money(9122), convertMoney(9999, rate, "EUR"), "99.99 * 0.9123 should round to 91.22")
+	assert.Equal(t, Money(9120), convertMoney(9999, rate, "CHF"), "francs should be rounded to the nearest five cents")
+	assert.Equal(t, Money(1110900), convertMoney(9999, big.NewRat(1111, 10), "JPY"), "yen shouldn't have any cents")
+}
+
+func TestConvertProductPrices(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	otherProduct := *exampleProduct
+	otherProduct.ID = 3
+	products := []Product{*exampleProduct, otherProduct}
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID, otherProduct.ID}, "EUR", &ProductPrice{ProductID: otherProduct.ID, Currency: "EUR", Price: 8000}, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.91230000", nil)
+
+	err := convertProductPrices(testUtil.DB, products, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, Money(9122), products[0].Price, "products without an explicit price should be converted")
+	assert.Equal(t, Money(8000), products[1].Price, "products with an explicit price should use it")
+	assert.Equal(t, "EUR", products[0].Currency)
+	assert.Equal(t, Money(4562), products[0].Cost, "cost should be converted along with the prices")
+	assert.Equal(t, Money(4562), products[1].Cost, "cost should be converted even when there's an explicit price")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestConvertProductPricesWithExplicitPriceAndPriceTiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	p := *exampleProduct
+	p.PriceTiers = []ProductPriceTier{{ProductID: p.ID, MinQuantity: 10, UnitPrice: 9000}}
+	products := []Product{p}
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{p.ID}, "EUR", &ProductPrice{ProductID: p.ID, Currency: "EUR", Price: 8000}, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.91230000", nil)
+
+	err := convertProductPrices(testUtil.DB, products, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, Money(8000), products[0].Price)
+	assert.Equal(t, Money(7201), products[0].PriceTiers[0].UnitPrice, "tiers should keep the same discount off of the explicit price")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestConvertProductPricesWithoutExchangeRate(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	products := []Product{*exampleProduct}
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "EUR", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "", sql.ErrNoRows)
+
+	err := convertProductPrices(testUtil.DB, products, "EUR")
+	assert.Equal(t, missingExchangeRateError("EUR"), err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "EUR", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.91230000", nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?currency=eur", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(9122), actual.Price)
+	assert.Equal(t, "EUR", actual.Currency)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithUnsupportedCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?currency=DOGE", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithCurrencyMissingExchangeRate(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productIDs := []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}
+	setExpectationsForRowCount(testUtil.Mock, "products", publishedQueryFilter, 3, nil)
+	setExpectationsForProductListQuery(testUtil.Mock, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, productIDs, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, productIDs, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, productIDs, nil, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, productIDs, "GBP", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "GBP", "", sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?currency=GBP", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleRows := sqlmock.NewRows(exchangeRateHeaders).
+		AddRow(1, "EUR", "0.91230000", generateExampleTimeForTests(), nil, nil)
+	query, _ := buildExchangeRateListQuery()
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/exchange_rates", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ExchangeRate{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.91230000", actual[0].Rate)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForExchangeRateUpsert(testUtil.Mock, &ExchangeRate{Currency: "EUR", Rate: "0.9123"}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, "/v1/exchange_rates/eur", strings.NewReader(`{"rate": 0.9123}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateUpsertHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"/v1/exchange_rates/USD":  `{"rate": 1}`,
+		"/v1/exchange_rates/DOGE": `{"rate": 1}`,
+		"/v1/exchange_rates/EUR":  `{"rate": -1}`,
+	}
+
+	for path, body := range testCases {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "setting an exchange rate at %s to %s should respond 400", path, body)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestExchangeRateUpsertHandlerForNonAdminUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, "/v1/exchange_rates/EUR", strings.NewReader(`{"rate": 0.9123}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateUpsertHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForExchangeRateUpsert(testUtil.Mock, &ExchangeRate{Currency: "EUR", Rate: "0.9123"}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPut, "/v1/exchange_rates/EUR", strings.NewReader(`{"rate": "0.9123"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(exchangeRateExistenceQuery)).
+		WithArgs("EUR").
+		WillReturnRows(sqlmock.NewRows([]string{""}).AddRow("true"))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(exchangeRateDeletionQuery)).
+		WithArgs("EUR").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/exchange_rates/EUR", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExchangeRateDeletionHandlerForNonexistentRate(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(exchangeRateExistenceQuery)).
+		WithArgs("EUR").
+		WillReturnRows(sqlmock.NewRows([]string{""}).AddRow("false"))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/exchange_rates/EUR", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
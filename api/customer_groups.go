@@ -0,0 +1,470 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/imdario/mergo"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	customerGroupsTableHeaders = `id,
+		name,
+		description,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	customerGroupPricesTableHeaders = `id,
+		customer_group_id,
+		product_id,
+		price,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	customerGroupRetrievalQuery = `SELECT * FROM customer_groups WHERE id = $1 AND archived_on IS NULL`
+	customerGroupExistenceQuery = `SELECT EXISTS(SELECT 1 FROM customer_groups WHERE id = $1 AND archived_on IS NULL)`
+	customerGroupDeletionQuery  = `UPDATE customer_groups SET archived_on = NOW() WHERE id = $1 AND archived_on IS NULL`
+
+	customerGroupMembershipUpsertQuery = `
+		INSERT INTO customer_group_members (customer_group_id, user_id) VALUES ($1, $2)
+		ON CONFLICT ("user_id") DO UPDATE SET customer_group_id = EXCLUDED.customer_group_id, updated_on = NOW(), archived_on = NULL
+	`
+	customerGroupMembershipDeletionQuery = `UPDATE customer_group_members SET archived_on = NOW() WHERE customer_group_id = $1 AND user_id = $2 AND archived_on IS NULL`
+	customerGroupIDRetrievalQueryForUser = `
+		SELECT m.customer_group_id FROM customer_group_members m
+			JOIN customer_groups g ON g.id = m.customer_group_id
+			WHERE m.user_id = $1
+			AND m.archived_on IS NULL
+			AND g.archived_on IS NULL
+	`
+
+	customerGroupPriceDeletionQuery = `UPDATE customer_group_prices SET archived_on = NOW() WHERE customer_group_id = $1 AND product_id = $2 AND archived_on IS NULL`
+)
+
+// CustomerGroup is a set of users (like wholesale buyers or staff) that can be given their own prices
+type CustomerGroup struct {
+	DBRow
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (g *CustomerGroup) generateScanArgs() []interface{} {
+	return []interface{}{
+		&g.ID,
+		&g.Name,
+		&g.Description,
+		&g.CreatedOn,
+		&g.UpdatedOn,
+		&g.ArchivedOn,
+	}
+}
+
+// CustomerGroupsResponse is a customer group response struct
+type CustomerGroupsResponse struct {
+	ListResponse
+	Data []CustomerGroup `json:"data"`
+}
+
+// CustomerGroupCreationInput is a struct to use for creating customer groups
+type CustomerGroupCreationInput struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// CustomerGroupUpdateInput is a struct to use for updating customer groups
+type CustomerGroupUpdateInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CustomerGroupPrice is the price a customer group pays for a product, in place of its retail price
+type CustomerGroupPrice struct {
+	DBRow
+	CustomerGroupID uint64 `json:"customer_group_id"`
+	ProductID       uint64 `json:"product_id"`
+	Price           Money  `json:"price"`
+}
+
+func (gp *CustomerGroupPrice) generateScanArgs() []interface{} {
+	return []interface{}{
+		&gp.ID,
+		&gp.CustomerGroupID,
+		&gp.ProductID,
+		&gp.Price,
+		&gp.CreatedOn,
+		&gp.UpdatedOn,
+		&gp.ArchivedOn,
+	}
+}
+
+// CustomerGroupPriceInput is a struct to use for setting a customer group's price for a product
+type CustomerGroupPriceInput struct {
+	Price Money `json:"price" validate:"required"`
+}
+
+func retrieveCustomerGroupFromDB(db *sqlx.DB, groupID string) (CustomerGroup, error) {
+	var g CustomerGroup
+	err := db.Get(&g, customerGroupRetrievalQuery, groupID)
+	return g, err
+}
+
+// retrieveCustomerGroupIDForUser returns the ID of the group a user belongs to, or zero if they don't belong to one
+func retrieveCustomerGroupIDForUser(db *sqlx.DB, userID uint64) (uint64, error) {
+	var groupID uint64
+	err := db.QueryRow(customerGroupIDRetrievalQueryForUser, userID).Scan(&groupID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return groupID, err
+}
+
+// retrieveCustomerGroupPrices retrieves a group's prices for the given products, or its whole price list if productIDs is nil
+func retrieveCustomerGroupPrices(db *sqlx.DB, groupID uint64, productIDs []uint64) ([]CustomerGroupPrice, error) {
+	prices := []CustomerGroupPrice{}
+	query, args := buildCustomerGroupPriceListQuery(groupID, productIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for customer group prices")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var gp CustomerGroupPrice
+		err = rows.Scan(gp.generateScanArgs()...)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning customer group price")
+		}
+		prices = append(prices, gp)
+	}
+	return prices, rows.Err()
+}
+
+// applyCustomerGroupPrices replaces the retail price of the given products with the price from the price list
+// of the logged in user's customer group. Anonymous requests, and users who aren't in a group, get retail prices.
+func applyCustomerGroupPrices(db *sqlx.DB, store *sessions.CookieStore, req *http.Request, products []Product) error {
+	userID, ok := retrieveUserIDFromSession(req, store)
+	if !ok || len(products) == 0 {
+		return nil
+	}
+
+	groupID, err := retrieveCustomerGroupIDForUser(db, userID)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving customer group for user")
+	} else if groupID == 0 {
+		return nil
+	}
+
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+	groupPrices, err := retrieveCustomerGroupPrices(db, groupID, productIDs)
+	if err != nil {
+		return err
+	}
+
+	pricesByProduct := map[uint64]CustomerGroupPrice{}
+	for _, gp := range groupPrices {
+		pricesByProduct[gp.ProductID] = gp
+	}
+	for i := range products {
+		if gp, ok := pricesByProduct[products[i].ID]; ok {
+			products[i].Price = gp.Price
+			products[i].CustomerGroupID = groupID
+		}
+	}
+	return nil
+}
+
+func buildCustomerGroupListHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupListHandler is a request handler that returns a list of customer groups
+	return func(res http.ResponseWriter, req *http.Request) {
+		rawFilterParams := req.URL.Query()
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		var count uint64
+		if !queryFilter.SkipCount {
+			count, err = getRowCount(db, "customer_groups", queryFilter)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve count of customer groups from the database")
+				return
+			}
+		}
+
+		var groups []CustomerGroup
+		query, args := buildCustomerGroupListQuery(queryFilter)
+		err = retrieveListOfRowsFromDB(db, query, args, &groups)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer groups from the database")
+			return
+		}
+
+		groupsResponse := &CustomerGroupsResponse{
+			ListResponse: newListResponse(queryFilter, count),
+			Data:         groups,
+		}
+		if len(groups) > 0 {
+			groupsResponse.NextCursor = buildNextCursor(queryFilter, len(groups), groups[len(groups)-1].DBRow)
+		}
+		json.NewEncoder(res).Encode(groupsResponse)
+	}
+}
+
+func buildCustomerGroupRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupRetrievalHandler is a request handler that returns a single customer group
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+
+		group, err := retrieveCustomerGroupFromDB(db, groupID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group from database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(group)
+	}
+}
+
+func buildCustomerGroupCreationHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupCreationHandler is a request handler that creates a customer group from user input
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupInput := &CustomerGroupCreationInput{}
+		err := validateRequestInput(req, groupInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		group := &CustomerGroup{Name: groupInput.Name, Description: groupInput.Description}
+		query, args := buildCustomerGroupCreationQuery(group)
+		err = db.QueryRow(query, args...).Scan(group.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "insert customer group into database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(group)
+	}
+}
+
+func buildCustomerGroupUpdateHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupUpdateHandler is a request handler that updates a customer group's name and description
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+
+		groupInput := &CustomerGroupUpdateInput{}
+		err := validateRequestInput(req, groupInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		existingGroup, err := retrieveCustomerGroupFromDB(db, groupID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group from database")
+			return
+		}
+
+		updatedGroup := &CustomerGroup{Name: groupInput.Name, Description: groupInput.Description}
+		// eating the error here because we've already validated input
+		mergo.Merge(updatedGroup, &existingGroup)
+
+		query, args := buildCustomerGroupUpdateQuery(updatedGroup)
+		err = db.QueryRow(query, args...).Scan(updatedGroup.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update customer group in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(updatedGroup)
+	}
+}
+
+func buildCustomerGroupDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupDeletionHandler is a request handler that archives a customer group. Its
+	// members and price list are left in place, but stop applying once the group is gone.
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+
+		exists, err := rowExistsInDB(db, customerGroupExistenceQuery, groupID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		}
+
+		_, err = db.Exec(customerGroupDeletionQuery, groupID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive customer group")
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildCustomerGroupMembershipHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupMembershipHandler is a request handler that puts a user in a customer group.
+	// Users can only belong to one group, so this moves them out of any group they were already in.
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+		userID := chi.URLParam(req, "user_id")
+
+		exists, err := rowExistsInDB(db, customerGroupExistenceQuery, groupID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		}
+
+		exists, err = rowExistsInDB(db, userExistenceQueryByID, userID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "user", userID)
+			return
+		}
+
+		_, err = db.Exec(customerGroupMembershipUpsertQuery, groupID, userID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "add user to customer group")
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildCustomerGroupMembershipDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupMembershipDeletionHandler is a request handler that removes a user from a customer group
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+		userID := chi.URLParam(req, "user_id")
+
+		result, err := db.Exec(customerGroupMembershipDeletionQuery, groupID, userID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "remove user from customer group")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "customer group member", userID)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildCustomerGroupPriceListHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupPriceListHandler is a request handler that returns a customer group's price list
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+
+		exists, err := rowExistsInDB(db, customerGroupExistenceQuery, groupID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		}
+
+		// we can eat this error because Mux takes care of validating route params for us
+		parsedGroupID, _ := strconv.ParseUint(groupID, 10, 64)
+		prices, err := retrieveCustomerGroupPrices(db, parsedGroupID, nil)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group prices from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(prices)
+	}
+}
+
+func buildCustomerGroupPriceUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupPriceUpsertHandler is a request handler that sets what a customer group pays for a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+		sku := chi.URLParam(req, "sku")
+
+		priceInput := &CustomerGroupPriceInput{}
+		err := validateRequestInput(req, priceInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		exists, err := rowExistsInDB(db, customerGroupExistenceQuery, groupID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "customer group", groupID)
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		// we can eat this error because Mux takes care of validating route params for us
+		parsedGroupID, _ := strconv.ParseUint(groupID, 10, 64)
+		price := &CustomerGroupPrice{
+			CustomerGroupID: parsedGroupID,
+			ProductID:       productID,
+			Price:           priceInput.Price,
+		}
+		query, args := buildCustomerGroupPriceUpsertQuery(price)
+		err = db.QueryRow(query, args...).Scan(price.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save customer group price in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(price)
+	}
+}
+
+func buildCustomerGroupPriceDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// CustomerGroupPriceDeletionHandler is a request handler that removes a product from a customer group's
+	// price list, after which the group pays the retail price for it again
+	return func(res http.ResponseWriter, req *http.Request) {
+		groupID := chi.URLParam(req, "customer_group_id")
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		result, err := db.Exec(customerGroupPriceDeletionQuery, groupID, productID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete customer group price")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "customer group price", sku)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
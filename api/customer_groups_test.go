@@ -0,0 +1,472 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	customerGroupHeaders      = strings.Split(strings.TrimSpace(customerGroupsTableHeaders), ",\n\t\t")
+	customerGroupPriceHeaders = strings.Split(strings.TrimSpace(customerGroupPricesTableHeaders), ",\n\t\t")
+	exampleCustomerGroup      = &CustomerGroup{
+		DBRow: DBRow{
+			ID:        3,
+			CreatedOn: generateExampleTimeForTests(),
+		},
+		Name:        "wholesale",
+		Description: "people who buy a lot of cheese",
+	}
+)
+
+func setExpectationsForCustomerGroupExistence(mock sqlmock.Sqlmock, id string, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(customerGroupExistenceQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForCustomerGroupRetrieval(mock sqlmock.Sqlmock, id string, err error) {
+	exampleRows := sqlmock.NewRows(customerGroupHeaders).
+		AddRow(exampleCustomerGroup.ID, exampleCustomerGroup.Name, exampleCustomerGroup.Description, exampleCustomerGroup.CreatedOn, nil, nil)
+	mock.ExpectQuery(formatQueryForSQLMock(customerGroupRetrievalQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForCustomerGroupIDRetrievalForUser(mock sqlmock.Sqlmock, userID uint64, groupID uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"customer_group_id"}).AddRow(groupID)
+	mock.ExpectQuery(formatQueryForSQLMock(customerGroupIDRetrievalQueryForUser)).
+		WithArgs(userID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForCustomerGroupPriceList(mock sqlmock.Sqlmock, groupID uint64, productIDs []uint64, prices []CustomerGroupPrice, err error) {
+	exampleRows := sqlmock.NewRows(customerGroupPriceHeaders)
+	for _, gp := range prices {
+		exampleRows = exampleRows.AddRow(gp.ID, gp.CustomerGroupID, gp.ProductID, gp.Price.String(), generateExampleTimeForTests(), nil, nil)
+	}
+	query, _ := buildCustomerGroupPriceListQuery(groupID, productIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductRetrievalHandlerForCustomerGroupMember(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	groupPrices := []CustomerGroupPrice{{CustomerGroupID: exampleCustomerGroup.ID, ProductID: exampleProduct.ID, Price: 7500}}
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, exampleCustomerGroup.ID, nil)
+	setExpectationsForCustomerGroupPriceList(testUtil.Mock, exampleCustomerGroup.ID, []uint64{exampleProduct.ID}, groupPrices, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(7500), actual.Price, "customer group members should see their group's price")
+	assert.Equal(t, exampleCustomerGroup.ID, actual.CustomerGroupID)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerForUserWithoutCustomerGroup(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(customerGroupIDRetrievalQueryForUser)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"customer_group_id"}))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.Price, actual.Price, "users outside of a customer group should see the retail price")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithErrorRetrievingCustomerGroup(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, 0, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerForCustomerGroupMemberWithCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	groupPrices := []CustomerGroupPrice{{CustomerGroupID: exampleCustomerGroup.ID, ProductID: exampleProduct.ID, Price: 7500}}
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, exampleCustomerGroup.ID, nil)
+	setExpectationsForCustomerGroupPriceList(testUtil.Mock, exampleCustomerGroup.ID, []uint64{exampleProduct.ID}, groupPrices, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "EUR", &ProductPrice{ProductID: exampleProduct.ID, Currency: "EUR", Price: 8000}, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.50000000", nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?currency=EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(3750), actual.Price, "group prices should be converted rather than replaced by the explicit retail price")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForRowCount(testUtil.Mock, "customer_groups", defaultQueryFilter, 1, nil)
+	exampleRows := sqlmock.NewRows(customerGroupHeaders).
+		AddRow(exampleCustomerGroup.ID, exampleCustomerGroup.Name, exampleCustomerGroup.Description, exampleCustomerGroup.CreatedOn, nil, nil)
+	query, _ := buildCustomerGroupListQuery(defaultQueryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/customer_groups", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &CustomerGroupsResponse{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), *actual.Count)
+	assert.Equal(t, exampleCustomerGroup.Name, actual.Data[0].Name)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupListHandlerForNonAdminUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/customer_groups", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupRetrievalHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupRetrieval(testUtil.Mock, "3", nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/customer_groups/3", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupRetrievalHandlerForNonexistentGroup(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(customerGroupRetrievalQuery)).
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows(customerGroupHeaders))
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/customer_groups/3", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	newGroup := &CustomerGroup{Name: exampleCustomerGroup.Name, Description: exampleCustomerGroup.Description}
+	exampleRows := sqlmock.NewRows(customerGroupHeaders).
+		AddRow(exampleCustomerGroup.ID, exampleCustomerGroup.Name, exampleCustomerGroup.Description, exampleCustomerGroup.CreatedOn, nil, nil)
+	query, args := buildCustomerGroupCreationQuery(newGroup)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows)
+
+	body := `{"name": "wholesale", "description": "people who buy a lot of cheese"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/customer_groups", strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupCreationHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/customer_groups", strings.NewReader(`{"description": "nameless"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupUpdateHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupRetrieval(testUtil.Mock, "3", nil)
+	updatedGroup := &CustomerGroup{DBRow: exampleCustomerGroup.DBRow, Name: "staff", Description: exampleCustomerGroup.Description}
+	exampleRows := sqlmock.NewRows(customerGroupHeaders).
+		AddRow(updatedGroup.ID, updatedGroup.Name, updatedGroup.Description, updatedGroup.CreatedOn, generateExampleTimeForTests(), nil)
+	query, args := buildCustomerGroupUpdateQuery(updatedGroup)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodPatch, "/v1/customer_groups/3", strings.NewReader(`{"name": "staff"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &CustomerGroup{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "staff", actual.Name)
+	assert.Equal(t, exampleCustomerGroup.Description, actual.Description, "fields left out of the update should keep their values")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", true, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupDeletionQuery)).
+		WithArgs("3").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/customer_groups/3", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupDeletionHandlerForNonexistentGroup(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", false, nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/customer_groups/3", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupMembershipHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", true, nil)
+	setExpectationsForUserExistenceByID(testUtil.Mock, "7", true, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupMembershipUpsertQuery)).
+		WithArgs("3", "7").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodPut, "/v1/customer_groups/3/members/7", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupMembershipHandlerForNonexistentUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", true, nil)
+	setExpectationsForUserExistenceByID(testUtil.Mock, "7", false, nil)
+
+	req, err := http.NewRequest(http.MethodPut, "/v1/customer_groups/3/members/7", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupMembershipDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupMembershipDeletionQuery)).
+		WithArgs("3", "7").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/customer_groups/3/members/7", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupMembershipDeletionHandlerForNonMember(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupMembershipDeletionQuery)).
+		WithArgs("3", "7").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/customer_groups/3/members/7", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupPriceListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	groupPrices := []CustomerGroupPrice{{CustomerGroupID: exampleCustomerGroup.ID, ProductID: exampleProduct.ID, Price: 7500}}
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", true, nil)
+	setExpectationsForCustomerGroupPriceList(testUtil.Mock, exampleCustomerGroup.ID, nil, groupPrices, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/customer_groups/3/prices", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []CustomerGroupPrice{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(7500), actual[0].Price)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupPriceUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", true, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	price := &CustomerGroupPrice{CustomerGroupID: exampleCustomerGroup.ID, ProductID: exampleProduct.ID, Price: 7500}
+	exampleRows := sqlmock.NewRows(customerGroupPriceHeaders).
+		AddRow(1, price.CustomerGroupID, price.ProductID, price.Price.String(), generateExampleTimeForTests(), nil, nil)
+	query, args := buildCustomerGroupPriceUpsertQuery(price)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/customer_groups/3/prices/%s", exampleProduct.SKU), strings.NewReader(`{"price": 75}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupPriceUpsertHandlerForNonexistentGroup(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForCustomerGroupExistence(testUtil.Mock, "3", false, nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/customer_groups/3/prices/%s", exampleProduct.SKU), strings.NewReader(`{"price": 75}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupPriceDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupPriceDeletionQuery)).
+		WithArgs("3", exampleProduct.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/customer_groups/3/prices/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCustomerGroupPriceDeletionHandlerForNonexistentPrice(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(customerGroupPriceDeletionQuery)).
+		WithArgs("3", exampleProduct.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/customer_groups/3/prices/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
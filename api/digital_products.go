@@ -0,0 +1,368 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	productFilesTableHeaders = `id,
+		product_id,
+		blob_key,
+		filename,
+		content_type,
+		size,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	downloadGrantsTableHeaders = `id,
+		token,
+		product_id,
+		user_id,
+		expires_on,
+		max_downloads,
+		download_count,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	blobKeySize       = 1 << 5
+	downloadTokenSize = 1 << 6
+
+	defaultDownloadGrantTTL     = 72 * time.Hour
+	defaultDownloadGrantMaximum = 5
+
+	productFileRetrievalQuery = `SELECT ` + productFilesTableHeaders + ` FROM product_files WHERE product_id = $1 AND archived_on IS NULL`
+	productFileExistenceQuery = `SELECT EXISTS(SELECT 1 FROM product_files WHERE product_id = $1 AND archived_on IS NULL)`
+	// redeeming a grant has to check and count in one statement, or two simultaneous requests could both squeeze in under the limit
+	downloadGrantRedemptionQuery = `
+		UPDATE download_grants SET download_count = download_count + 1, updated_on = NOW()
+		WHERE token = $1 AND archived_on IS NULL AND expires_on > NOW() AND download_count < max_downloads
+		RETURNING product_id
+	`
+)
+
+// downloadSigningKey is what download URLs are signed with. It's set at startup from the app secret.
+var downloadSigningKey []byte
+
+func setDownloadSigningKey(key []byte) {
+	downloadSigningKey = key
+}
+
+// ProductFile is the file a customer receives when they buy a digital product
+type ProductFile struct {
+	DBRow
+	ProductID   uint64 `json:"product_id"`
+	BlobKey     string `json:"-"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func (f *ProductFile) generateScanArgs() []interface{} {
+	return []interface{}{
+		&f.ID,
+		&f.ProductID,
+		&f.BlobKey,
+		&f.Filename,
+		&f.ContentType,
+		&f.Size,
+		&f.CreatedOn,
+		&f.UpdatedOn,
+		&f.ArchivedOn,
+	}
+}
+
+// DownloadGrant entitles a user to download a digital product's file a limited number of times before it expires
+type DownloadGrant struct {
+	DBRow
+	Token         string    `json:"token"`
+	ProductID     uint64    `json:"product_id"`
+	UserID        uint64    `json:"user_id"`
+	ExpiresOn     time.Time `json:"expires_on"`
+	MaxDownloads  uint32    `json:"max_downloads"`
+	DownloadCount uint32    `json:"download_count"`
+
+	// URL is the signed link the user can download the file from. It isn't stored.
+	URL string `json:"url,omitempty"`
+}
+
+func (g *DownloadGrant) generateScanArgs() []interface{} {
+	return []interface{}{
+		&g.ID,
+		&g.Token,
+		&g.ProductID,
+		&g.UserID,
+		&g.ExpiresOn,
+		&g.MaxDownloads,
+		&g.DownloadCount,
+		&g.CreatedOn,
+		&g.UpdatedOn,
+		&g.ArchivedOn,
+	}
+}
+
+// DownloadGrantCreationInput is a struct to use for granting a user access to a digital product's file
+type DownloadGrantCreationInput struct {
+	UserID uint64 `json:"user_id" validate:"required"`
+	// TTL is how many seconds the grant is good for, and defaults to three days
+	TTL uint32 `json:"ttl"`
+	// MaxDownloads defaults to five
+	MaxDownloads uint32 `json:"max_downloads"`
+}
+
+func signDownload(token string, expires int64) string {
+	mac := hmac.New(sha256.New, downloadSigningKey)
+	fmt.Fprintf(mac, "%s:%d", token, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func buildDownloadURL(token string, expiresOn time.Time) string {
+	expires := expiresOn.Unix()
+	return fmt.Sprintf("/download/%s?expires=%d&signature=%s", token, expires, signDownload(token, expires))
+}
+
+// downloadSignatureIsValid checks that a download URL was issued by us and hasn't expired
+func downloadSignatureIsValid(token string, rawExpires string, signature string, now time.Time) bool {
+	expires, err := strconv.ParseInt(rawExpires, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signDownload(token, expires)))
+}
+
+func retrieveProductFile(db sqlx.Queryer, productID uint64) (*ProductFile, error) {
+	f := &ProductFile{}
+	err := db.QueryRowx(productFileRetrievalQuery, productID).Scan(f.generateScanArgs()...)
+	return f, err
+}
+
+func filenameIsValid(filename string) bool {
+	return filename != "" && !strings.ContainsAny(filename, "\"/\\\r\n")
+}
+
+func notifyOfUnavailableDownload(res http.ResponseWriter) {
+	res.WriteHeader(http.StatusGone)
+	errRes := &ErrorResponse{
+		Status:  http.StatusGone,
+		Message: "This download link has expired or has been used too many times",
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
+
+func buildProductFileUploadHandler(db *sqlx.DB, blobs BlobStore) http.HandlerFunc {
+	// ProductFileUploadHandler is a request handler that attaches the request body to a digital product as its downloadable file
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		filename := req.URL.Query().Get("filename")
+		if !filenameIsValid(filename) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("invalid filename: `%s`", filename))
+			return
+		}
+		contentType := req.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		product, err := retrieveProductFromDB(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+		if !product.Digital {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("product `%s` is not a digital product", sku))
+			return
+		}
+
+		previous, err := retrieveProductFile(db, product.ID)
+		if err == sql.ErrNoRows {
+			previous = nil
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product file from the database")
+			return
+		}
+
+		file := &ProductFile{
+			ProductID:   product.ID,
+			BlobKey:     uniuri.NewLen(blobKeySize),
+			Filename:    filename,
+			ContentType: contentType,
+		}
+		file.Size, err = blobs.Put(file.BlobKey, req.Body)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "store product file")
+			return
+		}
+
+		query, args := buildProductFileUpsertQuery(file)
+		err = db.QueryRow(query, args...).Scan(file.generateScanArgs()...)
+		if err != nil {
+			blobs.Delete(file.BlobKey)
+			notifyOfInternalIssue(res, err, "save product file in database")
+			return
+		}
+
+		if previous != nil {
+			if err = blobs.Delete(previous.BlobKey); err != nil {
+				log.Printf("error encountered removing replaced file for product %d: %v", product.ID, err)
+			}
+		}
+
+		json.NewEncoder(res).Encode(file)
+	}
+}
+
+func buildDownloadGrantCreationHandler(db *sqlx.DB) http.HandlerFunc {
+	// DownloadGrantCreationHandler is a request handler that issues a user a signed link to a digital product's file
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		grantInput := &DownloadGrantCreationInput{}
+		err := validateRequestInput(req, grantInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		product, err := retrieveProductFromDB(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+		if !product.Digital {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("product `%s` is not a digital product", sku))
+			return
+		}
+
+		hasFile, err := rowExistsInDB(db, productFileExistenceQuery, strconv.FormatUint(product.ID, 10))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "check for product file in the database")
+			return
+		}
+		if !hasFile {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("product `%s` has no file to download", sku))
+			return
+		}
+
+		userID := strconv.FormatUint(grantInput.UserID, 10)
+		userExists, err := rowExistsInDB(db, userExistenceQueryByID, userID)
+		if err != nil || !userExists {
+			respondThatRowDoesNotExist(req, res, "user", userID)
+			return
+		}
+
+		ttl := defaultDownloadGrantTTL
+		if grantInput.TTL != 0 {
+			ttl = time.Duration(grantInput.TTL) * time.Second
+		}
+		maxDownloads := uint32(defaultDownloadGrantMaximum)
+		if grantInput.MaxDownloads != 0 {
+			maxDownloads = grantInput.MaxDownloads
+		}
+
+		grant := &DownloadGrant{
+			Token:        uniuri.NewLen(downloadTokenSize),
+			ProductID:    product.ID,
+			UserID:       grantInput.UserID,
+			ExpiresOn:    time.Now().UTC().Add(ttl).Truncate(time.Second),
+			MaxDownloads: maxDownloads,
+		}
+		query, args := buildDownloadGrantCreationQuery(grant)
+		err = db.QueryRow(query, args...).Scan(grant.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save download grant in database")
+			return
+		}
+		grant.URL = buildDownloadURL(grant.Token, grant.ExpiresOn)
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(grant)
+	}
+}
+
+func buildDownloadHandler(db *sqlx.DB, blobs BlobStore) http.HandlerFunc {
+	// DownloadHandler is a request handler that streams a digital product's file to the holder of a signed download link
+	return func(res http.ResponseWriter, req *http.Request) {
+		token := chi.URLParam(req, "token")
+		query := req.URL.Query()
+		if !downloadSignatureIsValid(token, query.Get("expires"), query.Get("signature"), time.Now()) {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+
+		// the grant is only redeemed for good once we know there's a file to hand over, so that a
+		// missing file or a blob store hiccup doesn't use up one of the customer's downloads
+		tx, err := db.Beginx()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		var productID uint64
+		err = tx.QueryRow(downloadGrantRedemptionQuery, token).Scan(&productID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			notifyOfUnavailableDownload(res)
+			return
+		} else if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "redeem download grant")
+			return
+		}
+
+		file, err := retrieveProductFile(tx, productID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			respondThatRowDoesNotExist(req, res, "product file", strconv.FormatUint(productID, 10))
+			return
+		} else if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "retrieve product file from the database")
+			return
+		}
+
+		blob, err := blobs.Get(file.BlobKey)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, errors.Wrap(err, "Error opening product file"), "retrieve product file")
+			return
+		}
+		defer blob.Close()
+
+		if err = tx.Commit(); err != nil {
+			notifyOfInternalIssue(res, err, "closing out transaction")
+			return
+		}
+
+		res.Header().Set("Content-Type", file.ContentType)
+		res.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+		res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Filename))
+		if _, err = io.Copy(res, blob); err != nil {
+			log.Printf("error encountered streaming file for product %d: %v", productID, err)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	productFileHeaders   = strings.Split(strings.TrimSpace(productFilesTableHeaders), ",\n\t\t")
+	downloadGrantHeaders = strings.Split(strings.TrimSpace(downloadGrantsTableHeaders), ",\n\t\t")
+)
+
+func exampleProductFile() *ProductFile {
+	return &ProductFile{
+		DBRow:       DBRow{ID: 1, CreatedOn: generateExampleTimeForTests()},
+		ProductID:   exampleProduct.ID,
+		BlobKey:     "exampleblobkey",
+		Filename:    "skateboard.zip",
+		ContentType: "application/zip",
+		Size:        int64(len("skateboard contents")),
+	}
+}
+
+func setExpectationsForDigitalProductRetrieval(mock sqlmock.Sqlmock, sku string, err error) {
+	data := make([]driver.Value, len(exampleProductData))
+	copy(data, exampleProductData)
+	for i, header := range productHeaders {
+		if header == "digital" {
+			data[i] = true
+		}
+	}
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(data...)
+	mock.ExpectQuery(formatQueryForSQLMock(completeProductRetrievalQuery)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductFileRetrieval(mock sqlmock.Sqlmock, productID uint64, f *ProductFile, err error) {
+	exampleRows := sqlmock.NewRows(productFileHeaders)
+	if f != nil {
+		exampleRows = exampleRows.AddRow(f.ID, f.ProductID, f.BlobKey, f.Filename, f.ContentType, f.Size, f.CreatedOn, nil, nil)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productFileRetrievalQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductFileUpsert(mock sqlmock.Sqlmock, f *ProductFile, err error) {
+	exampleRows := sqlmock.NewRows(productFileHeaders).
+		AddRow(1, f.ProductID, f.BlobKey, f.Filename, f.ContentType, f.Size, generateExampleTimeForTests(), nil, nil)
+	query, _ := buildProductFileUpsertQuery(f)
+	// can't expect args here because we can't predict the blob key
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductFileExistence(mock sqlmock.Sqlmock, productID string, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(productFileExistenceQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForDownloadGrantCreation(mock sqlmock.Sqlmock, g *DownloadGrant, err error) {
+	exampleRows := sqlmock.NewRows(downloadGrantHeaders).
+		AddRow(1, g.Token, g.ProductID, g.UserID, g.ExpiresOn, g.MaxDownloads, 0, generateExampleTimeForTests(), nil, nil)
+	query, _ := buildDownloadGrantCreationQuery(g)
+	// can't expect args here because we can't predict the token or expiry
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForDownloadGrantRedemption(mock sqlmock.Sqlmock, token string, productID uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"product_id"})
+	if err == nil {
+		exampleRows = exampleRows.AddRow(productID)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(downloadGrantRedemptionQuery)).
+		WithArgs(token).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestDownloadSignatureIsValid(t *testing.T) {
+	t.Parallel()
+	now := generateExampleTimeForTests()
+	expires := now.Add(time.Hour).Unix()
+	rawExpires := strconv.FormatInt(expires, 10)
+	signature := signDownload("token", expires)
+
+	assert.True(t, downloadSignatureIsValid("token", rawExpires, signature, now), "a freshly signed link should be valid")
+	assert.False(t, downloadSignatureIsValid("token", rawExpires, signature, now.Add(2*time.Hour)), "an expired link should be invalid")
+	assert.False(t, downloadSignatureIsValid("other", rawExpires, signature, now), "a link for another token should be invalid")
+	assert.False(t, downloadSignatureIsValid("token", strconv.FormatInt(expires+3600, 10), signature, now), "a link with an extended expiry should be invalid")
+	assert.False(t, downloadSignatureIsValid("token", "tomorrow", signature, now), "a link with a garbage expiry should be invalid")
+}
+
+func TestBuildDownloadURL(t *testing.T) {
+	t.Parallel()
+	expiresOn := generateExampleTimeForTests().Add(time.Hour)
+	u, err := url.Parse(buildDownloadURL("token", expiresOn))
+	assert.Nil(t, err)
+	assert.Equal(t, "/download/token", u.Path)
+	assert.Equal(t, strconv.FormatInt(expiresOn.Unix(), 10), u.Query().Get("expires"))
+	assert.Equal(t, signDownload("token", expiresOn.Unix()), u.Query().Get("signature"))
+}
+
+func TestFilenameIsValid(t *testing.T) {
+	t.Parallel()
+	assert.True(t, filenameIsValid("album.zip"))
+	assert.True(t, filenameIsValid("Sleeping People - Sleeping People.flac"))
+	for _, filename := range []string{"", `a"b.zip`, "../album.zip", `dir\album.zip`, "album\r\n.zip"} {
+		assert.False(t, filenameIsValid(filename), "`%s` should not be a valid filename", filename)
+	}
+}
+
+func TestRetrieveProductFile(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	expected := exampleProductFile()
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, expected, nil)
+
+	actual, err := retrieveProductFile(testUtil.DB, exampleProduct.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.BlobKey, actual.BlobKey)
+	assert.Equal(t, expected.Filename, actual.Filename)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, nil, sql.ErrNoRows)
+	setExpectationsForProductFileUpsert(testUtil.Mock, exampleProductFile(), nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/zip")
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	assert.Equal(t, 1, len(testUtil.Blobs.blobs), "the file should have been stored")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerReplacesPreviousFile(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	previous := exampleProductFile()
+	testUtil.Blobs.Put(previous.BlobKey, strings.NewReader("old contents"))
+
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, previous, nil)
+	setExpectationsForProductFileUpsert(testUtil.Mock, exampleProductFile(), nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	_, stillThere := testUtil.Blobs.blobs[previous.BlobKey]
+	assert.False(t, stillThere, "the replaced file should have been removed")
+	assert.Equal(t, 1, len(testUtil.Blobs.blobs))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerWithInvalidFilename(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerForPhysicalProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	assert.Empty(t, testUtil.Blobs.blobs, "nothing should have been stored")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerWithErrorSavingFile(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, nil, sql.ErrNoRows)
+	setExpectationsForProductFileUpsert(testUtil.Mock, exampleProductFile(), arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	assert.Empty(t, testUtil.Blobs.blobs, "the orphaned file should have been removed")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductFileUploadHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/file?filename=skateboard.zip", exampleProduct.SKU), strings.NewReader("skateboard contents"))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	productID := strconv.FormatUint(exampleProduct.ID, 10)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileExistence(testUtil.Mock, productID, true, nil)
+	setExpectationsForUserExistenceByID(testUtil.Mock, "5", true, nil)
+	setExpectationsForDownloadGrantCreation(testUtil.Mock, &DownloadGrant{
+		Token:        "exampletoken",
+		ProductID:    exampleProduct.ID,
+		UserID:       5,
+		ExpiresOn:    time.Now().UTC().Add(time.Hour).Truncate(time.Second),
+		MaxDownloads: 3,
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(`{"user_id": 5, "ttl": 3600, "max_downloads": 3}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &DownloadGrant{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), actual.MaxDownloads)
+
+	u, err := url.Parse(actual.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "/download/exampletoken", u.Path)
+	assert.True(t, downloadSignatureIsValid("exampletoken", u.Query().Get("expires"), u.Query().Get("signature"), time.Now()), "the grant's URL should be validly signed")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(exampleGarbageInput))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandlerForPhysicalProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(`{"user_id": 5}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandlerForProductWithoutFile(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileExistence(testUtil.Mock, strconv.FormatUint(exampleProduct.ID, 10), false, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(`{"user_id": 5}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandlerForNonexistentUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileExistence(testUtil.Mock, strconv.FormatUint(exampleProduct.ID, 10), true, nil)
+	setExpectationsForUserExistenceByID(testUtil.Mock, "5", false, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(`{"user_id": 5}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadGrantCreationHandlerWithErrorCreatingGrant(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForDigitalProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductFileExistence(testUtil.Mock, strconv.FormatUint(exampleProduct.ID, 10), true, nil)
+	setExpectationsForUserExistenceByID(testUtil.Mock, "5", true, nil)
+	setExpectationsForDownloadGrantCreation(testUtil.Mock, &DownloadGrant{}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/download_grants", exampleProduct.SKU), strings.NewReader(`{"user_id": 5}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	file := exampleProductFile()
+	testUtil.Blobs.Put(file.BlobKey, strings.NewReader("skateboard contents"))
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForDownloadGrantRedemption(testUtil.Mock, "exampletoken", exampleProduct.ID, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, file, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	assert.Equal(t, "application/zip", testUtil.Response.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="skateboard.zip"`, testUtil.Response.Header().Get("Content-Disposition"))
+
+	body, err := ioutil.ReadAll(testUtil.Response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "skateboard contents", string(body))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithInvalidSignature(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	expires := time.Now().Add(time.Hour).Unix()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/download/exampletoken?expires=%d&signature=%s", expires, signDownload("othertoken", expires)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithExpiredLink(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(-time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithExhaustedGrant(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForDownloadGrantRedemption(testUtil.Mock, "exampletoken", 0, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusGone, testUtil.Response.Code, "status code should be 410")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithErrorRedeemingGrant(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForDownloadGrantRedemption(testUtil.Mock, "exampletoken", 0, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithMissingBlob(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForDownloadGrantRedemption(testUtil.Mock, "exampletoken", exampleProduct.ID, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, exampleProductFile(), nil)
+	// the download shouldn't count against the grant if there's nothing to download
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestDownloadHandlerWithMissingProductFile(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForDownloadGrantRedemption(testUtil.Mock, "exampletoken", exampleProduct.ID, nil)
+	setExpectationsForProductFileRetrieval(testUtil.Mock, exampleProduct.ID, nil, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodGet, buildDownloadURL("exampletoken", time.Now().Add(time.Hour)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -41,7 +41,7 @@ type Discount struct {
 	DBRow
 	Name          string    `json:"name"`
 	Type          string    `json:"type"`
-	Amount        float32   `json:"amount"`
+	Amount        Money     `json:"amount"`
 	StartsOn      time.Time `json:"starts_on"`
 	ExpiresOn     NullTime  `json:"expires_on"`
 	RequiresCode  bool      `json:"requires_code"`
@@ -75,7 +75,7 @@ func (d *Discount) generateScanArgs() []interface{} {
 type DiscountCreationInput struct {
 	Name          string    `json:"name"`
 	Type          string    `json:"type"`
-	Amount        float32   `json:"amount"`
+	Amount        Money     `json:"amount"`
 	StartsOn      time.Time `json:"starts_on"`
 	ExpiresOn     NullTime  `json:"expires_on"`
 	RequiresCode  bool      `json:"requires_code"`
@@ -126,12 +126,19 @@ func buildDiscountListRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
 	// DiscountListRetrievalHandler is a request handler that returns a list of Discounts
 	return func(res http.ResponseWriter, req *http.Request) {
 		rawFilterParams := req.URL.Query()
-		queryFilter := parseRawFilterParams(rawFilterParams)
-		count, err := getRowCount(db, "discounts", queryFilter)
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
 		if err != nil {
-			notifyOfInternalIssue(res, err, "retrieve count of discounts from the database")
+			notifyOfInvalidRequestBody(res, err)
 			return
 		}
+		var count uint64
+		if !queryFilter.SkipCount {
+			count, err = getRowCount(db, "discounts", queryFilter)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve count of discounts from the database")
+				return
+			}
+		}
 
 		var discounts []Discount
 		query, args := buildDiscountListQuery(queryFilter)
@@ -142,12 +149,11 @@ func buildDiscountListRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
 		}
 
 		discountsResponse := &DiscountsResponse{
-			ListResponse: ListResponse{
-				Page:  queryFilter.Page,
-				Limit: queryFilter.Limit,
-				Count: count,
-			},
-			Data: discounts,
+			ListResponse: newListResponse(queryFilter, count),
+			Data:         discounts,
+		}
+		if len(discounts) > 0 {
+			discountsResponse.NextCursor = buildNextCursor(queryFilter, len(discounts), discounts[len(discounts)-1].DBRow)
 		}
 		json.NewEncoder(res).Encode(discountsResponse)
 	}
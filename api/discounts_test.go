@@ -52,7 +52,7 @@ func init() {
 		},
 		Name:      "Example Discount",
 		Type:      "flat_discount",
-		Amount:    12.34,
+		Amount:    1234,
 		StartsOn:  generateExampleTimeForTests(),
 		ExpiresOn: NullTime{pq.NullTime{Time: generateExampleTimeForTests().Add(30 * (24 * time.Hour)), Valid: true}},
 	}
@@ -61,7 +61,7 @@ func init() {
 		exampleDiscount.ID,
 		exampleDiscount.Name,
 		exampleDiscount.Type,
-		exampleDiscount.Amount,
+		exampleDiscount.Amount.String(),
 		exampleDiscount.StartsOn,
 		exampleDiscount.ExpiresOn.Time,
 		exampleDiscount.RequiresCode,
@@ -91,7 +91,7 @@ func setExpectationsForDiscountRetrievalByID(mock sqlmock.Sqlmock, id string, er
 func setExpectationsForDiscountCountQuery(mock sqlmock.Sqlmock, queryFilter *QueryFilter, err error) {
 	exampleRows := sqlmock.NewRows([]string{"count"}).AddRow(3)
 
-	discountListRetrievalQuery := buildCountQuery("discounts", queryFilter)
+	discountListRetrievalQuery, _ := buildCountQuery("discounts", queryFilter)
 	query := formatQueryForSQLMock(discountListRetrievalQuery)
 	mock.ExpectQuery(query).
 		WillReturnRows(exampleRows).
@@ -184,7 +184,7 @@ func TestRetrieveDiscountFromDB(t *testing.T) {
 		},
 		Name:      "Example Discount",
 		Type:      "flat_discount",
-		Amount:    12.34,
+		Amount:    1234,
 		StartsOn:  generateExampleTimeForTests(),
 		ExpiresOn: NullTime{pq.NullTime{Time: generateExampleTimeForTests().Add(30 * (24 * time.Hour)), Valid: true}},
 	}
@@ -323,7 +323,6 @@ func TestDiscountListHandler(t *testing.T) {
 		ListResponse: ListResponse{
 			Page:  1,
 			Limit: 25,
-			Count: 3,
 		},
 	}
 
@@ -333,8 +332,8 @@ func TestDiscountListHandler(t *testing.T) {
 
 	assert.Equal(t, expected.Page, actual.Page, "expected and actual product pages should be equal")
 	assert.Equal(t, expected.Limit, actual.Limit, "expected and actual product limits should be equal")
-	assert.Equal(t, expected.Count, actual.Count, "expected and actual product counts should be equal")
-	assert.Equal(t, uint64(len(actual.Data)), actual.Count, "actual product counts and product response count field should be equal")
+	assert.Equal(t, uint64(3), *actual.Count, "expected and actual product counts should be equal")
+	assert.Equal(t, uint64(len(actual.Data)), *actual.Count, "actual product counts and product response count field should be equal")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
@@ -379,7 +378,7 @@ func TestDiscountCreationHandler(t *testing.T) {
 		},
 		Name:         "Test",
 		Type:         "flat_amount",
-		Amount:       12.34,
+		Amount:       1234,
 		StartsOn:     dummyTime,
 		RequiresCode: true,
 		Code:         "TEST",
@@ -470,7 +469,7 @@ func TestDiscountUpdateHandler(t *testing.T) {
 		},
 		Name:         "New Name",
 		Type:         "flat_discount",
-		Amount:       12.34,
+		Amount:       1234,
 		RequiresCode: true,
 		Code:         "TEST",
 	}
@@ -525,7 +524,7 @@ func TestDiscountUpdateHandlerWithErrorRetrievingDiscount(t *testing.T) {
 		},
 		Name:         "New Name",
 		Type:         "flat_discount",
-		Amount:       12.34,
+		Amount:       1234,
 		RequiresCode: true,
 		Code:         "TEST",
 	}
@@ -552,7 +551,7 @@ func TestDiscountUpdateHandlerWithErrorUpdatingDiscount(t *testing.T) {
 		},
 		Name:         "New Name",
 		Type:         "flat_discount",
-		Amount:       12.34,
+		Amount:       1234,
 		RequiresCode: true,
 		Code:         "TEST",
 	}
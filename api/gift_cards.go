@@ -0,0 +1,423 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	giftCardsTableHeaders = `id,
+		code,
+		product_id,
+		initial_balance,
+		remaining_balance,
+		currency,
+		expires_on,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	giftCardTransactionsTableHeaders = `id,
+		gift_card_id,
+		kind,
+		amount,
+		balance_after,
+		user_id,
+		reference,
+		created_on
+	`
+
+	giftCardCodeSize = 16
+
+	giftCardTransactionKindIssue  = "issue"
+	giftCardTransactionKindDebit  = "debit"
+	giftCardTransactionKindCredit = "credit"
+
+	giftCardRetrievalQuery = `SELECT ` + giftCardsTableHeaders + ` FROM gift_cards WHERE code = $1 AND archived_on IS NULL`
+	giftCardDeletionQuery  = `UPDATE gift_cards SET archived_on = NOW() WHERE code = $1 AND archived_on IS NULL`
+
+	// balance changes are checked and applied in a single statement, so that the row lock serializes
+	// concurrent redemptions and a card can never be spent below zero or topped up past its initial value
+	giftCardDebitQuery = `
+		UPDATE gift_cards SET remaining_balance = remaining_balance - $2, updated_on = NOW()
+		WHERE code = $1 AND archived_on IS NULL AND (expires_on IS NULL OR expires_on > NOW()) AND remaining_balance >= $2
+		RETURNING ` + giftCardsTableHeaders
+	giftCardCreditQuery = `
+		UPDATE gift_cards SET remaining_balance = remaining_balance + $2, updated_on = NOW()
+		WHERE code = $1 AND archived_on IS NULL AND remaining_balance + $2 <= initial_balance
+		RETURNING ` + giftCardsTableHeaders
+)
+
+// giftCardCodeChars leaves out characters that are easily mistaken for one another when read off a card
+var giftCardCodeChars = []byte("ABCDEFGHJKLMNPQRSTUVWXYZ23456789")
+
+// errGiftCardAdjustmentRefused means a balance change didn't go through because the card can't accommodate it
+var errGiftCardAdjustmentRefused = errors.New("gift card balance adjustment refused")
+
+// GiftCard is a stored balance that can be spent in place of money
+type GiftCard struct {
+	DBRow
+	Code string `json:"code"`
+	// ProductID is only set for gift cards issued as a purchase of a gift card product
+	ProductID        *uint64  `json:"product_id,omitempty"`
+	InitialBalance   Money    `json:"initial_balance"`
+	RemainingBalance Money    `json:"remaining_balance"`
+	Currency         string   `json:"currency"`
+	ExpiresOn        NullTime `json:"expires_on"`
+}
+
+func (g *GiftCard) generateScanArgs() []interface{} {
+	return []interface{}{
+		&g.ID,
+		&g.Code,
+		&g.ProductID,
+		&g.InitialBalance,
+		&g.RemainingBalance,
+		&g.Currency,
+		&g.ExpiresOn,
+		&g.CreatedOn,
+		&g.UpdatedOn,
+		&g.ArchivedOn,
+	}
+}
+
+func (g *GiftCard) expired(now time.Time) bool {
+	return g.ExpiresOn.Valid && !g.ExpiresOn.Time.After(now)
+}
+
+// GiftCardTransaction is an entry in a gift card's ledger. Entries are never updated or removed.
+type GiftCardTransaction struct {
+	ID           uint64    `json:"id"`
+	GiftCardID   uint64    `json:"gift_card_id"`
+	Kind         string    `json:"kind"`
+	Amount       Money     `json:"amount"`
+	BalanceAfter Money     `json:"balance_after"`
+	UserID       *uint64   `json:"user_id,omitempty"`
+	Reference    string    `json:"reference"`
+	CreatedOn    time.Time `json:"created_on"`
+}
+
+func (t *GiftCardTransaction) generateScanArgs() []interface{} {
+	return []interface{}{
+		&t.ID,
+		&t.GiftCardID,
+		&t.Kind,
+		&t.Amount,
+		&t.BalanceAfter,
+		&t.UserID,
+		&t.Reference,
+		&t.CreatedOn,
+	}
+}
+
+// GiftCardCreationInput is a struct to use for issuing gift cards. When a SKU is provided, the card
+// is issued as a purchase of that product, and its balance defaults to the product's price.
+type GiftCardCreationInput struct {
+	SKU            string     `json:"sku"`
+	InitialBalance Money      `json:"initial_balance"`
+	Currency       string     `json:"currency"`
+	ExpiresOn      *time.Time `json:"expires_on"`
+}
+
+// GiftCardTransactionInput is a struct to use for spending or restoring part of a gift card's balance
+type GiftCardTransactionInput struct {
+	Amount    Money  `json:"amount" validate:"required"`
+	Reference string `json:"reference"`
+}
+
+func generateGiftCardCode() string {
+	return uniuri.NewLenChars(giftCardCodeSize, giftCardCodeChars)
+}
+
+// normalizeGiftCardCode lets customers type codes in whatever case they please
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(code)
+}
+
+func retrieveGiftCardFromDB(db *sqlx.DB, code string) (*GiftCard, error) {
+	g := &GiftCard{}
+	err := db.QueryRow(giftCardRetrievalQuery, code).Scan(g.generateScanArgs()...)
+	return g, err
+}
+
+func createGiftCardTransactionInDB(tx *sql.Tx, t *GiftCardTransaction) error {
+	query, args := buildGiftCardTransactionCreationQuery(t)
+	return tx.QueryRow(query, args...).Scan(t.generateScanArgs()...)
+}
+
+// adjustGiftCardBalance debits or credits a gift card and records the change in its ledger. It returns
+// errGiftCardAdjustmentRefused if the card doesn't exist, or can't accommodate the change.
+func adjustGiftCardBalance(db *sqlx.DB, code string, kind string, in *GiftCardTransactionInput, userID uint64) (*GiftCardTransaction, error) {
+	adjustmentQuery := giftCardDebitQuery
+	if kind == giftCardTransactionKindCredit {
+		adjustmentQuery = giftCardCreditQuery
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating database transaction")
+	}
+
+	card := &GiftCard{}
+	err = tx.QueryRow(adjustmentQuery, code, in.Amount).Scan(card.generateScanArgs()...)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errGiftCardAdjustmentRefused
+	} else if err != nil {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "Error adjusting gift card balance")
+	}
+
+	t := &GiftCardTransaction{
+		GiftCardID:   card.ID,
+		Kind:         kind,
+		Amount:       in.Amount,
+		BalanceAfter: card.RemainingBalance,
+		UserID:       &userID,
+		Reference:    in.Reference,
+	}
+	err = createGiftCardTransactionInDB(tx, t)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "Error recording gift card transaction")
+	}
+
+	return t, tx.Commit()
+}
+
+// respondToRefusedGiftCardAdjustment works out why a gift card balance change didn't go through, and tells the user
+func respondToRefusedGiftCardAdjustment(db *sqlx.DB, req *http.Request, res http.ResponseWriter, code string, kind string) {
+	card, err := retrieveGiftCardFromDB(db, code)
+	if err == sql.ErrNoRows {
+		respondThatRowDoesNotExist(req, res, "gift card", code)
+		return
+	} else if err != nil {
+		notifyOfInternalIssue(res, err, "retrieve gift card from the database")
+		return
+	}
+
+	switch {
+	case kind == giftCardTransactionKindCredit:
+		notifyOfInvalidRequestBody(res, fmt.Errorf("gift card balance cannot exceed its initial balance of %s", card.InitialBalance))
+	case card.expired(time.Now()):
+		notifyOfInvalidRequestBody(res, errors.New("gift card has expired"))
+	default:
+		notifyOfInvalidRequestBody(res, fmt.Errorf("insufficient gift card balance: %s remaining", card.RemainingBalance))
+	}
+}
+
+func buildGiftCardCreationHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// GiftCardCreationHandler is a request handler that issues a new gift card
+	return func(res http.ResponseWriter, req *http.Request) {
+		cardInput := &GiftCardCreationInput{}
+		err := validateRequestInput(req, cardInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		card := &GiftCard{
+			Code:           generateGiftCardCode(),
+			InitialBalance: cardInput.InitialBalance,
+			Currency:       strings.ToUpper(cardInput.Currency),
+		}
+		if card.Currency == "" {
+			card.Currency = baseCurrency
+		}
+		if !currencyIsSupported(card.Currency) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("unsupported currency: `%s`", cardInput.Currency))
+			return
+		}
+		if cardInput.ExpiresOn != nil {
+			if !cardInput.ExpiresOn.After(time.Now()) {
+				notifyOfInvalidRequestBody(res, errors.New("gift cards cannot be issued already expired"))
+				return
+			}
+			card.ExpiresOn.Time = *cardInput.ExpiresOn
+			card.ExpiresOn.Valid = true
+		}
+
+		if cardInput.SKU != "" {
+			product, err := retrieveProductFromDB(db, cardInput.SKU)
+			if err == sql.ErrNoRows {
+				respondThatRowDoesNotExist(req, res, "product", cardInput.SKU)
+				return
+			} else if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product from the database")
+				return
+			}
+			card.ProductID = &product.ID
+			if card.InitialBalance == 0 {
+				// products are priced in the base currency, so cards issued in any other one need the price converted
+				if card.Currency != baseCurrency {
+					products := []Product{product}
+					if err = convertProductPrices(db, products, card.Currency); err != nil {
+						notifyOfCurrencyConversionFailure(res, err)
+						return
+					}
+					product = products[0]
+				}
+				card.InitialBalance = effectiveUnitPrice(product.Price, product.OnSale, product.SalePrice)
+			}
+		}
+		if card.InitialBalance <= 0 {
+			notifyOfInvalidRequestBody(res, errors.New("gift cards must have a positive initial balance"))
+			return
+		}
+		card.RemainingBalance = card.InitialBalance
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		query, args := buildGiftCardCreationQuery(card)
+		err = tx.QueryRow(query, args...).Scan(card.generateScanArgs()...)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "insert gift card into database")
+			return
+		}
+
+		issuedBy, _ := retrieveUserIDFromSession(req, store)
+		err = createGiftCardTransactionInDB(tx, &GiftCardTransaction{
+			GiftCardID:   card.ID,
+			Kind:         giftCardTransactionKindIssue,
+			Amount:       card.InitialBalance,
+			BalanceAfter: card.RemainingBalance,
+			UserID:       &issuedBy,
+		})
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "record gift card issuance")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "closing out transaction")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(card)
+	}
+}
+
+func buildGiftCardRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
+	// GiftCardRetrievalHandler is a request handler that returns a gift card, and with it, its balance
+	return func(res http.ResponseWriter, req *http.Request) {
+		code := normalizeGiftCardCode(chi.URLParam(req, "code"))
+
+		card, err := retrieveGiftCardFromDB(db, code)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "gift card", code)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve gift card from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(card)
+	}
+}
+
+func buildGiftCardBalanceAdjustmentHandler(db *sqlx.DB, store *sessions.CookieStore, kind string) http.HandlerFunc {
+	// GiftCardBalanceAdjustmentHandler is a request handler that spends (or, for credits, restores) part of a gift card's balance
+	return func(res http.ResponseWriter, req *http.Request) {
+		code := normalizeGiftCardCode(chi.URLParam(req, "code"))
+
+		transactionInput := &GiftCardTransactionInput{}
+		err := validateRequestInput(req, transactionInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if transactionInput.Amount <= 0 {
+			notifyOfInvalidRequestBody(res, errors.New("amount must be positive"))
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		t, err := adjustGiftCardBalance(db, code, kind, transactionInput, userID)
+		if err == errGiftCardAdjustmentRefused {
+			respondToRefusedGiftCardAdjustment(db, req, res, code, kind)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "adjust gift card balance")
+			return
+		}
+
+		json.NewEncoder(res).Encode(t)
+	}
+}
+
+func buildGiftCardTransactionListHandler(db *sqlx.DB) http.HandlerFunc {
+	// GiftCardTransactionListHandler is a request handler that returns a gift card's ledger, oldest entry first
+	return func(res http.ResponseWriter, req *http.Request) {
+		code := normalizeGiftCardCode(chi.URLParam(req, "code"))
+
+		card, err := retrieveGiftCardFromDB(db, code)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "gift card", code)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve gift card from the database")
+			return
+		}
+
+		query, args := buildGiftCardTransactionListQuery(card.ID)
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve gift card transactions from the database")
+			return
+		}
+		defer rows.Close()
+
+		transactions := []GiftCardTransaction{}
+		for rows.Next() {
+			var t GiftCardTransaction
+			if err = rows.Scan(t.generateScanArgs()...); err != nil {
+				notifyOfInternalIssue(res, err, "scan gift card transaction")
+				return
+			}
+			transactions = append(transactions, t)
+		}
+		if err = rows.Err(); err != nil {
+			notifyOfInternalIssue(res, err, "retrieve gift card transactions from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(transactions)
+	}
+}
+
+func buildGiftCardDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// GiftCardDeletionHandler is a request handler that voids a gift card, along with whatever balance it had left
+	return func(res http.ResponseWriter, req *http.Request) {
+		code := normalizeGiftCardCode(chi.URLParam(req, "code"))
+
+		result, err := db.Exec(giftCardDeletionQuery, code)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive gift card")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "gift card", code)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
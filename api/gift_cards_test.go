@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const exampleGiftCardCode = "ABCDEFGH23456789"
+
+var (
+	giftCardHeaders            = strings.Split(strings.TrimSpace(giftCardsTableHeaders), ",\n\t\t")
+	giftCardTransactionHeaders = strings.Split(strings.TrimSpace(giftCardTransactionsTableHeaders), ",\n\t\t")
+)
+
+func exampleGiftCard() *GiftCard {
+	return &GiftCard{
+		DBRow:            DBRow{ID: 7, CreatedOn: generateExampleTimeForTests()},
+		Code:             exampleGiftCardCode,
+		InitialBalance:   5000,
+		RemainingBalance: 3000,
+		Currency:         baseCurrency,
+	}
+}
+
+func giftCardRows(g *GiftCard) *sqlmock.Rows {
+	var expiresOn interface{}
+	if g.ExpiresOn.Valid {
+		expiresOn = g.ExpiresOn.Time
+	}
+	var productID interface{}
+	if g.ProductID != nil {
+		productID = *g.ProductID
+	}
+	return sqlmock.NewRows(giftCardHeaders).
+		AddRow(g.ID, g.Code, productID, g.InitialBalance.String(), g.RemainingBalance.String(), g.Currency, expiresOn, g.CreatedOn, nil, nil)
+}
+
+func setExpectationsForGiftCardRetrieval(mock sqlmock.Sqlmock, code string, g *GiftCard, err error) {
+	exampleRows := sqlmock.NewRows(giftCardHeaders)
+	if g != nil {
+		exampleRows = giftCardRows(g)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(giftCardRetrievalQuery)).
+		WithArgs(code).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForGiftCardCreation(mock sqlmock.Sqlmock, g *GiftCard, err error) {
+	query, _ := buildGiftCardCreationQuery(g)
+	// can't expect args here because we can't predict the code
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(giftCardRows(g)).
+		WillReturnError(err)
+}
+
+func setExpectationsForGiftCardAdjustment(mock sqlmock.Sqlmock, adjustmentQuery string, code string, amount Money, g *GiftCard, err error) {
+	exampleRows := sqlmock.NewRows(giftCardHeaders)
+	if g != nil {
+		exampleRows = giftCardRows(g)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(adjustmentQuery)).
+		WithArgs(code, amount.String()).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForGiftCardTransactionCreation(mock sqlmock.Sqlmock, t *GiftCardTransaction, err error) {
+	exampleRows := sqlmock.NewRows(giftCardTransactionHeaders).
+		AddRow(1, t.GiftCardID, t.Kind, t.Amount.String(), t.BalanceAfter.String(), 1, t.Reference, generateExampleTimeForTests())
+	query, _ := buildGiftCardTransactionCreationQuery(t)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGenerateGiftCardCode(t *testing.T) {
+	t.Parallel()
+	code := generateGiftCardCode()
+	assert.Equal(t, giftCardCodeSize, len(code))
+	for _, c := range []byte(code) {
+		assert.True(t, bytes.IndexByte(giftCardCodeChars, c) >= 0, "`%c` should not appear in a gift card code", c)
+	}
+	assert.NotEqual(t, code, generateGiftCardCode(), "gift card codes should not repeat")
+}
+
+func TestNormalizeGiftCardCode(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, exampleGiftCardCode, normalizeGiftCardCode(strings.ToLower(exampleGiftCardCode)))
+}
+
+func TestGiftCardExpired(t *testing.T) {
+	t.Parallel()
+	now := generateExampleTimeForTests()
+	g := exampleGiftCard()
+	assert.False(t, g.expired(now), "gift cards without an expiry should never expire")
+
+	g.ExpiresOn.Valid = true
+	g.ExpiresOn.Time = now.Add(time.Hour)
+	assert.False(t, g.expired(now))
+	assert.True(t, g.expired(now.Add(time.Hour)))
+}
+
+func TestRetrieveGiftCardFromDB(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, exampleGiftCard(), nil)
+
+	actual, err := retrieveGiftCardFromDB(testUtil.DB, exampleGiftCardCode)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(3000), actual.RemainingBalance)
+	assert.Nil(t, actual.ProductID)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.RemainingBalance = card.InitialBalance
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardCreation(testUtil.Mock, card, nil)
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindIssue, Amount: card.InitialBalance, BalanceAfter: card.InitialBalance}, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(`{"initial_balance": 50}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &GiftCard{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(5000), actual.RemainingBalance)
+	assert.Equal(t, baseCurrency, actual.Currency)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerForGiftCardProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.ProductID = &exampleProduct.ID
+	card.InitialBalance = exampleProduct.Price
+	card.RemainingBalance = exampleProduct.Price
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardCreation(testUtil.Mock, card, nil)
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindIssue, Amount: card.InitialBalance, BalanceAfter: card.InitialBalance}, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(fmt.Sprintf(`{"sku": "%s"}`, exampleProduct.SKU)))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &GiftCard{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.Price, actual.InitialBalance, "a gift card product's balance should default to its price")
+	assert.Equal(t, exampleProduct.ID, *actual.ProductID)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerForGiftCardProductInAnotherCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.ProductID = &exampleProduct.ID
+	card.Currency = "JPY"
+	card.InitialBalance = Money(1499900)
+	card.RemainingBalance = card.InitialBalance
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "JPY", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "JPY", "150", nil)
+	testUtil.Mock.ExpectBegin()
+	query, args := buildGiftCardCreationQuery(card)
+	queryArgs := argsToDriverValues(args)
+	// we can't predict the code, but the balance is the point of this test
+	queryArgs[0] = sqlmock.AnyArg()
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(queryArgs...).
+		WillReturnRows(giftCardRows(card))
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindIssue, Amount: card.InitialBalance, BalanceAfter: card.InitialBalance}, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(fmt.Sprintf(`{"sku": "%s", "currency": "jpy"}`, exampleProduct.SKU)))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &GiftCard{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(1499900), actual.InitialBalance, "the product's price should be converted into the card's currency")
+	assert.Equal(t, "JPY", actual.Currency)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerForGiftCardProductWithoutExchangeRate(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "JPY", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "JPY", "", sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(fmt.Sprintf(`{"sku": "%s", "currency": "JPY"}`, exampleProduct.SKU)))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRetrieval(testUtil.Mock, "nonexistent", sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(`{"sku": "nonexistent"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	inputs := []string{
+		`{"initial_balance": 0}`,
+		`{"initial_balance": -10}`,
+		`{"initial_balance": 50, "currency": "XYZ"}`,
+		`{"initial_balance": 50, "expires_on": "2016-12-01T12:00:00Z"}`,
+	}
+
+	for _, input := range inputs {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(input))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for %s", input)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestGiftCardCreationHandlerWithErrorCreatingGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardCreation(testUtil.Mock, exampleGiftCard(), arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(`{"initial_balance": 50}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreationHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/gift_cards", strings.NewReader(`{"initial_balance": 50}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRetrievalHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, exampleGiftCard(), nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/gift_cards/%s", strings.ToLower(exampleGiftCardCode)), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &GiftCard{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(3000), actual.RemainingBalance)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRetrievalHandlerForNonexistentGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, nil, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/gift_cards/%s", exampleGiftCardCode), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.RemainingBalance = 1750
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardDebitQuery, exampleGiftCardCode, 1250, card, nil)
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindDebit, Amount: 1250, BalanceAfter: 1750, Reference: "order 12"}, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 12.50, "reference": "order 12"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &GiftCardTransaction{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(1750), actual.BalanceAfter)
+	assert.Equal(t, giftCardTransactionKindDebit, actual.Kind)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandlerWithInsufficientBalance(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardDebitQuery, exampleGiftCardCode, 4000, nil, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, exampleGiftCard(), nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 40}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	assert.Contains(t, testUtil.Response.Body.String(), "insufficient gift card balance")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandlerForExpiredGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.ExpiresOn.Valid = true
+	card.ExpiresOn.Time = generateExampleTimeForTests()
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardDebitQuery, exampleGiftCardCode, 1000, nil, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, card, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 10}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	assert.Contains(t, testUtil.Response.Body.String(), "expired")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandlerForNonexistentGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardDebitQuery, exampleGiftCardCode, 1000, nil, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, nil, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 10}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandlerWithErrorRecordingTransaction(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardDebitQuery, exampleGiftCardCode, 1000, card, nil)
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindDebit, Amount: 1000, BalanceAfter: card.RemainingBalance}, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 10}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardRedemptionHandlerWithInvalidAmount(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{`{"amount": 0}`, `{"amount": -5}`, exampleGarbageInput} {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(input))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, false)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for %s", input)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestGiftCardRedemptionHandlerRequiresAuthentication(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/redeem", exampleGiftCardCode), strings.NewReader(`{"amount": 10}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusUnauthorized, testUtil.Response.Code, "status code should be 401")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreditHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	card.RemainingBalance = 4000
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardCreditQuery, exampleGiftCardCode, 1000, card, nil)
+	setExpectationsForGiftCardTransactionCreation(testUtil.Mock, &GiftCardTransaction{GiftCardID: card.ID, Kind: giftCardTransactionKindCredit, Amount: 1000, BalanceAfter: 4000}, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/credit", exampleGiftCardCode), strings.NewReader(`{"amount": 10}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardCreditHandlerBeyondInitialBalance(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForGiftCardAdjustment(testUtil.Mock, giftCardCreditQuery, exampleGiftCardCode, 3000, nil, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, exampleGiftCard(), nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/gift_cards/%s/credit", exampleGiftCardCode), strings.NewReader(`{"amount": 30}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	assert.Contains(t, testUtil.Response.Body.String(), "initial balance")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardTransactionListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	card := exampleGiftCard()
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, card, nil)
+
+	exampleRows := sqlmock.NewRows(giftCardTransactionHeaders).
+		AddRow(1, card.ID, giftCardTransactionKindIssue, "50.00", "50.00", 1, "", generateExampleTimeForTests()).
+		AddRow(2, card.ID, giftCardTransactionKindDebit, "20.00", "30.00", 2, "order 12", generateExampleTimeForTests())
+	query, _ := buildGiftCardTransactionListQuery(card.ID)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(card.ID).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/gift_cards/%s/transactions", exampleGiftCardCode), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []GiftCardTransaction{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Equal(t, Money(3000), actual[1].BalanceAfter)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardTransactionListHandlerForNonexistentGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForGiftCardRetrieval(testUtil.Mock, exampleGiftCardCode, nil, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/gift_cards/%s/transactions", exampleGiftCardCode), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(giftCardDeletionQuery)).
+		WithArgs(exampleGiftCardCode).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/gift_cards/%s", exampleGiftCardCode), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestGiftCardDeletionHandlerForNonexistentGiftCard(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(giftCardDeletionQuery)).
+		WithArgs(exampleGiftCardCode).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/gift_cards/%s", exampleGiftCardCode), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
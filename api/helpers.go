@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	validator "gopkg.in/go-playground/validator.v9"
@@ -101,9 +103,11 @@ type DBRow struct {
 // ListResponse is a generic list response struct containing values that represent
 // pagination, meant to be embedded into other object response structs
 type ListResponse struct {
-	Count uint64 `json:"count"`
-	Limit uint8  `json:"limit"`
-	Page  uint64 `json:"page"`
+	// Count is only omitted when the client didn't want the list counted
+	Count      *uint64 `json:"count,omitempty"`
+	Limit      uint8   `json:"limit"`
+	Page       uint64  `json:"page"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
 // ErrorResponse is a handy struct we can respond with in the event we have an error to report
@@ -112,6 +116,14 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// ListCursor represents the last row a client has seen in a keyset paginated list.
+// Rows are ordered by (created_on, id), so those two values are all we need to seek
+// to the next page without an OFFSET.
+type ListCursor struct {
+	CreatedOn time.Time
+	ID        uint64
+}
+
 // QueryFilter represents a query filter
 type QueryFilter struct {
 	Page          uint64
@@ -120,14 +132,126 @@ type QueryFilter struct {
 	CreatedBefore time.Time
 	UpdatedAfter  time.Time
 	UpdatedBefore time.Time
+	UseCursor     bool
+	Cursor        *ListCursor
+	SkipCount     bool
+	// Archived is only ever set for admins, and controls whether archived rows are left out, mixed in, or all that's returned
+	Archived string
+	// PublishedOnly is set for product lists requested by anyone who isn't an admin
+	PublishedOnly bool
+	// Attributes are the custom attribute values products must have to be listed
+	Attributes ProductAttributes
+}
+
+const (
+	archivedRowsIncluded = "include"
+	archivedRowsOnly     = "only"
+)
+
+// parseArchivedFilterParam validates the `archived` param, which list routes that support browsing archived rows honor
+func parseArchivedFilterParam(rawFilterParams url.Values) (string, error) {
+	archived := rawFilterParams.Get("archived")
+	switch archived {
+	case "", archivedRowsIncluded, archivedRowsOnly:
+		return archived, nil
+	}
+	return "", fmt.Errorf("invalid archived filter: `%s`", archived)
+}
+
+func encodeListCursor(c *ListCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedOn.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeListCursor(in string) (*ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return nil, errors.New("malformed cursor")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ListCursor{
+		CreatedOn: time.Unix(0, nanos).UTC(),
+		ID:        id,
+	}
+	return c, nil
+}
+
+// buildNextCursor returns the cursor a client should provide to retrieve the page after one that
+// ended with lastRow, or an empty string if the client didn't ask for cursor pagination or there
+// are no more rows to be had.
+func buildNextCursor(queryFilter *QueryFilter, rowCount int, lastRow DBRow) string {
+	limit := int(queryFilter.Limit)
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	if !queryFilter.UseCursor || rowCount < limit {
+		return ""
+	}
+	return encodeListCursor(&ListCursor{CreatedOn: lastRow.CreatedOn, ID: lastRow.ID})
 }
 
-func parseRawFilterParams(rawFilterParams url.Values) *QueryFilter {
+// newListResponse describes a page of a list, along with the count of the whole list if one was taken
+func newListResponse(queryFilter *QueryFilter, count uint64) ListResponse {
+	lr := ListResponse{
+		Page:  queryFilter.Page,
+		Limit: queryFilter.Limit,
+	}
+	if !queryFilter.SkipCount {
+		lr.Count = &count
+	}
+	return lr
+}
+
+// parseRawFilterParams builds a QueryFilter out of a request's query params. Most malformed params are
+// ignored in favor of their defaults, but a malformed cursor is an error, since starting over from the
+// first page would send a client that's following next_cursor around in circles.
+func parseRawFilterParams(rawFilterParams url.Values) (*QueryFilter, error) {
 	qf := &QueryFilter{
 		Page:  1,
 		Limit: 25,
 	}
 
+	// cursor pagination is opt-in: a client starts with an empty `cursor` param and
+	// then passes along whatever `next_cursor` value we gave them on the last page.
+	cursor, cursorRequested := rawFilterParams["cursor"]
+	if cursorRequested && len(cursor) == 1 {
+		qf.UseCursor = true
+		// counting every row defeats the point of seeking, so we only count when asked to
+		qf.SkipCount = true
+		if cursor[0] != "" {
+			c, err := decodeListCursor(cursor[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: `%s`", cursor[0])
+			}
+			qf.Cursor = c
+		}
+	}
+
+	count := rawFilterParams["count"]
+	if len(count) == 1 {
+		b, err := strconv.ParseBool(count[0])
+		if err != nil {
+			log.Printf("encountered error when trying to parse query filter param %s: %v", `Count`, err)
+		} else {
+			qf.SkipCount = !b
+		}
+	}
+
 	page := rawFilterParams["page"]
 	if len(page) == 1 {
 		i, err := strconv.ParseUint(page[0], 10, 64)
@@ -188,7 +312,7 @@ func parseRawFilterParams(rawFilterParams url.Values) *QueryFilter {
 		}
 	}
 
-	return qf
+	return qf, nil
 }
 
 func restrictedStringIsValid(input string) bool {
@@ -202,8 +326,8 @@ func restrictedStringIsValid(input string) bool {
 
 func getRowCount(db *sqlx.DB, table string, queryFilter *QueryFilter) (uint64, error) {
 	var count uint64
-	query := buildCountQuery(table, queryFilter)
-	err := db.Get(&count, query)
+	query, args := buildCountQuery(table, queryFilter)
+	err := db.Get(&count, query, args...)
 	return count, err
 }
 
@@ -249,11 +373,30 @@ func validateRequestInput(req *http.Request, output interface{}) error {
 
 func respondThatRowDoesNotExist(req *http.Request, res http.ResponseWriter, itemType, id string) {
 	itemTypeToIdentifierMap := map[string]string{
-		"product option":       "id",
-		"product option value": "id",
-		"product":              "sku",
-		"discount":             "id",
-		"user":                 "username",
+		"product option":                   "id",
+		"product option value":             "id",
+		"product":                          "sku",
+		"archived product":                 "sku",
+		"discount":                         "id",
+		"product review":                   "id",
+		"product price":                    "currency",
+		"product price tier":               "min_quantity",
+		"exchange rate":                    "currency",
+		"customer group":                   "id",
+		"customer group member":            "user id",
+		"customer group price":             "sku",
+		"product file":                     "product id",
+		"gift card":                        "code",
+		"product relation":                 "related sku",
+		"product revision":                 "revision",
+		"product attribute":                "id",
+		"product barcode":                  "gtin",
+		"product translation":              "locale",
+		"product option translation":       "locale",
+		"product option value translation": "locale",
+		"inventory location":               "id",
+		"reservation":                      "id",
+		"user":                             "username",
 	}
 
 	// in case we forget one, default to ID
@@ -310,3 +453,22 @@ func notifyOfExaustedAuthenticationAttempts(res http.ResponseWriter) {
 	}
 	json.NewEncoder(res).Encode(errRes)
 }
+
+func notifyOfForbiddenRequest(res http.ResponseWriter) {
+	log.Printf("Unauthorized user attempted an admin-only request")
+	res.WriteHeader(http.StatusForbidden)
+	errRes := &ErrorResponse{
+		Status:  http.StatusForbidden,
+		Message: "Forbidden",
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
+
+func notifyOfUnauthorizedRequest(res http.ResponseWriter) {
+	res.WriteHeader(http.StatusUnauthorized)
+	errRes := &ErrorResponse{
+		Status:  http.StatusUnauthorized,
+		Message: "Unauthorized",
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
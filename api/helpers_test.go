@@ -37,7 +37,9 @@ var (
 	exampleFilterStartTime time.Time
 	exampleFilterEndTime   time.Time
 	defaultQueryFilter     *QueryFilter
+	publishedQueryFilter   *QueryFilter
 	customQueryFilter      *QueryFilter
+	exampleCursor          *ListCursor
 
 	arbitraryError   error
 	exampleTime      time.Time
@@ -62,11 +64,22 @@ func init() {
 		Limit: 25,
 	}
 
+	publishedQueryFilter = &QueryFilter{
+		Page:          1,
+		Limit:         25,
+		PublishedOnly: true,
+	}
+
 	customQueryFilter = &QueryFilter{
 		Page:         2,
 		Limit:        35,
 		CreatedAfter: generateExampleTimeForTests(),
 	}
+
+	exampleCursor = &ListCursor{
+		CreatedOn: generateExampleTimeForTests(),
+		ID:        existingID,
+	}
 }
 
 ///////////////////////////////////////////////////////
@@ -83,6 +96,8 @@ type TestUtil struct {
 	DB       *sqlx.DB
 	Mock     sqlmock.Sqlmock
 	Store    *sessions.CookieStore
+	Blobs    *memoryBlobStore
+	Notifier *recordingLowStockNotifier
 }
 
 func generateExampleTimeForTests() time.Time {
@@ -95,7 +110,9 @@ func generateExampleTimeForTests() time.Time {
 
 func setExpectationsForRowCount(mock sqlmock.Sqlmock, table string, queryFilter *QueryFilter, count uint64, err error) {
 	exampleRows := sqlmock.NewRows([]string{"count"}).AddRow(count)
-	mock.ExpectQuery(formatQueryForSQLMock(buildCountQuery(table, queryFilter))).
+	query, args := buildCountQuery(table, queryFilter)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
 }
@@ -112,8 +129,11 @@ func setupTestVariables(t *testing.T) *TestUtil {
 	}
 	store := sessions.NewCookieStore([]byte(secret))
 
+	blobs := newMemoryBlobStore()
+	notifier := &recordingLowStockNotifier{}
+
 	router := chi.NewRouter()
-	SetupAPIRoutes(router, db, store)
+	SetupAPIRoutes(router, db, store, blobs, notifier)
 
 	return &TestUtil{
 		Response: httptest.NewRecorder(),
@@ -121,6 +141,8 @@ func setupTestVariables(t *testing.T) *TestUtil {
 		DB:       db,
 		Mock:     mock,
 		Store:    store,
+		Blobs:    blobs,
+		Notifier: notifier,
 	}
 }
 
@@ -304,6 +326,45 @@ func TestParseRawFilterParams(t *testing.T) {
 			expected:       defaultQueryFilter,
 			failureMessage: "URL with no relevant values should parsee to the default query filter",
 		},
+		{
+			input: "https://test.com/example?cursor=",
+			expected: &QueryFilter{
+				Page:      1,
+				Limit:     25,
+				UseCursor: true,
+				SkipCount: true,
+			},
+			failureMessage: "URL with an empty cursor param should start cursor pagination without a count",
+		},
+		{
+			input: fmt.Sprintf("https://test.com/example?cursor=%s", encodeListCursor(exampleCursor)),
+			expected: &QueryFilter{
+				Page:      1,
+				Limit:     25,
+				UseCursor: true,
+				Cursor:    exampleCursor,
+				SkipCount: true,
+			},
+			failureMessage: "URL with a cursor param should parse that cursor",
+		},
+		{
+			input: "https://test.com/example?cursor=&count=true",
+			expected: &QueryFilter{
+				Page:      1,
+				Limit:     25,
+				UseCursor: true,
+			},
+			failureMessage: "URL with a cursor param should still count rows when asked to",
+		},
+		{
+			input: "https://test.com/example?count=false",
+			expected: &QueryFilter{
+				Page:      1,
+				Limit:     25,
+				SkipCount: true,
+			},
+			failureMessage: "URL with count set to false should skip counting rows",
+		},
 	}
 
 	for _, test := range testSuite {
@@ -311,12 +372,76 @@ func TestParseRawFilterParams(t *testing.T) {
 		if err != nil {
 			log.Fatal(err)
 		}
-		actual := parseRawFilterParams(earl.Query())
+		actual, err := parseRawFilterParams(earl.Query())
+		assert.Nil(t, err)
 		assert.Equal(t, test.expected, actual, test.failureMessage)
 	}
 
 }
 
+func TestParseRawFilterParamsWithInvalidCursor(t *testing.T) {
+	t.Parallel()
+	_, err := parseRawFilterParams(url.Values{"cursor": {"not_a_real_cursor"}})
+	assert.NotNil(t, err, "an invalid cursor shouldn't quietly start pagination over from the beginning")
+}
+
+func TestNewListResponse(t *testing.T) {
+	t.Parallel()
+	actual := newListResponse(&QueryFilter{Page: 1, Limit: 25}, 0)
+	assert.NotNil(t, actual.Count, "lists should include their count even when it's zero")
+	assert.Equal(t, uint64(0), *actual.Count)
+
+	actual = newListResponse(&QueryFilter{Page: 1, Limit: 25, SkipCount: true}, 0)
+	assert.Nil(t, actual.Count, "lists that weren't counted shouldn't claim a count")
+}
+
+func TestParseArchivedFilterParam(t *testing.T) {
+	t.Parallel()
+	for _, archived := range []string{"", "include", "only"} {
+		actual, err := parseArchivedFilterParam(url.Values{"archived": {archived}})
+		assert.Nil(t, err)
+		assert.Equal(t, archived, actual)
+	}
+
+	actual, err := parseArchivedFilterParam(url.Values{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", actual, "archived rows should be left out by default")
+
+	_, err = parseArchivedFilterParam(url.Values{"archived": {"true"}})
+	assert.NotNil(t, err)
+}
+
+func TestListCursorEncodingRoundTrip(t *testing.T) {
+	t.Parallel()
+	encoded := encodeListCursor(exampleCursor)
+	actual, err := decodeListCursor(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleCursor, actual, "decoded cursor should match the encoded one")
+}
+
+func TestDecodeListCursorWithMalformedInput(t *testing.T) {
+	t.Parallel()
+	for _, in := range []string{"!!!", "bm9wZQ", "YTpi", "MTox"} {
+		_, err := decodeListCursor(in)
+		if in == "MTox" {
+			// `1:1` is a perfectly reasonable cursor
+			assert.Nil(t, err)
+			continue
+		}
+		assert.NotNil(t, err, "decoding cursor `%s` should fail", in)
+	}
+}
+
+func TestBuildNextCursor(t *testing.T) {
+	t.Parallel()
+	lastRow := DBRow{ID: exampleCursor.ID, CreatedOn: exampleCursor.CreatedOn}
+	cursorFilter := &QueryFilter{Page: 1, Limit: 3, UseCursor: true}
+
+	assert.Equal(t, encodeListCursor(exampleCursor), buildNextCursor(cursorFilter, 3, lastRow), "a full page should produce a cursor")
+	assert.Equal(t, "", buildNextCursor(cursorFilter, 2, lastRow), "a partial page should mean there's nothing left to fetch")
+	assert.Equal(t, "", buildNextCursor(defaultQueryFilter, 25, lastRow), "offset pagination should never produce a cursor")
+}
+
 func TestRestrictedStringIsValid(t *testing.T) {
 	testCases := []struct {
 		Input        string
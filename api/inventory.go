@@ -0,0 +1,485 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	inventoryLocationsTableHeaders = `id,
+		name,
+		sellable,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	inventoryLevelsTableHeaders = `id,
+		location_id,
+		product_id,
+		option_value_ids,
+		quantity,
+		created_on,
+		updated_on
+	`
+
+	inventoryLocationListQuery      = `SELECT * FROM inventory_locations WHERE archived_on IS NULL ORDER BY id`
+	inventoryLocationRetrievalQuery = `SELECT * FROM inventory_locations WHERE id = $1 AND archived_on IS NULL`
+	inventoryLocationExistenceQuery = `SELECT EXISTS(SELECT 1 FROM inventory_locations WHERE id = $1 AND archived_on IS NULL)`
+	inventoryLocationDeletionQuery  = `UPDATE inventory_locations SET archived_on = NOW() WHERE id = $1 AND archived_on IS NULL`
+
+	inventoryLevelListQueryForProduct = `
+		SELECT l.* FROM inventory_levels l
+			JOIN inventory_locations loc ON loc.id = l.location_id
+			WHERE l.product_id = $1
+			AND loc.archived_on IS NULL
+			ORDER BY l.location_id, l.option_value_ids
+	`
+	inventoryLevelUpsertQuery = `
+		INSERT INTO inventory_levels (location_id, product_id, option_value_ids, quantity) VALUES ($1, $2, $3, $4)
+		ON CONFLICT ("location_id", "product_id", "option_value_ids") DO UPDATE SET quantity = EXCLUDED.quantity, updated_on = NOW()
+		RETURNING ` + inventoryLevelsTableHeaders
+	inventoryLevelIncreaseQuery = `
+		INSERT INTO inventory_levels (location_id, product_id, option_value_ids, quantity) VALUES ($1, $2, $3, $4)
+		ON CONFLICT ("location_id", "product_id", "option_value_ids") DO UPDATE SET quantity = inventory_levels.quantity + EXCLUDED.quantity, updated_on = NOW()
+		RETURNING ` + inventoryLevelsTableHeaders
+	// stock can only be taken from a level that exists, and has enough in it, unless the product can be oversold
+	// and has room to be across all of its locations, in which case the level goes negative until it's restocked
+	inventoryLevelDecreaseQuery = `
+		UPDATE inventory_levels SET quantity = quantity + $4, updated_on = NOW()
+		WHERE location_id = $1 AND product_id = $2 AND option_value_ids = $3 AND (quantity + $4 >= 0 OR (
+			SELECT ` + productOversellAllowedCondition + ` AND (products.quantity + $4 >= ` + productStockFloorExpression + `) IS NOT FALSE
+			FROM products WHERE products.id = $2
+		))
+		RETURNING ` + inventoryLevelsTableHeaders
+	inventoryLevelQuantityQuery = `SELECT quantity FROM inventory_levels WHERE location_id = $1 AND product_id = $2 AND option_value_ids = $3 FOR UPDATE`
+
+	// a product's quantity is what's in stock at sellable locations, and is kept up to date here whenever
+	// stock levels change, so that everything that reads products.quantity sees the derived figure.
+	productQuantitySyncStatement = `
+		UPDATE products SET quantity = (
+			SELECT COALESCE(SUM(l.quantity), 0) FROM inventory_levels l
+				JOIN inventory_locations loc ON loc.id = l.location_id
+				WHERE l.product_id = products.id
+				AND loc.sellable
+				AND loc.archived_on IS NULL
+		)`
+	productQuantitySyncQuery            = productQuantitySyncStatement + ` WHERE id = $1`
+	productQuantitySyncQueryForLocation = productQuantitySyncStatement + ` WHERE id IN (SELECT product_id FROM inventory_levels WHERE location_id = $1)`
+	// products that are stocked at locations keep their derived quantity when they're updated directly
+	productQuantityUpdateExpression = `CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE ? END`
+)
+
+// errInventoryAdjustmentRefused means a stock change didn't go through because there isn't enough stock to take from
+var errInventoryAdjustmentRefused = errors.New("inventory adjustment refused")
+
+// InventoryLocation is somewhere stock is kept, like a warehouse or a shop
+type InventoryLocation struct {
+	DBRow
+	Name     string `json:"name"`
+	Sellable bool   `json:"sellable"`
+}
+
+func (l *InventoryLocation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&l.ID,
+		&l.Name,
+		&l.Sellable,
+		&l.CreatedOn,
+		&l.UpdatedOn,
+		&l.ArchivedOn,
+	}
+}
+
+// InventoryLocationCreationInput is a struct to use for creating inventory locations. Locations are sellable unless told otherwise.
+type InventoryLocationCreationInput struct {
+	Name     string `json:"name" validate:"required"`
+	Sellable *bool  `json:"sellable"`
+}
+
+// InventoryLocationUpdateInput is a struct to use for updating inventory locations
+type InventoryLocationUpdateInput struct {
+	Name     string `json:"name"`
+	Sellable *bool  `json:"sellable"`
+}
+
+// OptionValueIDs identifies a combination of option values. It's stored sorted and comma separated,
+// so that the same combination is always stored the same way, no matter what order it was given in.
+type OptionValueIDs []uint64
+
+// Value implements the driver.Valuer interface
+func (ids OptionValueIDs) Value() (driver.Value, error) {
+	sorted := append([]uint64{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	parts := []string{}
+	for _, id := range sorted {
+		parts = append(parts, strconv.FormatUint(id, 10))
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (ids *OptionValueIDs) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into OptionValueIDs", value)
+	}
+
+	*ids = OptionValueIDs{}
+	if raw == "" {
+		return nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return err
+		}
+		*ids = append(*ids, id)
+	}
+	return nil
+}
+
+// InventoryLevel is how much of a product, or of one combination of its options, is in stock at a location
+type InventoryLevel struct {
+	ID             uint64         `json:"id"`
+	LocationID     uint64         `json:"location_id"`
+	ProductID      uint64         `json:"product_id"`
+	OptionValueIDs OptionValueIDs `json:"option_value_ids"`
+	Quantity       int            `json:"quantity"`
+	CreatedOn      time.Time      `json:"created_on"`
+	UpdatedOn      NullTime       `json:"updated_on,omitempty"`
+}
+
+func (l *InventoryLevel) generateScanArgs() []interface{} {
+	return []interface{}{
+		&l.ID,
+		&l.LocationID,
+		&l.ProductID,
+		&l.OptionValueIDs,
+		&l.Quantity,
+		&l.CreatedOn,
+		&l.UpdatedOn,
+	}
+}
+
+// InventoryLevelInput is a struct to use for setting a stock level outright, like after a stock count
+type InventoryLevelInput struct {
+	OptionValueIDs []uint64 `json:"option_value_ids"`
+	Quantity       *int     `json:"quantity"`
+	Reference      string   `json:"reference"`
+}
+
+func retrieveInventoryLocationFromDB(db *sqlx.DB, locationID string) (*InventoryLocation, error) {
+	l := &InventoryLocation{}
+	err := db.QueryRow(inventoryLocationRetrievalQuery, locationID).Scan(l.generateScanArgs()...)
+	return l, err
+}
+
+// retrieveInventoryLevelsForProduct retrieves a product's stock at every location that has any record of it
+func retrieveInventoryLevelsForProduct(db *sqlx.DB, productID uint64) ([]InventoryLevel, error) {
+	levels := []InventoryLevel{}
+	rows, err := db.Query(inventoryLevelListQueryForProduct, productID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for inventory levels")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l InventoryLevel
+		if err = rows.Scan(l.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning inventory level")
+		}
+		levels = append(levels, l)
+	}
+	return levels, rows.Err()
+}
+
+// saveInventoryLevelInTransaction runs one of the level queries and brings the product's quantity back in line with
+// its locations. It returns errInventoryAdjustmentRefused if the level doesn't have enough stock.
+func saveInventoryLevelInTransaction(tx *sql.Tx, query string, level *InventoryLevel, quantity int) error {
+	err := tx.QueryRow(query, level.LocationID, level.ProductID, level.OptionValueIDs, quantity).Scan(level.generateScanArgs()...)
+	if err == sql.ErrNoRows {
+		return errInventoryAdjustmentRefused
+	} else if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(productQuantitySyncQuery, level.ProductID)
+	return err
+}
+
+// setInventoryLevel sets a level outright, and records the difference from what was there before as a
+// correction in the product's ledger, all in one transaction.
+func setInventoryLevel(db *sqlx.DB, level *InventoryLevel, quantity int, m *InventoryMovement) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Error creating database transaction")
+	}
+
+	var previousQuantity int
+	err = tx.QueryRow(inventoryLevelQuantityQuery, level.LocationID, level.ProductID, level.OptionValueIDs).Scan(&previousQuantity)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	if err = saveInventoryLevelInTransaction(tx, inventoryLevelUpsertQuery, level, quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if delta := quantity - previousQuantity; delta != 0 {
+		m.ProductID = level.ProductID
+		m.LocationID = &level.LocationID
+		m.OptionValueIDs = level.OptionValueIDs
+		m.Delta = delta
+		m.QuantityAfter = level.Quantity
+		m.Reason = inventoryMovementReasonCorrection
+		if err = createInventoryMovementInDB(tx, m); err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "Error recording inventory movement")
+		}
+	}
+	return tx.Commit()
+}
+
+// updateInventoryLocationInDB saves changes to a location, and recalculates the quantity of every product
+// stocked there, since the location's stock may have just started or stopped counting towards them
+func updateInventoryLocationInDB(db *sqlx.DB, l *InventoryLocation) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	query, args := buildInventoryLocationUpdateQuery(l)
+	if err = tx.QueryRow(query, args...).Scan(l.generateScanArgs()...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(productQuantitySyncQueryForLocation, l.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// archiveInventoryLocation archives a location, and takes its stock out of the quantity of every product stocked there
+func archiveInventoryLocation(db *sqlx.DB, locationID uint64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(inventoryLocationDeletionQuery, locationID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(productQuantitySyncQueryForLocation, locationID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func buildInventoryLocationListHandler(db *sqlx.DB) http.HandlerFunc {
+	// InventoryLocationListHandler is a request handler that returns every inventory location
+	return func(res http.ResponseWriter, req *http.Request) {
+		locations := []InventoryLocation{}
+		err := retrieveListOfRowsFromDB(db, inventoryLocationListQuery, nil, &locations)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve inventory locations from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(locations)
+	}
+}
+
+func buildInventoryLocationCreationHandler(db *sqlx.DB) http.HandlerFunc {
+	// InventoryLocationCreationHandler is a request handler that creates an inventory location from user input
+	return func(res http.ResponseWriter, req *http.Request) {
+		locationInput := &InventoryLocationCreationInput{}
+		err := validateRequestInput(req, locationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		location := &InventoryLocation{Name: locationInput.Name, Sellable: true}
+		if locationInput.Sellable != nil {
+			location.Sellable = *locationInput.Sellable
+		}
+		query, args := buildInventoryLocationCreationQuery(location)
+		err = db.QueryRow(query, args...).Scan(location.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "insert inventory location into database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(location)
+	}
+}
+
+func buildInventoryLocationUpdateHandler(db *sqlx.DB, notifier LowStockNotifier) http.HandlerFunc {
+	// InventoryLocationUpdateHandler is a request handler that renames an inventory location, or changes whether it's sellable
+	return func(res http.ResponseWriter, req *http.Request) {
+		locationID := chi.URLParam(req, "location_id")
+
+		locationInput := &InventoryLocationUpdateInput{}
+		err := validateRequestInput(req, locationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		location, err := retrieveInventoryLocationFromDB(db, locationID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "inventory location", locationID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve inventory location from database")
+			return
+		}
+
+		if locationInput.Name != "" {
+			location.Name = locationInput.Name
+		}
+		if locationInput.Sellable != nil {
+			location.Sellable = *locationInput.Sellable
+		}
+
+		err = updateInventoryLocationInDB(db, location)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update inventory location in database")
+			return
+		}
+		if locationInput.Sellable != nil {
+			checkLocationForLowStock(db, notifier, location.ID)
+		}
+
+		json.NewEncoder(res).Encode(location)
+	}
+}
+
+func buildInventoryLocationDeletionHandler(db *sqlx.DB, notifier LowStockNotifier) http.HandlerFunc {
+	// InventoryLocationDeletionHandler is a request handler that archives an inventory location. Its stock
+	// levels are kept, but stop counting towards the quantity of the products stocked there.
+	return func(res http.ResponseWriter, req *http.Request) {
+		locationID := chi.URLParam(req, "location_id")
+		// we can eat this error because Chi takes care of validating route params for us
+		parsedLocationID, _ := strconv.ParseUint(locationID, 10, 64)
+
+		exists, err := rowExistsInDB(db, inventoryLocationExistenceQuery, locationID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "inventory location", locationID)
+			return
+		}
+
+		err = archiveInventoryLocation(db, parsedLocationID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive inventory location")
+			return
+		}
+		checkLocationForLowStock(db, notifier, parsedLocationID)
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildProductInventoryListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductInventoryListHandler is a request handler that returns a product's stock at each location
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		levels, err := retrieveInventoryLevelsForProduct(db, productID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve inventory levels from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(levels)
+	}
+}
+
+func buildInventoryLevelUpdateHandler(db *sqlx.DB, store *sessions.CookieStore, notifier LowStockNotifier) http.HandlerFunc {
+	// InventoryLevelUpdateHandler is a request handler that sets how much of a product is in stock at a location
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		locationID := chi.URLParam(req, "location_id")
+		// we can eat this error because Chi takes care of validating route params for us
+		parsedLocationID, _ := strconv.ParseUint(locationID, 10, 64)
+
+		levelInput := &InventoryLevelInput{}
+		err := validateRequestInput(req, levelInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if levelInput.Quantity == nil || *levelInput.Quantity < 0 {
+			notifyOfInvalidRequestBody(res, errors.New("quantity must be zero or more"))
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		exists, err := rowExistsInDB(db, inventoryLocationExistenceQuery, locationID)
+		if err != nil || !exists {
+			respondThatRowDoesNotExist(req, res, "inventory location", locationID)
+			return
+		}
+
+		optionValues, err := retrieveProductOptionValuesForProduct(db, productID, levelInput.OptionValueIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+			return
+		}
+		if _, err = combineOptionValues(optionValues, levelInput.OptionValueIDs); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		level := &InventoryLevel{LocationID: parsedLocationID, ProductID: productID, OptionValueIDs: levelInput.OptionValueIDs}
+		userID, _ := retrieveUserIDFromSession(req, store)
+		movement := &InventoryMovement{Reference: levelInput.Reference, UserID: movementUserID(userID)}
+		err = setInventoryLevel(db, level, *levelInput.Quantity, movement)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save inventory level in database")
+			return
+		}
+		checkProductForLowStock(db, notifier, productID)
+
+		json.NewEncoder(res).Encode(level)
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	inventoryMovementsTableHeaders = `id,
+		product_id,
+		location_id,
+		option_value_ids,
+		delta,
+		quantity_after,
+		reason,
+		reference,
+		user_id,
+		created_on
+	`
+
+	inventoryMovementReasonReceived   = "received"
+	inventoryMovementReasonSold       = "sold"
+	inventoryMovementReasonDamaged    = "damaged"
+	inventoryMovementReasonCorrection = "correction"
+	inventoryMovementReasonReturned   = "returned"
+
+	inventoryMovementListQueryForProduct = `SELECT ` + inventoryMovementsTableHeaders + ` FROM inventory_movements WHERE product_id = $1 ORDER BY id`
+	productInventoryLevelExistenceQuery  = `SELECT EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = $1)`
+	// locking the product while its quantity is set directly keeps the correction recorded for it exact
+	productStockQuery = `SELECT quantity, EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) FROM products WHERE id = $1 FOR UPDATE`
+
+	// products that aren't stocked at locations have their quantity adjusted directly, in a single statement so that
+	// concurrent adjustments are serialized and what isn't held by reservations never goes below what its policy allows.
+	// Stock coming in is always let through, even if the product is still short of what's held afterwards.
+	productQuantityAdjustmentQuery = `
+		UPDATE products SET quantity = quantity + $2
+		WHERE id = $1 AND ($2 > 0 OR (quantity + $2 - ` + productActiveHoldsExpression + ` >= ` + productStockFloorExpression + `) IS NOT FALSE)
+		AND NOT EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id)
+		RETURNING quantity
+	`
+)
+
+var inventoryMovementReasons = map[string]bool{
+	inventoryMovementReasonReceived:   true,
+	inventoryMovementReasonSold:       true,
+	inventoryMovementReasonDamaged:    true,
+	inventoryMovementReasonCorrection: true,
+	inventoryMovementReasonReturned:   true,
+}
+
+// InventoryMovement is an entry in a product's stock ledger. Entries are never updated or removed.
+type InventoryMovement struct {
+	ID        uint64 `json:"id"`
+	ProductID uint64 `json:"product_id"`
+	// LocationID is only set for products that are stocked at inventory locations
+	LocationID     *uint64        `json:"location_id,omitempty"`
+	OptionValueIDs OptionValueIDs `json:"option_value_ids"`
+	Delta          int            `json:"delta"`
+	// QuantityAfter is what was left where the movement happened, which is the location if there is one
+	QuantityAfter int       `json:"quantity_after"`
+	Reason        string    `json:"reason"`
+	Reference     string    `json:"reference"`
+	UserID        *uint64   `json:"user_id,omitempty"`
+	CreatedOn     time.Time `json:"created_on"`
+}
+
+func (m *InventoryMovement) generateScanArgs() []interface{} {
+	return []interface{}{
+		&m.ID,
+		&m.ProductID,
+		&m.LocationID,
+		&m.OptionValueIDs,
+		&m.Delta,
+		&m.QuantityAfter,
+		&m.Reason,
+		&m.Reference,
+		&m.UserID,
+		&m.CreatedOn,
+	}
+}
+
+// InventoryMovementInput is a struct to use for adding stock to, or (with a negative delta) taking stock from a product.
+// Products that are stocked at inventory locations have to say which location the stock moved at.
+type InventoryMovementInput struct {
+	Delta          int      `json:"delta" validate:"required"`
+	Reason         string   `json:"reason" validate:"required"`
+	Reference      string   `json:"reference"`
+	LocationID     uint64   `json:"location_id"`
+	OptionValueIDs []uint64 `json:"option_value_ids"`
+}
+
+// movementUserID turns the ID of the user making a request into the user a movement is recorded against, if there is one
+func movementUserID(userID uint64) *uint64 {
+	if userID == 0 {
+		return nil
+	}
+	return &userID
+}
+
+func createInventoryMovementInDB(tx *sql.Tx, m *InventoryMovement) error {
+	query, args := buildInventoryMovementCreationQuery(m)
+	return tx.QueryRow(query, args...).Scan(m.generateScanArgs()...)
+}
+
+// recordInitialStock puts the stock a product was created with in its ledger, so that the ledger adds up from the start
+func recordInitialStock(tx *sql.Tx, productID uint64, quantity int, userID uint64) error {
+	if quantity == 0 {
+		return nil
+	}
+	return createInventoryMovementInDB(tx, &InventoryMovement{
+		ProductID:     productID,
+		Delta:         quantity,
+		QuantityAfter: quantity,
+		Reason:        inventoryMovementReasonReceived,
+		Reference:     "product creation",
+		UserID:        movementUserID(userID),
+	})
+}
+
+// recordQuantityCorrection records a quantity that was set directly, rather than adjusted, in the product's ledger
+func recordQuantityCorrection(tx *sql.Tx, productID uint64, before int, after int, userID uint64) error {
+	if before == after {
+		return nil
+	}
+	return createInventoryMovementInDB(tx, &InventoryMovement{
+		ProductID:     productID,
+		Delta:         after - before,
+		QuantityAfter: after,
+		Reason:        inventoryMovementReasonCorrection,
+		Reference:     "product update",
+		UserID:        movementUserID(userID),
+	})
+}
+
+// applyInventoryMovement applies a movement to the stock it's about and appends it to the ledger. It returns
+// errInventoryAdjustmentRefused if there isn't enough stock to take the movement's delta from.
+func applyInventoryMovement(tx *sql.Tx, m *InventoryMovement) error {
+	var err error
+	if m.LocationID != nil {
+		level := &InventoryLevel{LocationID: *m.LocationID, ProductID: m.ProductID, OptionValueIDs: m.OptionValueIDs}
+		query := inventoryLevelIncreaseQuery
+		if m.Delta < 0 {
+			query = inventoryLevelDecreaseQuery
+		}
+		err = saveInventoryLevelInTransaction(tx, query, level, m.Delta)
+		m.QuantityAfter = level.Quantity
+	} else {
+		err = tx.QueryRow(productQuantityAdjustmentQuery, m.ProductID, m.Delta).Scan(&m.QuantityAfter)
+		if err == sql.ErrNoRows {
+			err = errInventoryAdjustmentRefused
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = createInventoryMovementInDB(tx, m); err != nil {
+		return errors.Wrap(err, "Error recording inventory movement")
+	}
+	return nil
+}
+
+// adjustInventory applies a movement in its own transaction
+func adjustInventory(db *sqlx.DB, m *InventoryMovement) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Error creating database transaction")
+	}
+
+	if err = applyInventoryMovement(tx, m); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// retrieveInventoryMovementsForProduct retrieves a product's stock ledger, oldest entry first
+func retrieveInventoryMovementsForProduct(db *sqlx.DB, productID uint64) ([]InventoryMovement, error) {
+	movements := []InventoryMovement{}
+	rows, err := db.Query(inventoryMovementListQueryForProduct, productID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for inventory movements")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m InventoryMovement
+		if err = rows.Scan(m.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning inventory movement")
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+func buildProductInventoryAdjustmentHandler(db *sqlx.DB, store *sessions.CookieStore, notifier LowStockNotifier) http.HandlerFunc {
+	// ProductInventoryAdjustmentHandler is a request handler that moves stock in or out, and records why in the product's ledger
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		movementInput := &InventoryMovementInput{}
+		err := validateRequestInput(req, movementInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if !inventoryMovementReasons[movementInput.Reason] {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The reason received (%s) is invalid", movementInput.Reason))
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+		if isBundle, err := productIsBundle(db, productID); err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundle from the database")
+			return
+		} else if isBundle {
+			notifyOfInvalidRequestBody(res, errStockOperationOnBundle)
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		movement := &InventoryMovement{
+			ProductID:      productID,
+			OptionValueIDs: movementInput.OptionValueIDs,
+			Delta:          movementInput.Delta,
+			Reason:         movementInput.Reason,
+			Reference:      movementInput.Reference,
+			UserID:         movementUserID(userID),
+		}
+
+		if movementInput.LocationID != 0 {
+			locationID := fmt.Sprintf("%d", movementInput.LocationID)
+			exists, err := rowExistsInDB(db, inventoryLocationExistenceQuery, locationID)
+			if err != nil || !exists {
+				respondThatRowDoesNotExist(req, res, "inventory location", locationID)
+				return
+			}
+
+			optionValues, err := retrieveProductOptionValuesForProduct(db, productID, movementInput.OptionValueIDs)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+				return
+			}
+			if _, err = combineOptionValues(optionValues, movementInput.OptionValueIDs); err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+			movement.LocationID = &movementInput.LocationID
+		} else {
+			if len(movementInput.OptionValueIDs) > 0 {
+				notifyOfInvalidRequestBody(res, errors.New("option combinations are only stocked at inventory locations, so a location_id is required"))
+				return
+			}
+			stockedAtLocations, err := rowExistsInDB(db, productInventoryLevelExistenceQuery, fmt.Sprintf("%d", productID))
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve inventory levels from the database")
+				return
+			} else if stockedAtLocations {
+				notifyOfInvalidRequestBody(res, fmt.Errorf("%s is stocked at inventory locations, so a location_id is required", sku))
+				return
+			}
+		}
+
+		err = adjustInventory(db, movement)
+		if err == errInventoryAdjustmentRefused {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("there isn't enough stock to take %d away", -movementInput.Delta))
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "adjust inventory")
+			return
+		}
+		checkProductForLowStock(db, notifier, productID)
+
+		json.NewEncoder(res).Encode(movement)
+	}
+}
+
+func buildProductInventoryMovementListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductInventoryMovementListHandler is a request handler that returns a product's stock ledger, oldest entry first
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		movements, err := retrieveInventoryMovementsForProduct(db, productID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve inventory movements from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(movements)
+	}
+}
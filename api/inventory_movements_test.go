@@ -0,0 +1,337 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var inventoryMovementHeaders = strings.Split(strings.TrimSpace(inventoryMovementsTableHeaders), ",\n\t\t")
+
+func setExpectationsForInventoryMovementCreation(mock sqlmock.Sqlmock, m *InventoryMovement, err error) {
+	exampleRows := sqlmock.NewRows(inventoryMovementHeaders).
+		AddRow(1, m.ProductID, m.LocationID, "", m.Delta, m.QuantityAfter, m.Reason, m.Reference, 1, generateExampleTimeForTests())
+	query, _ := buildInventoryMovementCreationQuery(m)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+// setExpectationsForStockRecord expects a ledger entry for stock that was set on a product rather than adjusted
+func setExpectationsForStockRecord(mock sqlmock.Sqlmock, m *InventoryMovement) {
+	exampleRows := sqlmock.NewRows(inventoryMovementHeaders).
+		AddRow(1, m.ProductID, nil, "", m.Delta, m.QuantityAfter, m.Reason, m.Reference, nil, generateExampleTimeForTests())
+	query, args := buildInventoryMovementCreationQuery(m)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows)
+}
+
+func setExpectationsForProductStock(mock sqlmock.Sqlmock, productID uint64, quantity int, stockedAtLocations bool) {
+	exampleRows := sqlmock.NewRows([]string{"quantity", "stocked_at_locations"}).AddRow(quantity, stockedAtLocations)
+	mock.ExpectQuery(formatQueryForSQLMock(productStockQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows)
+}
+
+func setExpectationsForInitialStock(mock sqlmock.Sqlmock, productID uint64, quantity int) {
+	setExpectationsForStockRecord(mock, &InventoryMovement{
+		ProductID:     productID,
+		Delta:         quantity,
+		QuantityAfter: quantity,
+		Reason:        inventoryMovementReasonReceived,
+		Reference:     "product creation",
+	})
+}
+
+func setExpectationsForProductInventoryLevelExistence(mock sqlmock.Sqlmock, productID uint64, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(productInventoryLevelExistenceQuery)).
+		WithArgs(strconv.Itoa(int(productID))).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductQuantityAdjustment(mock sqlmock.Sqlmock, productID uint64, delta int, resultingQuantity int, err error) {
+	exampleRows := sqlmock.NewRows([]string{"quantity"}).AddRow(resultingQuantity)
+	mock.ExpectQuery(formatQueryForSQLMock(productQuantityAdjustmentQuery)).
+		WithArgs(productID, delta).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductInventoryAdjustmentHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductQuantityAdjustment(testUtil.Mock, exampleProduct.ID, 5, 128, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, Delta: 5, QuantityAfter: 128, Reason: inventoryMovementReasonReceived, Reference: "PO-1138"}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	body := `{"delta": 5, "reason": "received", "reference": "PO-1138"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &InventoryMovement{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, actual.Delta)
+	assert.Equal(t, 128, actual.QuantityAfter)
+	assert.Equal(t, "PO-1138", actual.Reference)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerAtLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelIncreaseQuery, level, 5, 9, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, LocationID: &exampleInventoryLocation.ID, Delta: 5, QuantityAfter: 9, Reason: inventoryMovementReasonReturned}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	body := `{"delta": 5, "reason": "returned", "location_id": 2}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &InventoryMovement{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, actual.QuantityAfter)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerTakingStockAtLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelDecreaseQuery, level, -2, 2, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, LocationID: &exampleInventoryLocation.ID, Delta: -2, QuantityAfter: 2, Reason: inventoryMovementReasonDamaged}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID, exampleLowStockProduct)
+
+	body := `{"delta": -2, "reason": "damaged", "location_id": 2}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	assert.Equal(t, 1, len(testUtil.Notifier.notified))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithInsufficientStock(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductQuantityAdjustment(testUtil.Mock, exampleProduct.ID, -200, 0, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+
+	body := `{"delta": -200, "reason": "sold"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithErrorRecordingMovement(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductQuantityAdjustment(testUtil.Mock, exampleProduct.ID, -1, 122, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, Delta: -1, QuantityAfter: 122, Reason: inventoryMovementReasonSold}, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	body := `{"delta": -1, "reason": "sold"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerForProductStockedAtLocations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleProduct.ID, true, nil)
+
+	body := `{"delta": 5, "reason": "received"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithOptionValuesButNoLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+
+	body := `{"delta": 5, "reason": "received", "option_value_ids": [7]}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerForBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, true, nil)
+
+	body := `{"delta": 5, "reason": "received"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithInvalidReason(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"delta": 5, "reason": "found it behind the couch"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithoutDelta(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(`{"reason": "received"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerWithNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "nonexistent", 0, sql.ErrNoRows)
+
+	body := `{"delta": 5, "reason": "received"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", "nonexistent", "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryAdjustmentHandlerForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"delta": 5, "reason": "received"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "adjust"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryMovementListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleRows := sqlmock.NewRows(inventoryMovementHeaders).
+		AddRow(1, exampleProduct.ID, nil, "", 10, 10, inventoryMovementReasonReceived, "PO-1138", 1, generateExampleTimeForTests()).
+		AddRow(2, exampleProduct.ID, nil, "", -3, 7, inventoryMovementReasonSold, "", nil, generateExampleTimeForTests())
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(inventoryMovementListQueryForProduct)).
+		WithArgs(exampleProduct.ID).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "movements"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []InventoryMovement{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Equal(t, -3, actual[1].Delta)
+	assert.Nil(t, actual[1].UserID)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryMovementListHandlerWithNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "nonexistent", 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product", "nonexistent", "inventory", "movements"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	inventoryLocationHeaders = strings.Split(strings.TrimSpace(inventoryLocationsTableHeaders), ",\n\t\t")
+	inventoryLevelHeaders    = strings.Split(strings.TrimSpace(inventoryLevelsTableHeaders), ",\n\t\t")
+	exampleInventoryLocation = &InventoryLocation{
+		DBRow: DBRow{
+			ID:        2,
+			CreatedOn: generateExampleTimeForTests(),
+		},
+		Name:     "east warehouse",
+		Sellable: true,
+	}
+)
+
+func setExpectationsForInventoryLocationExistence(mock sqlmock.Sqlmock, id string, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(inventoryLocationExistenceQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForInventoryLocationRetrieval(mock sqlmock.Sqlmock, id string, err error) {
+	exampleRows := sqlmock.NewRows(inventoryLocationHeaders).
+		AddRow(exampleInventoryLocation.ID, exampleInventoryLocation.Name, exampleInventoryLocation.Sellable, exampleInventoryLocation.CreatedOn, nil, nil)
+	mock.ExpectQuery(formatQueryForSQLMock(inventoryLocationRetrievalQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForInventoryLevelSave(mock sqlmock.Sqlmock, query string, level *InventoryLevel, quantity int, resultingQuantity int, err error) {
+	exampleRows := sqlmock.NewRows(inventoryLevelHeaders).
+		AddRow(1, level.LocationID, level.ProductID, "", resultingQuantity, generateExampleTimeForTests(), nil)
+	optionValueIDs, _ := level.OptionValueIDs.Value()
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(level.LocationID, level.ProductID, optionValueIDs, quantity).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForInventoryLevelQuantityRetrieval(mock sqlmock.Sqlmock, level *InventoryLevel, quantity int, err error) {
+	exampleRows := sqlmock.NewRows([]string{"quantity"}).AddRow(quantity)
+	optionValueIDs, _ := level.OptionValueIDs.Value()
+	mock.ExpectQuery(formatQueryForSQLMock(inventoryLevelQuantityQuery)).
+		WithArgs(level.LocationID, level.ProductID, optionValueIDs).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductQuantitySync(mock sqlmock.Sqlmock, productID uint64, err error) {
+	mock.ExpectExec(formatQueryForSQLMock(productQuantitySyncQuery)).
+		WithArgs(productID).
+		WillReturnResult(sqlmock.NewResult(1, 1)).
+		WillReturnError(err)
+}
+
+func TestOptionValueIDsValue(t *testing.T) {
+	t.Parallel()
+
+	actual, err := OptionValueIDs{12, 3, 7}.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "3,7,12", actual, "option value IDs should be stored in order")
+
+	actual, err = OptionValueIDs(nil).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "", actual)
+}
+
+func TestOptionValueIDsScan(t *testing.T) {
+	t.Parallel()
+
+	var ids OptionValueIDs
+	assert.Nil(t, ids.Scan([]byte("3,7,12")))
+	assert.Equal(t, OptionValueIDs{3, 7, 12}, ids)
+
+	assert.Nil(t, ids.Scan(""))
+	assert.Equal(t, OptionValueIDs{}, ids, "empty combinations should scan as an empty list rather than nil")
+
+	assert.NotNil(t, ids.Scan("3,x"))
+	assert.NotNil(t, ids.Scan(3))
+}
+
+func TestInventoryLocationListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleRows := sqlmock.NewRows(inventoryLocationHeaders).
+		AddRow(exampleInventoryLocation.ID, exampleInventoryLocation.Name, exampleInventoryLocation.Sellable, exampleInventoryLocation.CreatedOn, nil, nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(inventoryLocationListQuery)).WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/inventory_locations", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []InventoryLocation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleInventoryLocation.Name, actual[0].Name)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationListHandlerForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/inventory_locations", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	location := &InventoryLocation{Name: "returns", Sellable: false}
+	query, args := buildInventoryLocationCreationQuery(location)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(inventoryLocationHeaders).AddRow(3, "returns", false, generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/inventory_locations", strings.NewReader(`{"name": "returns", "sellable": false}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &InventoryLocation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.False(t, actual.Sellable)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationCreationHandlerIsSellableByDefault(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	location := &InventoryLocation{Name: "shop", Sellable: true}
+	query, args := buildInventoryLocationCreationQuery(location)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(inventoryLocationHeaders).AddRow(3, "shop", true, generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/inventory_locations", strings.NewReader(`{"name": "shop"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationCreationHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/inventory_locations", strings.NewReader(`{"sellable": true}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationUpdateHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	setExpectationsForInventoryLocationRetrieval(testUtil.Mock, locationID, nil)
+	testUtil.Mock.ExpectBegin()
+	updated := &InventoryLocation{DBRow: exampleInventoryLocation.DBRow, Name: exampleInventoryLocation.Name, Sellable: false}
+	query, args := buildInventoryLocationUpdateQuery(updated)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(inventoryLocationHeaders).AddRow(updated.ID, updated.Name, false, generateExampleTimeForTests(), generateExampleTimeForTests(), nil))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productQuantitySyncQueryForLocation)).
+		WithArgs(exampleInventoryLocation.ID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForLocationLowStockCheck(testUtil.Mock, exampleInventoryLocation.ID)
+
+	req, err := http.NewRequest(http.MethodPatch, buildRoute("v1", "inventory_locations", locationID), strings.NewReader(`{"sellable": false}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &InventoryLocation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.False(t, actual.Sellable)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationUpdateHandlerWithNonexistentLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForInventoryLocationRetrieval(testUtil.Mock, "9", sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPatch, buildRoute("v1", "inventory_locations", "9"), strings.NewReader(`{"name": "west warehouse"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationUpdateHandlerWithErrorSyncingQuantities(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	setExpectationsForInventoryLocationRetrieval(testUtil.Mock, locationID, nil)
+	testUtil.Mock.ExpectBegin()
+	updated := &InventoryLocation{DBRow: exampleInventoryLocation.DBRow, Name: "west warehouse", Sellable: true}
+	query, args := buildInventoryLocationUpdateQuery(updated)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(inventoryLocationHeaders).AddRow(updated.ID, updated.Name, true, generateExampleTimeForTests(), generateExampleTimeForTests(), nil))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productQuantitySyncQueryForLocation)).
+		WithArgs(exampleInventoryLocation.ID).
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPatch, buildRoute("v1", "inventory_locations", locationID), strings.NewReader(`{"name": "west warehouse"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(inventoryLocationDeletionQuery)).
+		WithArgs(exampleInventoryLocation.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productQuantitySyncQueryForLocation)).
+		WithArgs(exampleInventoryLocation.ID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForLocationLowStockCheck(testUtil.Mock, exampleInventoryLocation.ID)
+
+	req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "inventory_locations", locationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLocationDeletionHandlerWithNonexistentLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, "9", false, nil)
+
+	req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "inventory_locations", "9"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	exampleRows := sqlmock.NewRows(inventoryLevelHeaders).
+		AddRow(1, exampleInventoryLocation.ID, exampleProduct.ID, "", 4, generateExampleTimeForTests(), nil).
+		AddRow(2, exampleInventoryLocation.ID, exampleProduct.ID, "3,7", 2, generateExampleTimeForTests(), nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(inventoryLevelListQueryForProduct)).
+		WithArgs(exampleProduct.ID).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product", exampleProduct.SKU, "inventory"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []InventoryLevel{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Equal(t, OptionValueIDs{}, actual[0].OptionValueIDs)
+	assert.Equal(t, OptionValueIDs{3, 7}, actual[1].OptionValueIDs)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductInventoryListHandlerWithNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "nonexistent", 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product", "nonexistent", "inventory"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelQuantityRetrieval(testUtil.Mock, level, 0, sql.ErrNoRows)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelUpsertQuery, level, 0, 0, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", locationID), strings.NewReader(`{"quantity": 0}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerForOptionCombination(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	value := ProductOptionValue{DBRow: DBRow{ID: 7}, ProductOptionID: 1, Value: "large"}
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID, OptionValueIDs: OptionValueIDs{7}}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{7}, []ProductOptionValue{value}, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelQuantityRetrieval(testUtil.Mock, level, 4, nil)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelUpsertQuery, level, 12, 12, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, Delta: 8, QuantityAfter: 12, Reason: inventoryMovementReasonCorrection}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	body := `{"quantity": 12, "option_value_ids": [7]}`
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", locationID), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &InventoryLevel{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, actual.Quantity)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerWithOptionValueFromAnotherProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{99}, nil, nil)
+
+	body := `{"quantity": 12, "option_value_ids": [99]}`
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", locationID), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerWithNegativeQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "2"), strings.NewReader(`{"quantity": -1}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerWithNonexistentLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, "9", false, nil)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", "9"), strings.NewReader(`{"quantity": 3}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerWithErrorSyncingQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelQuantityRetrieval(testUtil.Mock, level, 0, sql.ErrNoRows)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelUpsertQuery, level, 3, 3, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", locationID), strings.NewReader(`{"quantity": 3}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestInventoryLevelUpdateHandlerWithErrorRecordingMovement(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleProduct.ID}
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForInventoryLevelQuantityRetrieval(testUtil.Mock, level, 5, nil)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelUpsertQuery, level, 3, 3, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleProduct.ID, Delta: -2, QuantityAfter: 3, Reason: inventoryMovementReasonCorrection}, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", exampleProduct.SKU, "inventory", locationID), strings.NewReader(`{"quantity": 3, "reference": "stock count"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
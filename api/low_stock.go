@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// bundles are left out, since they're restocked by restocking their components, which have thresholds of their own
+	lowStockProductCondition = `p.reorder_threshold IS NOT NULL AND p.quantity <= p.reorder_threshold AND p.archived_on IS NULL
+		AND NOT EXISTS(SELECT 1 FROM product_bundles b WHERE b.product_id = p.id AND b.archived_on IS NULL)`
+
+	lowStockReportQuery = `
+		SELECT p.id, p.sku, p.name, p.quantity, p.reorder_threshold, a.created_on FROM products p
+			LEFT JOIN low_stock_alerts a ON a.product_id = p.id
+			WHERE ` + lowStockProductCondition + `
+			ORDER BY p.quantity - p.reorder_threshold, p.id
+	`
+
+	// checks are scoped either to a single product, or to every product stocked at a location
+	lowStockProductScope  = ` AND p.id = $1`
+	lowStockLocationScope = ` AND p.id IN (SELECT product_id FROM inventory_levels WHERE location_id = $1)`
+
+	// products that have been restocked above their threshold (or no longer have one) can be alerted about again
+	lowStockAlertRearmStatement = `
+		DELETE FROM low_stock_alerts a USING products p
+		WHERE p.id = a.product_id AND (p.reorder_threshold IS NULL OR p.quantity > p.reorder_threshold)`
+	// a product is only alerted about when it doesn't already have an alert, so however many
+	// updates race past its threshold, only one of them gets to tell anybody
+	lowStockAlertRaiseStatementPrefix = `
+		WITH raised AS (
+			INSERT INTO low_stock_alerts (product_id)
+			SELECT p.id FROM products p WHERE ` + lowStockProductCondition
+	lowStockAlertRaiseStatementSuffix = `
+			ON CONFLICT DO NOTHING
+			RETURNING product_id, created_on
+		)
+		SELECT p.id, p.sku, p.name, p.quantity, p.reorder_threshold, r.created_on FROM products p
+			JOIN raised r ON r.product_id = p.id
+			ORDER BY p.id
+	`
+
+	lowStockAlertRearmQuery            = lowStockAlertRearmStatement + lowStockProductScope
+	lowStockAlertRearmQueryForLocation = lowStockAlertRearmStatement + lowStockLocationScope
+	lowStockAlertRaiseQuery            = lowStockAlertRaiseStatementPrefix + lowStockProductScope + lowStockAlertRaiseStatementSuffix
+	lowStockAlertRaiseQueryForLocation = lowStockAlertRaiseStatementPrefix + lowStockLocationScope + lowStockAlertRaiseStatementSuffix
+)
+
+// LowStockProduct is a product that has run down to its reorder threshold
+type LowStockProduct struct {
+	ProductID        uint64 `json:"product_id"`
+	SKU              string `json:"sku"`
+	Name             string `json:"name"`
+	Quantity         int    `json:"quantity"`
+	ReorderThreshold uint32 `json:"reorder_threshold"`
+	// LowSince is when purchasing was told about it, if they have been
+	LowSince NullTime `json:"low_since"`
+}
+
+func (p *LowStockProduct) generateScanArgs() []interface{} {
+	return []interface{}{
+		&p.ProductID,
+		&p.SKU,
+		&p.Name,
+		&p.Quantity,
+		&p.ReorderThreshold,
+		&p.LowSince,
+	}
+}
+
+// LowStockNotifier is told whenever a product runs down to its reorder threshold
+type LowStockNotifier interface {
+	NotifyOfLowStock(p LowStockProduct) error
+}
+
+// LogLowStockNotifier is a LowStockNotifier that writes to the application log
+type LogLowStockNotifier struct{}
+
+// NotifyOfLowStock logs that a product is running low
+func (n LogLowStockNotifier) NotifyOfLowStock(p LowStockProduct) error {
+	log.Printf("product %s is running low: %d left, reorder threshold is %d", p.SKU, p.Quantity, p.ReorderThreshold)
+	return nil
+}
+
+// FileLowStockNotifier is a LowStockNotifier that appends each notification to a file as a line of JSON
+type FileLowStockNotifier struct {
+	sync.Mutex
+	path string
+}
+
+// NewFileLowStockNotifier returns a FileLowStockNotifier that writes to the given file, creating it if need be
+func NewFileLowStockNotifier(path string) (*FileLowStockNotifier, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error opening low stock notification file")
+	}
+	return &FileLowStockNotifier{path: path}, f.Close()
+}
+
+// NotifyOfLowStock appends a product that's running low to the notifier's file
+func (n *FileLowStockNotifier) NotifyOfLowStock(p LowStockProduct) error {
+	n.Lock()
+	defer n.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error opening low stock notification file")
+	}
+	if err = json.NewEncoder(f).Encode(p); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Error writing low stock notification")
+	}
+	return f.Close()
+}
+
+// checkForLowStock re-arms the alerts of products that have been restocked, and notifies of the ones that have just
+// run low. The stock change that prompted the check has already happened by now, so failures are only logged.
+func checkForLowStock(db *sqlx.DB, notifier LowStockNotifier, rearmQuery, raiseQuery string, id uint64) {
+	if _, err := db.Exec(rearmQuery, id); err != nil {
+		log.Printf("error encountered re-arming low stock alerts: %v", err)
+		return
+	}
+
+	rows, err := db.Query(raiseQuery, id)
+	if err != nil {
+		log.Printf("error encountered raising low stock alerts: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p LowStockProduct
+		if err = rows.Scan(p.generateScanArgs()...); err != nil {
+			log.Printf("error encountered scanning low stock alert: %v", err)
+			return
+		}
+		if err = notifier.NotifyOfLowStock(p); err != nil {
+			log.Printf("error encountered notifying of low stock for product %s: %v", p.SKU, err)
+		}
+	}
+}
+
+// checkProductForLowStock checks whether a product's stock has just crossed its reorder threshold
+func checkProductForLowStock(db *sqlx.DB, notifier LowStockNotifier, productID uint64) {
+	checkForLowStock(db, notifier, lowStockAlertRearmQuery, lowStockAlertRaiseQuery, productID)
+}
+
+// checkLocationForLowStock checks every product stocked at a location, for when the location's stock as a whole starts or stops counting
+func checkLocationForLowStock(db *sqlx.DB, notifier LowStockNotifier, locationID uint64) {
+	checkForLowStock(db, notifier, lowStockAlertRearmQueryForLocation, lowStockAlertRaiseQueryForLocation, locationID)
+}
+
+func buildLowStockReportHandler(db *sqlx.DB) http.HandlerFunc {
+	// LowStockReportHandler is a request handler that returns every product at or below its reorder threshold, lowest first
+	return func(res http.ResponseWriter, req *http.Request) {
+		rows, err := db.Query(lowStockReportQuery)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve low stock products from the database")
+			return
+		}
+		defer rows.Close()
+
+		products := []LowStockProduct{}
+		for rows.Next() {
+			var p LowStockProduct
+			if err = rows.Scan(p.generateScanArgs()...); err != nil {
+				notifyOfInternalIssue(res, err, "scan low stock product")
+				return
+			}
+			products = append(products, p)
+		}
+		json.NewEncoder(res).Encode(products)
+	}
+}
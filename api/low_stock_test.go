@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	lowStockProductHeaders = []string{"id", "sku", "name", "quantity", "reorder_threshold", "created_on"}
+	exampleLowStockProduct = LowStockProduct{
+		ProductID:        2,
+		SKU:              "skateboard",
+		Name:             "Skateboard",
+		Quantity:         3,
+		ReorderThreshold: 5,
+	}
+)
+
+// recordingLowStockNotifier is a LowStockNotifier for tests that remembers what it was told
+type recordingLowStockNotifier struct {
+	sync.Mutex
+	notified []LowStockProduct
+}
+
+func (n *recordingLowStockNotifier) NotifyOfLowStock(p LowStockProduct) error {
+	n.Lock()
+	defer n.Unlock()
+	n.notified = append(n.notified, p)
+	return nil
+}
+
+func setExpectationsForLowStockCheck(mock sqlmock.Sqlmock, rearmQuery, raiseQuery string, id uint64, raised []LowStockProduct) {
+	mock.ExpectExec(formatQueryForSQLMock(rearmQuery)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	exampleRows := sqlmock.NewRows(lowStockProductHeaders)
+	for _, p := range raised {
+		exampleRows = exampleRows.AddRow(p.ProductID, p.SKU, p.Name, p.Quantity, p.ReorderThreshold, generateExampleTimeForTests())
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(raiseQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows)
+}
+
+func setExpectationsForProductLowStockCheck(mock sqlmock.Sqlmock, productID uint64, raised ...LowStockProduct) {
+	setExpectationsForLowStockCheck(mock, lowStockAlertRearmQuery, lowStockAlertRaiseQuery, productID, raised)
+}
+
+func setExpectationsForLocationLowStockCheck(mock sqlmock.Sqlmock, locationID uint64, raised ...LowStockProduct) {
+	setExpectationsForLowStockCheck(mock, lowStockAlertRearmQueryForLocation, lowStockAlertRaiseQueryForLocation, locationID, raised)
+}
+
+func TestFileLowStockNotifier(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "low_stock")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "alerts.log")
+	notifier, err := NewFileLowStockNotifier(path)
+	assert.Nil(t, err)
+
+	second := exampleLowStockProduct
+	second.SKU = "helmet"
+	assert.Nil(t, notifier.NotifyOfLowStock(exampleLowStockProduct))
+	assert.Nil(t, notifier.NotifyOfLowStock(second))
+
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	skus := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var p LowStockProduct
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &p))
+		skus = append(skus, p.SKU)
+	}
+	assert.Equal(t, []string{"skateboard", "helmet"}, skus)
+}
+
+func TestNewFileLowStockNotifierWithUnwritablePath(t *testing.T) {
+	t.Parallel()
+	_, err := NewFileLowStockNotifier(filepath.Join("nonexistent", "directory", "alerts.log"))
+	assert.NotNil(t, err)
+}
+
+func TestCheckProductForLowStock(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleLowStockProduct.ProductID, exampleLowStockProduct)
+
+	checkProductForLowStock(testUtil.DB, testUtil.Notifier, exampleLowStockProduct.ProductID)
+	assert.Equal(t, 1, len(testUtil.Notifier.notified))
+	assert.Equal(t, "skateboard", testUtil.Notifier.notified[0].SKU)
+	assert.True(t, testUtil.Notifier.notified[0].LowSince.Valid)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCheckProductForLowStockWhenAlreadyAlerted(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleLowStockProduct.ProductID)
+
+	checkProductForLowStock(testUtil.DB, testUtil.Notifier, exampleLowStockProduct.ProductID)
+	assert.Equal(t, 0, len(testUtil.Notifier.notified))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCheckProductForLowStockWithErrorRearmingAlerts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(lowStockAlertRearmQuery)).
+		WithArgs(exampleLowStockProduct.ProductID).
+		WillReturnError(arbitraryError)
+
+	checkProductForLowStock(testUtil.DB, testUtil.Notifier, exampleLowStockProduct.ProductID)
+	assert.Equal(t, 0, len(testUtil.Notifier.notified))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestCheckLocationForLowStock(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	second := exampleLowStockProduct
+	second.ProductID = 3
+	setExpectationsForLocationLowStockCheck(testUtil.Mock, exampleInventoryLocation.ID, exampleLowStockProduct, second)
+
+	checkLocationForLowStock(testUtil.DB, testUtil.Notifier, exampleInventoryLocation.ID)
+	assert.Equal(t, 2, len(testUtil.Notifier.notified))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestLowStockReportHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleRows := sqlmock.NewRows(lowStockProductHeaders).
+		AddRow(2, "skateboard", "Skateboard", 0, 5, generateExampleTimeForTests()).
+		AddRow(3, "helmet", "Helmet", 4, 4, nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(lowStockReportQuery)).
+		WillReturnRows(exampleRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "inventory", "low_stock"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []LowStockProduct{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Equal(t, "helmet", actual[1].SKU)
+	assert.True(t, actual[1].LowSince.Time.IsZero())
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestLowStockReportHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(lowStockReportQuery)).
+		WillReturnError(arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "inventory", "low_stock"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestLowStockReportHandlerForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "inventory", "low_stock"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -95,9 +95,41 @@ func main() {
 		log.Fatalf("Something is up with your app secret: `%s`", secret)
 	}
 	store := sessions.NewCookieStore([]byte(secret))
+	setDownloadSigningKey([]byte(secret))
+
+	blobDir := os.Getenv("DAIRYCART_BLOB_DIR")
+	if blobDir == "" {
+		blobDir = "blobs"
+	}
+	blobs, err := NewLocalBlobStore(blobDir)
+	if err != nil {
+		log.Fatalf("error encountered setting up blob storage: %v", err)
+	}
+
+	var notifier LowStockNotifier = LogLowStockNotifier{}
+	if alertFile := os.Getenv("DAIRYCART_LOW_STOCK_ALERT_FILE"); alertFile != "" {
+		notifier, err = NewFileLowStockNotifier(alertFile)
+		if err != nil {
+			log.Fatalf("error encountered setting up low stock notifications: %v", err)
+		}
+	}
+
+	if currency := os.Getenv("DAIRYCART_BASE_CURRENCY"); currency != "" {
+		if err = setBaseCurrency(currency); err != nil {
+			log.Fatalf("error encountered setting base currency: %v", err)
+		}
+	}
+
+	if locale := os.Getenv("DAIRYCART_BASE_LOCALE"); locale != "" {
+		if err = setBaseLocale(locale); err != nil {
+			log.Fatalf("error encountered setting base locale: %v", err)
+		}
+	}
+
+	go sweepExpiredReservations(db, reservationSweepInterval)
 
 	v1APIRouter := chi.NewRouter()
-	SetupAPIRoutes(v1APIRouter, db, store)
+	SetupAPIRoutes(v1APIRouter, db, store, blobs, notifier)
 
 	// serve 'em up a lil' sauce
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "👍") })
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS discounts_created_on_id_idx;
+DROP INDEX IF EXISTS product_options_created_on_id_idx;
+DROP INDEX IF EXISTS products_created_on_id_idx;
\ No newline at end of file
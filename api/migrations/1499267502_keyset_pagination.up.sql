@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS products_created_on_id_idx ON products ("created_on", "id");
+CREATE INDEX IF NOT EXISTS product_options_created_on_id_idx ON product_options ("created_on", "id");
+CREATE INDEX IF NOT EXISTS discounts_created_on_id_idx ON discounts ("created_on", "id");
\ No newline at end of file
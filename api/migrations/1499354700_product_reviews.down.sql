@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS product_reviews_status_idx;
+DROP TABLE IF EXISTS product_reviews;
+DROP TYPE IF EXISTS product_review_status;
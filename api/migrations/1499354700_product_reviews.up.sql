@@ -0,0 +1,20 @@
+CREATE TYPE product_review_status AS ENUM ('pending', 'approved', 'rejected');
+CREATE TABLE IF NOT EXISTS product_reviews (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "rating" smallint NOT NULL CONSTRAINT rating_must_be_between_one_and_five CHECK(rating BETWEEN 1 AND 5),
+    "title" text NOT NULL DEFAULT '',
+    "body" text NOT NULL DEFAULT '',
+    "verified_purchase" boolean DEFAULT FALSE,
+    "status" product_review_status NOT NULL DEFAULT 'pending',
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id", "user_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS product_reviews_status_idx ON product_reviews ("product_id", "status");
@@ -0,0 +1,2 @@
+DROP TABLE exchange_rates;
+DROP TABLE product_prices;
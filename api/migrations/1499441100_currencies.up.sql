@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS product_prices (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "currency" char(3) NOT NULL,
+    "price" numeric(15, 2) NOT NULL,
+    "sale_price" numeric(15, 2) NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id", "currency"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    "id" bigserial,
+    "currency" char(3) NOT NULL,
+    "rate" numeric(18, 8) NOT NULL CONSTRAINT rate_must_be_positive CHECK(rate > 0),
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("currency"),
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product_price_tiers (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "min_quantity" integer NOT NULL CONSTRAINT min_quantity_must_be_positive CHECK(min_quantity > 0),
+    "unit_price" numeric(15, 2) NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id", "min_quantity"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
@@ -0,0 +1,3 @@
+DROP TABLE customer_group_prices;
+DROP TABLE customer_group_members;
+DROP TABLE customer_groups;
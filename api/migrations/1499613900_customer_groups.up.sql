@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS customer_groups (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text NOT NULL DEFAULT '',
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("name"),
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS customer_group_members (
+    "id" bigserial,
+    "customer_group_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("user_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("customer_group_id") REFERENCES "customer_groups"("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE TABLE IF NOT EXISTS customer_group_prices (
+    "id" bigserial,
+    "customer_group_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "price" numeric(15, 2) NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("customer_group_id", "product_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("customer_group_id") REFERENCES "customer_groups"("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
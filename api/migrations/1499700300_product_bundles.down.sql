@@ -0,0 +1,2 @@
+DROP TABLE product_bundle_components;
+DROP TABLE product_bundles;
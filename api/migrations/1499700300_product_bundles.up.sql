@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS product_bundles (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "discount_type" text NOT NULL DEFAULT '' CONSTRAINT valid_bundle_discount_type CHECK(discount_type IN ('', 'percentage', 'flat_amount')),
+    "discount_amount" numeric(15, 2) NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+
+CREATE TABLE IF NOT EXISTS product_bundle_components (
+    "id" bigserial,
+    "bundle_product_id" bigint NOT NULL,
+    "component_product_id" bigint NOT NULL,
+    "quantity" integer NOT NULL CONSTRAINT component_quantity_must_be_positive CHECK(quantity > 0),
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("bundle_product_id", "component_product_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("bundle_product_id") REFERENCES "products"("id"),
+    FOREIGN KEY ("component_product_id") REFERENCES "products"("id")
+);
@@ -0,0 +1,3 @@
+DROP TABLE download_grants;
+DROP TABLE product_files;
+ALTER TABLE products DROP COLUMN IF EXISTS "digital";
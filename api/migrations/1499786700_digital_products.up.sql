@@ -0,0 +1,33 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "digital" boolean NOT NULL DEFAULT 'false';
+
+CREATE TABLE IF NOT EXISTS product_files (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "blob_key" text NOT NULL,
+    "filename" text NOT NULL,
+    "content_type" text NOT NULL,
+    "size" bigint NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+
+CREATE TABLE IF NOT EXISTS download_grants (
+    "id" bigserial,
+    "token" text NOT NULL,
+    "product_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "expires_on" timestamp NOT NULL,
+    "max_downloads" integer NOT NULL CONSTRAINT max_downloads_must_be_positive CHECK(max_downloads > 0),
+    "download_count" integer NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("token"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
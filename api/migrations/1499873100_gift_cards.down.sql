@@ -0,0 +1,2 @@
+DROP TABLE gift_card_transactions;
+DROP TABLE gift_cards;
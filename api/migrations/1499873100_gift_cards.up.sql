@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS gift_cards (
+    "id" bigserial,
+    "code" text NOT NULL,
+    "product_id" bigint,
+    "initial_balance" numeric(15, 2) NOT NULL CONSTRAINT initial_balance_must_be_positive CHECK(initial_balance > 0),
+    "remaining_balance" numeric(15, 2) NOT NULL CONSTRAINT remaining_balance_must_not_be_negative CHECK(remaining_balance >= 0),
+    "currency" text NOT NULL,
+    "expires_on" timestamp,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    CONSTRAINT balance_cannot_exceed_initial_balance CHECK(remaining_balance <= initial_balance),
+    UNIQUE ("code"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+
+CREATE TABLE IF NOT EXISTS gift_card_transactions (
+    "id" bigserial,
+    "gift_card_id" bigint NOT NULL,
+    "kind" text NOT NULL CONSTRAINT valid_gift_card_transaction_kind CHECK(kind IN ('issue', 'debit', 'credit')),
+    "amount" numeric(15, 2) NOT NULL CONSTRAINT transaction_amount_must_be_positive CHECK(amount > 0),
+    "balance_after" numeric(15, 2) NOT NULL,
+    "user_id" bigint,
+    "reference" text NOT NULL DEFAULT '',
+    "created_on" timestamp DEFAULT NOW(),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("gift_card_id") REFERENCES "gift_cards"("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS gift_card_transactions_gift_card_id_idx ON gift_card_transactions ("gift_card_id", "id");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS product_relations (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "related_product_id" bigint NOT NULL CONSTRAINT products_cannot_relate_to_themselves CHECK(related_product_id != product_id),
+    "kind" text NOT NULL CONSTRAINT valid_product_relation_kind CHECK(kind IN ('related', 'upsell', 'cross-sell', 'accessory')),
+    "position" integer NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id", "related_product_id", "kind"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    FOREIGN KEY ("related_product_id") REFERENCES "products"("id")
+);
@@ -0,0 +1,17 @@
+ALTER TABLE product_options DROP CONSTRAINT product_options_product_id_fkey, ADD CONSTRAINT product_options_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_option_values DROP CONSTRAINT product_option_values_product_option_id_fkey, ADD CONSTRAINT product_option_values_product_option_id_fkey FOREIGN KEY ("product_option_id") REFERENCES "product_options"("id");
+ALTER TABLE product_reviews DROP CONSTRAINT product_reviews_product_id_fkey, ADD CONSTRAINT product_reviews_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_prices DROP CONSTRAINT product_prices_product_id_fkey, ADD CONSTRAINT product_prices_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_price_tiers DROP CONSTRAINT product_price_tiers_product_id_fkey, ADD CONSTRAINT product_price_tiers_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE customer_group_prices DROP CONSTRAINT customer_group_prices_product_id_fkey, ADD CONSTRAINT customer_group_prices_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_bundles DROP CONSTRAINT product_bundles_product_id_fkey, ADD CONSTRAINT product_bundles_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_bundle_components DROP CONSTRAINT product_bundle_components_bundle_product_id_fkey, ADD CONSTRAINT product_bundle_components_bundle_product_id_fkey FOREIGN KEY ("bundle_product_id") REFERENCES "products"("id");
+ALTER TABLE product_bundle_components DROP CONSTRAINT product_bundle_components_component_product_id_fkey, ADD CONSTRAINT product_bundle_components_component_product_id_fkey FOREIGN KEY ("component_product_id") REFERENCES "products"("id");
+ALTER TABLE product_files DROP CONSTRAINT product_files_product_id_fkey, ADD CONSTRAINT product_files_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE download_grants DROP CONSTRAINT download_grants_product_id_fkey, ADD CONSTRAINT download_grants_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE gift_cards DROP CONSTRAINT gift_cards_product_id_fkey, ADD CONSTRAINT gift_cards_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_relations DROP CONSTRAINT product_relations_product_id_fkey, ADD CONSTRAINT product_relations_product_id_fkey FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE product_relations DROP CONSTRAINT product_relations_related_product_id_fkey, ADD CONSTRAINT product_relations_related_product_id_fkey FOREIGN KEY ("related_product_id") REFERENCES "products"("id");
+
+DROP INDEX IF EXISTS products_unarchived_sku_idx;
+ALTER TABLE products ADD CONSTRAINT products_sku_key UNIQUE ("sku");
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_revisions (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "revision" integer NOT NULL CONSTRAINT revision_must_be_positive CHECK(revision > 0),
+    "action" text NOT NULL CONSTRAINT valid_product_revision_action CHECK(action IN ('create', 'update', 'archive', 'restore', 'revert')),
+    "snapshot" jsonb NOT NULL,
+    "user_id" bigint,
+    "created_on" timestamp DEFAULT NOW(),
+    UNIQUE ("product_id", "revision"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE,
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+/* products that predate revisions start out with their current state as their first one */
+INSERT INTO product_revisions (product_id, revision, action, snapshot) SELECT id, 1, 'create', to_jsonb(products) FROM products;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN IF EXISTS "discontinued_on";
+ALTER TABLE products DROP COLUMN IF EXISTS "status";
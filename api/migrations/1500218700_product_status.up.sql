@@ -0,0 +1,3 @@
+/* existing products were all visible before, so they start out published */
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'published' CHECK("status" IN ('draft', 'scheduled', 'published', 'discontinued'));
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "discontinued_on" timestamp;
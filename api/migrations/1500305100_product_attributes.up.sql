@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS product_attribute_definitions (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "type" text NOT NULL CHECK("type" IN ('text', 'number', 'boolean', 'enum')),
+    "required" boolean NOT NULL DEFAULT 'false',
+    "choices" text[] NOT NULL DEFAULT '{}',
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    PRIMARY KEY ("id")
+);
+
+/* names are what attributes are keyed by on products, so only live definitions need unique ones */
+CREATE UNIQUE INDEX IF NOT EXISTS product_attribute_definitions_unarchived_name_idx ON product_attribute_definitions ("name") WHERE archived_on IS NULL;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "attributes" jsonb NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN ("attributes");
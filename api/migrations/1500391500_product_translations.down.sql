@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS product_option_value_translations;
+DROP TABLE IF EXISTS product_option_translations;
+DROP TABLE IF EXISTS product_translations;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS product_translations (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "locale" text NOT NULL,
+    "name" text NOT NULL,
+    "subtitle" text,
+    "description" text,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_id", "locale"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_option_translations (
+    "id" bigserial,
+    "product_option_id" bigint NOT NULL,
+    "locale" text NOT NULL,
+    "name" text NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_option_id", "locale"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_option_id") REFERENCES "product_options"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS product_option_value_translations (
+    "id" bigserial,
+    "product_option_value_id" bigint NOT NULL,
+    "locale" text NOT NULL,
+    "value" text NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("product_option_value_id", "locale"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("product_option_value_id") REFERENCES "product_option_values"("id") ON DELETE CASCADE
+);
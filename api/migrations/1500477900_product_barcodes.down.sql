@@ -0,0 +1,2 @@
+-- there's no telling which codes were UPC-A to begin with, so they all go back to EAN-13, which UPC-A is a subset of
+UPDATE products SET upc = substr(upc, 2) WHERE upc ~ '^0[0-9]{13}$';
@@ -0,0 +1,37 @@
+-- barcodes are stored as GTIN-14, which UPC-A and EAN-13 codes become by padding them with leading zeroes.
+-- Legacy codes that aren't valid barcodes, or that pad into the same GTIN-14 as another product's (like a UPC-A
+-- and its EAN-13 form), can't be normalized, so they're reported and cleared before the rest are padded.
+DO $$
+DECLARE
+    rejected record;
+BEGIN
+    FOR rejected IN
+        SELECT id, upc FROM products
+        WHERE upc IS NOT NULL AND upc <> '' AND NOT (
+            upc ~ '^[0-9]{12,14}$' AND (
+                -- the same check digit validation the API does, where every other digit from the right is tripled
+                SELECT sum(substr(lpad(upc, 14, '0'), i, 1)::integer * CASE WHEN i % 2 = 1 THEN 3 ELSE 1 END)
+                FROM generate_series(1, 14) AS i
+            ) % 10 = 0
+        )
+    LOOP
+        RAISE WARNING 'clearing invalid barcode `%` from product %', rejected.upc, rejected.id;
+        UPDATE products SET upc = NULL WHERE id = rejected.id;
+    END LOOP;
+
+    -- when codes collide, the product that's had its barcode the longest keeps it
+    FOR rejected IN
+        SELECT id, upc FROM (
+            SELECT id, upc, row_number() OVER (PARTITION BY lpad(upc, 14, '0') ORDER BY id) AS n
+            FROM products
+            WHERE upc ~ '^[0-9]{12,14}$'
+        ) AS padded
+        WHERE n > 1
+    LOOP
+        RAISE WARNING 'clearing barcode `%` from product %, since another product already has the same GTIN', rejected.upc, rejected.id;
+        UPDATE products SET upc = NULL WHERE id = rejected.id;
+    END LOOP;
+
+    UPDATE products SET upc = lpad(upc, 14, '0') WHERE upc ~ '^[0-9]{12,13}$';
+END
+$$;
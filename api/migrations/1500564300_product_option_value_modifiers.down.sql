@@ -0,0 +1,3 @@
+ALTER TABLE product_option_values DROP COLUMN IF EXISTS "sku_suffix";
+ALTER TABLE product_option_values DROP COLUMN IF EXISTS "weight_delta";
+ALTER TABLE product_option_values DROP COLUMN IF EXISTS "price_delta";
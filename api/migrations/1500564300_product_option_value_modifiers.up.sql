@@ -0,0 +1,3 @@
+ALTER TABLE product_option_values ADD COLUMN IF NOT EXISTS "price_delta" numeric(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE product_option_values ADD COLUMN IF NOT EXISTS "weight_delta" numeric(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE product_option_values ADD COLUMN IF NOT EXISTS "sku_suffix" text NOT NULL DEFAULT '';
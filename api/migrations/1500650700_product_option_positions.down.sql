@@ -0,0 +1,2 @@
+ALTER TABLE product_option_values DROP COLUMN IF EXISTS "position";
+ALTER TABLE product_options DROP COLUMN IF EXISTS "position";
@@ -0,0 +1,10 @@
+ALTER TABLE product_options ADD COLUMN IF NOT EXISTS "position" integer NOT NULL DEFAULT 0;
+ALTER TABLE product_option_values ADD COLUMN IF NOT EXISTS "position" integer NOT NULL DEFAULT 0;
+
+/* existing options and values stay in the order they were created in */
+UPDATE product_options o SET position = ordered.position
+    FROM (SELECT id, row_number() OVER (PARTITION BY product_id ORDER BY id) - 1 AS position FROM product_options) ordered
+    WHERE o.id = ordered.id;
+UPDATE product_option_values v SET position = ordered.position
+    FROM (SELECT id, row_number() OVER (PARTITION BY product_option_id ORDER BY id) - 1 AS position FROM product_option_values) ordered
+    WHERE v.id = ordered.id;
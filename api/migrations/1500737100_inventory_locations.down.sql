@@ -0,0 +1,2 @@
+DROP TABLE inventory_levels;
+DROP TABLE inventory_locations;
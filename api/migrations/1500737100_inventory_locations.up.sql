@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS inventory_locations (
+    "id" bigserial,
+    "name" text NOT NULL,
+    -- stock at locations that aren't sellable (like a returns area) is tracked, but doesn't count towards a product's quantity
+    "sellable" boolean NOT NULL DEFAULT 'true',
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX inventory_locations_name_idx ON inventory_locations ("name") WHERE archived_on IS NULL;
+
+CREATE TABLE IF NOT EXISTS inventory_levels (
+    "id" bigserial,
+    "location_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    -- the sorted, comma separated IDs of the option values that make up the stocked combination, or empty for the product itself
+    "option_value_ids" text NOT NULL DEFAULT '',
+    "quantity" integer NOT NULL DEFAULT 0,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    UNIQUE ("location_id", "product_id", "option_value_ids"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("location_id") REFERENCES "inventory_locations"("id"),
+    -- purging an archived product takes its stock levels with it
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
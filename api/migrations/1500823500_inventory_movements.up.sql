@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    -- movements of products that aren't stocked at locations don't have one
+    "location_id" bigint,
+    "option_value_ids" text NOT NULL DEFAULT '',
+    "delta" integer NOT NULL CONSTRAINT movement_delta_must_not_be_zero CHECK(delta <> 0),
+    "quantity_after" integer NOT NULL,
+    "reason" text NOT NULL CONSTRAINT valid_inventory_movement_reason CHECK(reason IN ('received', 'sold', 'damaged', 'correction', 'returned')),
+    "reference" text NOT NULL DEFAULT '',
+    "user_id" bigint,
+    "created_on" timestamp DEFAULT NOW(),
+    PRIMARY KEY ("id"),
+    -- a purged product's ledger goes with it, since there's nothing left for it to account for
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE,
+    FOREIGN KEY ("location_id") REFERENCES "inventory_locations"("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_product_id_idx ON inventory_movements ("product_id", "id");
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "quantity" integer NOT NULL CONSTRAINT reservation_quantity_must_be_positive CHECK(quantity > 0),
+    "status" text NOT NULL DEFAULT 'active' CONSTRAINT valid_reservation_status CHECK(status IN ('active', 'confirmed', 'released', 'expired')),
+    "user_id" bigint,
+    "expires_on" timestamp NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    "updated_on" timestamp,
+    PRIMARY KEY ("id"),
+    -- purging an archived product takes its holds with it
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE,
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+-- only active holds are ever summed up or swept, so only they need to be found quickly
+CREATE INDEX IF NOT EXISTS reservations_active_product_id_idx ON reservations ("product_id") WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS reservations_active_expires_on_idx ON reservations ("expires_on") WHERE status = 'active';
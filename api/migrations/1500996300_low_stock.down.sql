@@ -0,0 +1,2 @@
+DROP TABLE low_stock_alerts;
+ALTER TABLE products DROP COLUMN IF EXISTS "reorder_threshold";
@@ -0,0 +1,10 @@
+/* products without a threshold are never reported as running low */
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "reorder_threshold" integer CHECK("reorder_threshold" >= 0);
+
+/* a product has a row here from when it falls to its threshold until it's restocked above it, so purchasing only hears about it once */
+CREATE TABLE IF NOT EXISTS low_stock_alerts (
+    "product_id" bigint NOT NULL,
+    "created_on" timestamp DEFAULT NOW(),
+    PRIMARY KEY ("product_id"),
+    FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN IF EXISTS "restock_on";
+ALTER TABLE products DROP COLUMN IF EXISTS "max_oversell";
+ALTER TABLE products DROP COLUMN IF EXISTS "stock_policy";
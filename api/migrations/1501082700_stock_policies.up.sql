@@ -0,0 +1,6 @@
+/* products have always been unorderable once they run out, so that stays the default */
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "stock_policy" text NOT NULL DEFAULT 'deny' CHECK("stock_policy" IN ('deny', 'backorder', 'preorder'));
+/* how far below zero a backordered or pre-ordered product's quantity may go, where NULL means there's no limit */
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "max_oversell" integer CHECK("max_oversell" >= 0);
+/* when more stock is expected in, which is only ever informational */
+ALTER TABLE products ADD COLUMN IF NOT EXISTS "restock_on" timestamp;
@@ -21,7 +21,8 @@ INSERT INTO products
     "package_height",
     "package_width",
     "package_length",
-    "quantity_per_package"
+    "quantity_per_package",
+    "digital"
 )
 VALUES
 (
@@ -46,14 +47,15 @@ VALUES
     /* package_height       */ 5,
     /* package_width        */ 5,
     /* package_length       */ 5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'false'
 ),
 (
     /* name                 */ 'Sleeping People - Sleeping People',
     /* subtitle             */ 'A solid math rock album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'sleeping-people',
-    /* upc                  */ '656605908410',
+    /* upc                  */ '00656605908410',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Sleeping People',
     /* quantity             */ 123,
@@ -70,14 +72,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Jaga Jazzist - One Armed Bandit',
     /* subtitle             */ 'A solid jazz album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'one-armed-bandit',
-    /* upc                  */ '5021392578187',
+    /* upc                  */ '05021392578187',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Jaga Jazzist',
     /* quantity             */ 123,
@@ -94,7 +97,8 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Cloudkicker - Let Yourself Be Huge',
@@ -118,14 +122,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Animals As Leaders - The Joy Of Motion',
     /* subtitle             */ 'A solid prog metal album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'the-joy-of-motion',
-    /* upc                  */ '817424013895',
+    /* upc                  */ '00817424013895',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Animals As Leaders',
     /* quantity             */ 123,
@@ -142,14 +147,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Mort Garson - Mother Earth''s Plantasia',
     /* subtitle             */ 'A solid synth album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'mother-earths-plantasia',
-    /* upc                  */ '5291103812552',
+    /* upc                  */ '05291103812552',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Mort Garson',
     /* quantity             */ 123,
@@ -166,14 +172,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Camel - The Snow Goose',
     /* subtitle             */ 'A solid prog rock album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'the-snow-goose',
-    /* upc                  */ '600753356661',
+    /* upc                  */ '00600753356661',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Camel',
     /* quantity             */ 123,
@@ -190,7 +197,8 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Piglet - Lava Land',
@@ -214,14 +222,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Tera Melos - Untitled',
     /* subtitle             */ 'Yet another solid math rock album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'untitled',
-    /* upc                  */ '634457550513',
+    /* upc                  */ '00634457550513',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Tera Melos',
     /* quantity             */ 123,
@@ -238,14 +247,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'Frank Zappa - Jazz From Hell',
     /* subtitle             */ 'A solid Zappa album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'jazz-from-hell',
-    /* upc                  */ '013347420516',
+    /* upc                  */ '00013347420516',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'Frank Zappa',
     /* quantity             */ 123,
@@ -262,14 +272,15 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 ),
 (
     /* name                 */ 'CHON - Newborn Sun',
     /* subtitle             */ 'Yet another solid math rock album',
     /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
     /* sku                  */ 'newborn-sun',
-    /* upc                  */ '794558090315',
+    /* upc                  */ '00794558090315',
     /* manufacturer         */ 'Record Company',
     /* brand                */ 'CHON',
     /* quantity             */ 123,
@@ -286,7 +297,8 @@ VALUES
     /* package_height       */ 12,
     /* package_width        */ 12,
     /* package_length       */ .5,
-    /* quantity_per_package */ 1
+    /* quantity_per_package */ 1,
+    /* digital              */ 'true'
 );
 
 INSERT INTO product_options
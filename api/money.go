@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of currency, stored as a whole number of minor units (cents) so that
+// the two decimal places our numeric(15, 2) columns hold never pass through a float.
+type Money int64
+
+// RoundingMode determines what happens to fractions of a cent when Money is multiplied or divided
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest cent, with halves rounded away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest cent, with halves rounded to the nearest even cent (banker's rounding)
+	RoundHalfEven
+	// RoundDown truncates fractions of a cent, rounding towards zero
+	RoundDown
+	// RoundUp rounds any fraction of a cent away from zero
+	RoundUp
+
+	centsPerUnit = 100
+)
+
+// ParseMoney parses a decimal string like "12.34" into Money. More than two decimal
+// places is an error rather than something we silently round away.
+func ParseMoney(s string) (Money, error) {
+	in := strings.TrimSpace(s)
+	// only one sign is allowed, so anything after it has to be digits
+	negative := strings.HasPrefix(in, "-")
+	if negative || strings.HasPrefix(in, "+") {
+		in = in[1:]
+	}
+
+	parts := strings.SplitN(in, ".", 2)
+	whole, fraction := parts[0], ""
+	if len(parts) == 2 {
+		fraction = parts[1]
+	}
+
+	if (whole == "" && fraction == "") || len(fraction) > 2 || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("`%s` is not a valid amount of money", s)
+	}
+
+	cents, err := strconv.ParseInt(whole+(fraction + "00")[:2], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("`%s` is not a valid amount of money", s)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders Money with exactly two decimal places
+func (m Money) String() string {
+	sign := ""
+	cents := uint64(m)
+	if m < 0 {
+		sign = "-"
+		cents = uint64(-m)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerUnit, cents%centsPerUnit)
+}
+
+// MarshalJSON renders Money as a JSON number with exactly two decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a string containing one
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if bytes.HasPrefix(b, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return fmt.Errorf("`%s` is not a valid amount of money", b)
+		}
+		return m.UnmarshalText([]byte(s))
+	}
+	return m.UnmarshalText(b)
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value satisfies the driver.Valuer interface. Postgres is happy to take a numeric as text.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan satisfies the sql.Scanner interface
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		return m.UnmarshalText(v)
+	case string:
+		return m.UnmarshalText([]byte(v))
+	case int64:
+		*m = Money(v * centsPerUnit)
+	case float64:
+		*m = Money(math.Round(v * centsPerUnit))
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+// Add returns the sum of two amounts
+func (m Money) Add(o Money) Money {
+	return m + o
+}
+
+// Sub returns the difference of two amounts
+func (m Money) Sub(o Money) Money {
+	return m - o
+}
+
+// Times returns the amount multiplied by a whole quantity, which never needs rounding
+func (m Money) Times(quantity int64) Money {
+	return m * Money(quantity)
+}
+
+// Mul returns the amount multiplied by an arbitrary ratio, rounded to the cent with the given mode
+func (m Money) Mul(r *big.Rat, mode RoundingMode) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), r)
+	return Money(roundRat(product, mode))
+}
+
+// Div returns the amount divided by n, rounded to the cent with the given mode
+func (m Money) Div(n int64, mode RoundingMode) Money {
+	return m.Mul(big.NewRat(1, n), mode)
+}
+
+// Percent returns the given percentage of the amount. Percentages are themselves two decimal
+// values (discount amounts are stored the same way prices are), so 12.50% is Money(1250).
+func (m Money) Percent(pct Money, mode RoundingMode) Money {
+	return m.Mul(big.NewRat(int64(pct), 100*centsPerUnit), mode)
+}
+
+// RoundToIncrement rounds the amount to a multiple of increment, for currencies whose smallest
+// unit (or smallest coin in circulation) is bigger than a cent.
+func (m Money) RoundToIncrement(increment Money, mode RoundingMode) Money {
+	if increment <= 1 {
+		return m
+	}
+	return m.Mul(big.NewRat(1, int64(increment)), mode) * increment
+}
+
+// roundRat rounds a rational number to an integer with the given mode
+func roundRat(r *big.Rat, mode RoundingMode) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo.Int64()
+	}
+
+	// QuoRem truncates towards zero, so any adjustment is one step further from zero
+	away := big.NewInt(int64(r.Sign()))
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+	cmp := twiceRem.Cmp(r.Denom())
+
+	switch mode {
+	case RoundUp:
+		quo.Add(quo, away)
+	case RoundHalfUp:
+		if cmp >= 0 {
+			quo.Add(quo, away)
+		}
+	case RoundHalfEven:
+		if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+			quo.Add(quo, away)
+		}
+	}
+	return quo.Int64()
+}
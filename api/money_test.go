@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+	inputOutputMap := map[string]Money{
+		"12.34": 1234,
+		"12.3":  1230,
+		"12":    1200,
+		".5":    50,
+		"-0.01": -1,
+		"+3":    300,
+		" 7.00": 700,
+	}
+
+	for input, expected := range inputOutputMap {
+		actual, err := ParseMoney(input)
+		assert.Nil(t, err, "parsing `%s` shouldn't fail", input)
+		assert.Equal(t, expected, actual, "parsing `%s` should produce %d cents", input, expected)
+	}
+}
+
+func TestParseMoneyWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"", ".", "-", "12.345", "1,000.00", "twelve", "1e3", "99999999999999999999", "-+5", "+-5", "--5"} {
+		_, err := ParseMoney(input)
+		assert.NotNil(t, err, "parsing `%s` should fail", input)
+	}
+}
+
+func TestMoneyUnmarshalJSONWithMismatchedQuotes(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{`"5`, `5"`, `"`, `""5""`} {
+		var m Money
+		assert.NotNil(t, m.UnmarshalJSON([]byte(input)), "decoding %s should fail", input)
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "12.34", Money(1234).String())
+	assert.Equal(t, "0.05", Money(5).String())
+	assert.Equal(t, "-1.50", Money(-150).String())
+	assert.Equal(t, "0.00", Money(0).String())
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	example := struct {
+		Price Money `json:"price"`
+	}{}
+
+	err := json.Unmarshal([]byte(`{"price": 12.34}`), &example)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(1234), example.Price, "12.34 should be decoded exactly")
+
+	err = json.Unmarshal([]byte(`{"price": "20"}`), &example)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(2000), example.Price, "money should be decodable from a string")
+
+	b, err := json.Marshal(example)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"price":20.00}`, string(b), "money should always be encoded with two decimal places")
+
+	err = json.Unmarshal([]byte(`{"price": 12.345}`), &example)
+	assert.NotNil(t, err, "fractions of a cent shouldn't be silently rounded away")
+}
+
+func TestMoneyScan(t *testing.T) {
+	t.Parallel()
+	inputOutputMap := map[interface{}]Money{
+		"12.34":            1234,
+		int64(3):           300,
+		float64(0.1 + 0.2): 30,
+	}
+
+	for input, expected := range inputOutputMap {
+		var m Money
+		err := m.Scan(input)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, m, "scanning %v should produce %d cents", input, expected)
+	}
+
+	var m Money
+	assert.Nil(t, m.Scan([]byte("5.55")))
+	assert.Equal(t, Money(555), m)
+	assert.NotNil(t, m.Scan(true), "scanning a bool into money should fail")
+}
+
+func TestMoneyValue(t *testing.T) {
+	t.Parallel()
+	actual, err := Money(1234).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "12.34", actual)
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Money(1500), Money(1234).Add(266))
+	assert.Equal(t, Money(968), Money(1234).Sub(266))
+	assert.Equal(t, Money(3702), Money(1234).Times(3))
+}
+
+func TestMoneyRounding(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		amount   Money
+		divisor  int64
+		mode     RoundingMode
+		expected Money
+	}{
+		// 0.25 / 2 = 0.125
+		{25, 2, RoundHalfUp, 13},
+		{25, 2, RoundHalfEven, 12},
+		{25, 2, RoundDown, 12},
+		{25, 2, RoundUp, 13},
+		// 0.35 / 2 = 0.175
+		{35, 2, RoundHalfEven, 18},
+		// 1.00 / 3 = 0.333...
+		{100, 3, RoundHalfUp, 33},
+		{100, 3, RoundUp, 34},
+		// -0.25 / 2 = -0.125, rounding is symmetric around zero
+		{-25, 2, RoundHalfUp, -13},
+		{-25, 2, RoundDown, -12},
+		{-25, 2, RoundUp, -13},
+		// no rounding needed
+		{100, 4, RoundUp, 25},
+	}
+
+	for _, tc := range testCases {
+		actual := tc.amount.Div(tc.divisor, tc.mode)
+		assert.Equal(t, tc.expected, actual, "%s / %d with rounding mode %d should be %s", tc.amount, tc.divisor, tc.mode, tc.expected)
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Money(1851), Money(1234).Mul(big.NewRat(3, 2), RoundHalfUp))
+}
+
+func TestMoneyPercent(t *testing.T) {
+	t.Parallel()
+	// 12.50% of 19.99 is 2.49875
+	assert.Equal(t, Money(250), Money(1999).Percent(1250, RoundHalfUp))
+	assert.Equal(t, Money(249), Money(1999).Percent(1250, RoundDown))
+}
+
+func TestMoneyRoundToIncrement(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Money(1235), Money(1233).RoundToIncrement(5, RoundHalfUp))
+	assert.Equal(t, Money(1200), Money(1249).RoundToIncrement(100, RoundHalfUp))
+	assert.Equal(t, Money(1300), Money(1250).RoundToIncrement(100, RoundHalfUp))
+	assert.Equal(t, Money(1249), Money(1249).RoundToIncrement(1, RoundHalfUp), "rounding to the cent should be a no-op")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// a sku can be archived more than once, in which case the most recently archived product is the one that comes back
+	archivedProductRetrievalQuery = `SELECT * FROM products WHERE sku = $1 AND archived_on IS NOT NULL ORDER BY archived_on DESC LIMIT 1`
+
+	// only the options and values that were archived along with the product come back with it. These have to run
+	// before the product is restored, since they compare against the product's archived_on.
+	productOptionValuesRestorationQuery = `UPDATE product_option_values SET archived_on = NULL, updated_on = NOW() WHERE product_option_id IN (SELECT id FROM product_options WHERE product_id = $1) AND archived_on = (SELECT archived_on FROM products WHERE id = $1)`
+	productOptionsRestorationQuery      = `UPDATE product_options SET archived_on = NULL, updated_on = NOW() WHERE product_id = $1 AND archived_on = (SELECT archived_on FROM products WHERE id = $1)`
+	productRestorationQuery             = `UPDATE products SET archived_on = NULL, updated_on = NOW() WHERE id = $1 AND archived_on IS NOT NULL`
+
+	// archived products that are still components of a live bundle stick around, since the bundle still refers to them
+	purgeableProductsQuery = `SELECT id, sku FROM products WHERE archived_on < $1 AND NOT EXISTS(SELECT 1 FROM product_bundle_components c JOIN products b ON b.id = c.bundle_product_id WHERE c.component_product_id = products.id AND c.archived_on IS NULL AND b.archived_on IS NULL) FOR UPDATE`
+
+	archivedProductOptionValuesPurgeQuery = `DELETE FROM product_option_values WHERE archived_on < $1`
+	archivedProductOptionsPurgeQuery      = `DELETE FROM product_options WHERE archived_on < $1`
+)
+
+// ArchivePurgeInput is a struct to use for permanently deleting rows that were archived a while ago
+type ArchivePurgeInput struct {
+	OlderThanDays uint32 `json:"older_than_days" validate:"required"`
+}
+
+// ArchivePurgeResult describes what was permanently deleted by a purge
+type ArchivePurgeResult struct {
+	Products            []string `json:"products"`
+	ProductOptions      int64    `json:"product_options"`
+	ProductOptionValues int64    `json:"product_option_values"`
+}
+
+// retrieveArchivedProductFromDB retrieves the most recently archived product with a given SKU from the database
+func retrieveArchivedProductFromDB(db *sqlx.DB, sku string) (Product, error) {
+	var p Product
+	err := db.Get(&p, archivedProductRetrievalQuery, sku)
+	return p, err
+}
+
+// restoreProductInDB un-archives a product along with the options and values that were archived with it
+func restoreProductInDB(db *sqlx.DB, productID uint64, userID uint64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{productOptionValuesRestorationQuery, productOptionsRestorationQuery, productRestorationQuery} {
+		if _, err = tx.Exec(query, productID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = recordProductRevision(tx, productID, "restore", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func notifyOfSKUConflict(res http.ResponseWriter, sku string) {
+	res.WriteHeader(http.StatusConflict)
+	errRes := &ErrorResponse{
+		Status:  http.StatusConflict,
+		Message: fmt.Sprintf("product with sku `%s` already exists", sku),
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
+
+func buildProductRestorationHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductRestorationHandler is a request handler that un-archives a product that was deleted
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		archivedProduct, err := retrieveArchivedProductFromDB(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "archived product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve archived product from the database")
+			return
+		}
+
+		// the sku may have been given to a new product since this one was deleted
+		exists, err := rowExistsInDB(db, skuExistenceQuery, sku)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "check for products with the same sku")
+			return
+		}
+		if exists {
+			notifyOfSKUConflict(res, sku)
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		err = restoreProductInDB(db, archivedProduct.ID, userID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "restore product in database")
+			return
+		}
+
+		product, err := retrieveProductFromDB(db, sku)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve restored product from the database")
+			return
+		}
+		product.Availability = product.availability(time.Now())
+		json.NewEncoder(res).Encode(product)
+	}
+}
+
+// purgeArchivedRowsFromDB permanently deletes products, options, and option values that were archived before a given
+// time, and returns the keys of any files that belonged to the deleted products so they can be removed too
+func purgeArchivedRowsFromDB(db *sqlx.DB, archivedBefore time.Time) (*ArchivePurgeResult, []string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, blobKeys, err := purgeArchivedRows(tx, archivedBefore)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	return result, blobKeys, tx.Commit()
+}
+
+func purgeArchivedRows(tx *sql.Tx, archivedBefore time.Time) (*ArchivePurgeResult, []string, error) {
+	result := &ArchivePurgeResult{Products: []string{}}
+
+	rows, err := tx.Query(purgeableProductsQuery, archivedBefore)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error encountered querying for purgeable products")
+	}
+	var productIDs []uint64
+	for rows.Next() {
+		var id uint64
+		var sku string
+		if err = rows.Scan(&id, &sku); err != nil {
+			rows.Close()
+			return nil, nil, errors.Wrap(err, "Error scanning purgeable product")
+		}
+		productIDs = append(productIDs, id)
+		result.Products = append(result.Products, sku)
+	}
+	rows.Close()
+
+	var blobKeys []string
+	if len(productIDs) > 0 {
+		query, args := buildProductFileKeyListQuery(productIDs)
+		rows, err = tx.Query(query, args...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error encountered querying for product files")
+		}
+		for rows.Next() {
+			var key string
+			if err = rows.Scan(&key); err != nil {
+				rows.Close()
+				return nil, nil, errors.Wrap(err, "Error scanning product file")
+			}
+			blobKeys = append(blobKeys, key)
+		}
+		rows.Close()
+
+		// everything else that belongs to these products goes with them
+		query, args = buildProductPurgeQuery(productIDs)
+		if _, err = tx.Exec(query, args...); err != nil {
+			return nil, nil, errors.Wrap(err, "Error purging products")
+		}
+	}
+
+	valuesResult, err := tx.Exec(archivedProductOptionValuesPurgeQuery, archivedBefore)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error purging product option values")
+	}
+	result.ProductOptionValues, _ = valuesResult.RowsAffected()
+
+	optionsResult, err := tx.Exec(archivedProductOptionsPurgeQuery, archivedBefore)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error purging product options")
+	}
+	result.ProductOptions, _ = optionsResult.RowsAffected()
+
+	return result, blobKeys, nil
+}
+
+func buildArchivePurgeHandler(db *sqlx.DB, blobs BlobStore) http.HandlerFunc {
+	// ArchivePurgeHandler is a request handler that permanently deletes products, options, and option values
+	// that were archived more than a given number of days ago
+	return func(res http.ResponseWriter, req *http.Request) {
+		purgeInput := &ArchivePurgeInput{}
+		err := validateRequestInput(req, purgeInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		archivedBefore := time.Now().AddDate(0, 0, -int(purgeInput.OlderThanDays))
+
+		result, blobKeys, err := purgeArchivedRowsFromDB(db, archivedBefore)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "purge archived rows from the database")
+			return
+		}
+
+		// the rows are already gone at this point, so a file that can't be removed is only worth a mention
+		for _, key := range blobKeys {
+			if err = blobs.Delete(key); err != nil {
+				log.Printf("error encountered removing file %s for purged product: %v", key, err)
+			}
+		}
+
+		json.NewEncoder(res).Encode(result)
+	}
+}
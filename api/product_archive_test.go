@@ -0,0 +1,275 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func setExpectationsForArchivedProductRetrieval(mock sqlmock.Sqlmock, sku string, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	mock.ExpectQuery(formatQueryForSQLMock(archivedProductRetrievalQuery)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductRestoration(mock sqlmock.Sqlmock, productID uint64, err error) {
+	mock.ExpectBegin()
+	mock.ExpectExec(formatQueryForSQLMock(productOptionValuesRestorationQuery)).
+		WithArgs(productID).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(formatQueryForSQLMock(productOptionsRestorationQuery)).
+		WithArgs(productID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if err != nil {
+		mock.ExpectExec(formatQueryForSQLMock(productRestorationQuery)).
+			WithArgs(productID).
+			WillReturnError(err)
+		mock.ExpectRollback()
+		return
+	}
+	mock.ExpectExec(formatQueryForSQLMock(productRestorationQuery)).
+		WithArgs(productID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	setExpectationsForProductRevisionCreation(mock, productID, "restore", 1, nil)
+	mock.ExpectCommit()
+}
+
+func setExpectationsForArchivePurge(mock sqlmock.Sqlmock, archivedBefore driver.Value, productIDs []uint64, blobKeys []string) {
+	mock.ExpectBegin()
+	productRows := sqlmock.NewRows([]string{"id", "sku"})
+	for _, id := range productIDs {
+		productRows = productRows.AddRow(id, fmt.Sprintf("archived-%d", id))
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(purgeableProductsQuery)).
+		WithArgs(archivedBefore).
+		WillReturnRows(productRows)
+
+	if len(productIDs) > 0 {
+		keyRows := sqlmock.NewRows([]string{"blob_key"})
+		for _, key := range blobKeys {
+			keyRows = keyRows.AddRow(key)
+		}
+		query, args := buildProductFileKeyListQuery(productIDs)
+		mock.ExpectQuery(formatQueryForSQLMock(query)).
+			WithArgs(argsToDriverValues(args)...).
+			WillReturnRows(keyRows)
+		query, args = buildProductPurgeQuery(productIDs)
+		mock.ExpectExec(formatQueryForSQLMock(query)).
+			WithArgs(argsToDriverValues(args)...).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(productIDs))))
+	}
+
+	mock.ExpectExec(formatQueryForSQLMock(archivedProductOptionValuesPurgeQuery)).
+		WithArgs(archivedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(formatQueryForSQLMock(archivedProductOptionsPurgeQuery)).
+		WithArgs(archivedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+}
+
+func TestRetrieveArchivedProductFromDB(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForArchivedProductRetrieval(testUtil.Mock, exampleSKU, nil)
+
+	actual, err := retrieveArchivedProductFromDB(testUtil.DB, exampleSKU)
+	assert.Nil(t, err)
+	assert.Equal(t, *exampleProduct, actual, "expected and actual products should match")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestRestoreProductInDB(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRestoration(testUtil.Mock, exampleProduct.ID, nil)
+
+	err := restoreProductInDB(testUtil.DB, exampleProduct.ID, 1)
+	assert.Nil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRestorationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForArchivedProductRetrieval(testUtil.Mock, exampleSKU, nil)
+	setExpectationsForProductExistence(testUtil.Mock, exampleSKU, false, nil)
+	setExpectationsForProductRestoration(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleSKU, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/restore", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.SKU, actual.SKU)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRestorationHandlerForProductThatWasNeverArchived(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForArchivedProductRetrieval(testUtil.Mock, exampleSKU, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/restore", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRestorationHandlerWhenSKUHasBeenTaken(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForArchivedProductRetrieval(testUtil.Mock, exampleSKU, nil)
+	setExpectationsForProductExistence(testUtil.Mock, exampleSKU, true, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/restore", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusConflict, testUtil.Response.Code, "status code should be 409")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRestorationHandlerWithErrorRestoringProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForArchivedProductRetrieval(testUtil.Mock, exampleSKU, nil)
+	setExpectationsForProductExistence(testUtil.Mock, exampleSKU, false, nil)
+	setExpectationsForProductRestoration(testUtil.Mock, exampleProduct.ID, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/restore", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRestorationHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/restore", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestPurgeArchivedRowsFromDB(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	archivedBefore := generateExampleTimeForTests()
+	setExpectationsForArchivePurge(testUtil.Mock, archivedBefore, []uint64{2, 3}, []string{"abc123"})
+
+	result, blobKeys, err := purgeArchivedRowsFromDB(testUtil.DB, archivedBefore)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"archived-2", "archived-3"}, result.Products)
+	assert.Equal(t, int64(2), result.ProductOptions)
+	assert.Equal(t, int64(4), result.ProductOptionValues)
+	assert.Equal(t, []string{"abc123"}, blobKeys)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestPurgeArchivedRowsFromDBWithoutPurgeableProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	archivedBefore := generateExampleTimeForTests()
+	setExpectationsForArchivePurge(testUtil.Mock, archivedBefore, nil, nil)
+
+	result, blobKeys, err := purgeArchivedRowsFromDB(testUtil.DB, archivedBefore)
+	assert.Nil(t, err)
+	assert.Empty(t, result.Products)
+	assert.Empty(t, blobKeys)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestPurgeArchivedRowsFromDBWithErrorPurgingOptions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	archivedBefore := generateExampleTimeForTests()
+
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(purgeableProductsQuery)).
+		WithArgs(archivedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sku"}))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(archivedProductOptionValuesPurgeQuery)).
+		WithArgs(archivedBefore).
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	_, _, err := purgeArchivedRowsFromDB(testUtil.DB, archivedBefore)
+	assert.NotNil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestArchivePurgeHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	_, err := testUtil.Blobs.Put("abc123", strings.NewReader("album"))
+	assert.Nil(t, err)
+	setExpectationsForArchivePurge(testUtil.Mock, sqlmock.AnyArg(), []uint64{2}, []string{"abc123"})
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/products/purge", strings.NewReader(`{"older_than_days": 30}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ArchivePurgeResult{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"archived-2"}, actual.Products)
+
+	_, err = testUtil.Blobs.Get("abc123")
+	assert.NotNil(t, err, "files belonging to purged products should be removed")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestArchivePurgeHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	inputs := []string{
+		exampleGarbageInput,
+		`{"older_than_days": 0}`,
+	}
+
+	for _, input := range inputs {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPost, "/v1/products/purge", strings.NewReader(input))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for %s", input)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestArchivePurgeHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/products/purge", strings.NewReader(`{"older_than_days": 30}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
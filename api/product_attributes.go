@@ -0,0 +1,489 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const (
+	productAttributeDefinitionsTableHeaders = `id,
+		name,
+		type,
+		required,
+		choices,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	productAttributeDefinitionRetrievalQuery = `SELECT ` + productAttributeDefinitionsTableHeaders + ` FROM product_attribute_definitions WHERE id = $1 AND archived_on IS NULL`
+	productAttributeNameExistenceQuery       = `SELECT EXISTS(SELECT 1 FROM product_attribute_definitions WHERE name = $1 AND archived_on IS NULL)`
+	productAttributeDefinitionDeletionQuery  = `UPDATE product_attribute_definitions SET archived_on = NOW() WHERE id = $1 AND archived_on IS NULL RETURNING name`
+
+	// products shouldn't hang on to values for an attribute that doesn't exist anymore, since they'd fail validation the next time they're updated
+	productAttributeRemovalQuery = `UPDATE products SET attributes = attributes - $1::text, updated_on = NOW() WHERE attributes->$1::text IS NOT NULL`
+
+	// attributeFilterParamPrefix is what product list params that filter on an attribute start with, as in `attributes.material=oak`
+	attributeFilterParamPrefix = "attributes."
+)
+
+const (
+	productAttributeTypeText    = "text"
+	productAttributeTypeNumber  = "number"
+	productAttributeTypeBoolean = "boolean"
+	productAttributeTypeEnum    = "enum"
+)
+
+// ProductAttributes are the values of a product's custom attributes, keyed by attribute name
+type ProductAttributes map[string]interface{}
+
+// Value satisfies the driver.Valuer interface
+func (a ProductAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(a)
+	// lib/pq sends byte slices as bytea, which jsonb columns won't accept
+	return string(b), err
+}
+
+// Scan satisfies the sql.Scanner interface
+func (a *ProductAttributes) Scan(src interface{}) error {
+	*a = ProductAttributes{}
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	}
+	return fmt.Errorf("cannot scan %T into ProductAttributes", src)
+}
+
+// withoutNulls returns the attributes that have a value, since setting an attribute to null is how it gets removed
+func (a ProductAttributes) withoutNulls() ProductAttributes {
+	if a == nil {
+		return nil
+	}
+	out := ProductAttributes{}
+	for name, value := range a {
+		if value != nil {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// ProductAttributeDefinition describes a custom attribute that products can have, like a book's ISBN or a shirt's material
+type ProductAttributeDefinition struct {
+	DBRow
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	Required bool           `json:"required"`
+	Choices  pq.StringArray `json:"choices"`
+}
+
+func (d *ProductAttributeDefinition) generateScanArgs() []interface{} {
+	return []interface{}{
+		&d.ID,
+		&d.Name,
+		&d.Type,
+		&d.Required,
+		&d.Choices,
+		&d.CreatedOn,
+		&d.UpdatedOn,
+		&d.ArchivedOn,
+	}
+}
+
+// ProductAttributeDefinitionsResponse is a product attribute definition response struct
+type ProductAttributeDefinitionsResponse struct {
+	ListResponse
+	Data []ProductAttributeDefinition `json:"data"`
+}
+
+// ProductAttributeDefinitionCreationInput is a struct to use for creating product attribute definitions
+type ProductAttributeDefinitionCreationInput struct {
+	Name     string   `json:"name" validate:"required"`
+	Type     string   `json:"type" validate:"required"`
+	Required bool     `json:"required"`
+	Choices  []string `json:"choices"`
+}
+
+// ProductAttributeDefinitionUpdateInput is a struct to use for updating product attribute definitions. An attribute's name
+// and type can't be changed, since products already have values stored under that name and of that type.
+type ProductAttributeDefinitionUpdateInput struct {
+	Required *bool    `json:"required"`
+	Choices  []string `json:"choices"`
+}
+
+func productAttributeTypeIsValid(attributeType string) bool {
+	switch attributeType {
+	case productAttributeTypeText, productAttributeTypeNumber, productAttributeTypeBoolean, productAttributeTypeEnum:
+		return true
+	}
+	return false
+}
+
+// validateProductAttributeChoices makes sure enums have choices to pick from, and that nothing else does
+func validateProductAttributeChoices(attributeType string, choices []string) error {
+	if attributeType == productAttributeTypeEnum {
+		if len(choices) == 0 {
+			return errors.New("enum attributes must have at least one choice")
+		}
+		return nil
+	}
+	if len(choices) > 0 {
+		return fmt.Errorf("only enum attributes can have choices, not %s attributes", attributeType)
+	}
+	return nil
+}
+
+// accepts returns whether a value is the right type for the attribute. Values come from decoded JSON,
+// so numbers are always float64s.
+func (d *ProductAttributeDefinition) accepts(value interface{}) bool {
+	switch d.Type {
+	case productAttributeTypeText:
+		_, ok := value.(string)
+		return ok
+	case productAttributeTypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case productAttributeTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case productAttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		for _, choice := range d.Choices {
+			if s == choice {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateProductAttributes checks a product's attributes against the attribute definitions
+func validateProductAttributes(definitions []ProductAttributeDefinition, attributes ProductAttributes) error {
+	definitionsByName := map[string]*ProductAttributeDefinition{}
+	for i := range definitions {
+		definitionsByName[definitions[i].Name] = &definitions[i]
+	}
+
+	names := []string{}
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d, ok := definitionsByName[name]
+		if !ok {
+			return fmt.Errorf("attribute `%s` does not exist", name)
+		}
+		if !d.accepts(attributes[name]) {
+			if d.Type == productAttributeTypeEnum {
+				return fmt.Errorf("attribute `%s` must be one of: %s", name, strings.Join(d.Choices, ", "))
+			}
+			return fmt.Errorf("attribute `%s` must be a %s", name, d.Type)
+		}
+	}
+
+	for _, d := range definitions {
+		if _, ok := attributes[d.Name]; d.Required && !ok {
+			return fmt.Errorf("attribute `%s` is required", d.Name)
+		}
+	}
+	return nil
+}
+
+// retrieveProductAttributeDefinitions retrieves the attribute definitions with the given names, or all of them if names is nil
+func retrieveProductAttributeDefinitions(db *sqlx.DB, names []string) ([]ProductAttributeDefinition, error) {
+	definitions := []ProductAttributeDefinition{}
+	query, args := buildProductAttributeDefinitionsQuery(names)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product attribute definitions")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d ProductAttributeDefinition
+		if err = rows.Scan(d.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product attribute definition")
+		}
+		definitions = append(definitions, d)
+	}
+	return definitions, rows.Err()
+}
+
+// parseAttributeFilterParams turns the `attributes.<name>` params on a product list request into the attribute values
+// products must have, converting each value to the attribute's type so it can be compared against what's stored
+func parseAttributeFilterParams(db *sqlx.DB, rawFilterParams url.Values) (ProductAttributes, error) {
+	filterValues := map[string]string{}
+	names := []string{}
+	for param, values := range rawFilterParams {
+		if !strings.HasPrefix(param, attributeFilterParamPrefix) || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(param, attributeFilterParamPrefix)
+		filterValues[name] = values[0]
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	definitions, err := retrieveProductAttributeDefinitions(db, names)
+	if err != nil {
+		return nil, err
+	}
+	definitionsByName := map[string]ProductAttributeDefinition{}
+	for _, d := range definitions {
+		definitionsByName[d.Name] = d
+	}
+
+	filter := ProductAttributes{}
+	for _, name := range names {
+		d, ok := definitionsByName[name]
+		if !ok {
+			return nil, &attributeFilterError{fmt.Errorf("attribute `%s` does not exist", name)}
+		}
+		raw := filterValues[name]
+		switch d.Type {
+		case productAttributeTypeNumber:
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, &attributeFilterError{fmt.Errorf("attribute `%s` must be filtered by a number", name)}
+			}
+			filter[name] = n
+		case productAttributeTypeBoolean:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, &attributeFilterError{fmt.Errorf("attribute `%s` must be filtered by true or false", name)}
+			}
+			filter[name] = b
+		default:
+			filter[name] = raw
+		}
+	}
+	return filter, nil
+}
+
+// attributeFilterError is returned for attribute filters that the client got wrong, as opposed to ones we couldn't look up
+type attributeFilterError struct {
+	error
+}
+
+func buildProductAttributeDefinitionListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductAttributeDefinitionListHandler is a request handler that returns a list of product attribute definitions
+	return func(res http.ResponseWriter, req *http.Request) {
+		rawFilterParams := req.URL.Query()
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		var count uint64
+		if !queryFilter.SkipCount {
+			count, err = getRowCount(db, "product_attribute_definitions", queryFilter)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve count of product attribute definitions from the database")
+				return
+			}
+		}
+
+		var definitions []ProductAttributeDefinition
+		query, args := buildProductAttributeDefinitionListQuery(queryFilter)
+		err = retrieveListOfRowsFromDB(db, query, args, &definitions)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attribute definitions from the database")
+			return
+		}
+
+		definitionsResponse := &ProductAttributeDefinitionsResponse{
+			ListResponse: newListResponse(queryFilter, count),
+			Data:         definitions,
+		}
+		if len(definitions) > 0 {
+			definitionsResponse.NextCursor = buildNextCursor(queryFilter, len(definitions), definitions[len(definitions)-1].DBRow)
+		}
+		json.NewEncoder(res).Encode(definitionsResponse)
+	}
+}
+
+func retrieveProductAttributeDefinitionFromDB(db *sqlx.DB, definitionID string) (ProductAttributeDefinition, error) {
+	var d ProductAttributeDefinition
+	err := db.QueryRow(productAttributeDefinitionRetrievalQuery, definitionID).Scan(d.generateScanArgs()...)
+	return d, err
+}
+
+func buildProductAttributeDefinitionRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductAttributeDefinitionRetrievalHandler is a request handler that returns a single product attribute definition
+	return func(res http.ResponseWriter, req *http.Request) {
+		definitionID := chi.URLParam(req, "attribute_id")
+
+		definition, err := retrieveProductAttributeDefinitionFromDB(db, definitionID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product attribute", definitionID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attribute from database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(definition)
+	}
+}
+
+func buildProductAttributeDefinitionCreationHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductAttributeDefinitionCreationHandler is a request handler that creates a product attribute definition from user input
+	return func(res http.ResponseWriter, req *http.Request) {
+		definitionInput := &ProductAttributeDefinitionCreationInput{}
+		err := validateRequestInput(req, definitionInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if !restrictedStringIsValid(definitionInput.Name) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The attribute name received (%s) is invalid", definitionInput.Name))
+			return
+		}
+		if !productAttributeTypeIsValid(definitionInput.Type) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The attribute type received (%s) is invalid", definitionInput.Type))
+			return
+		}
+		if err = validateProductAttributeChoices(definitionInput.Type, definitionInput.Choices); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		exists, err := rowExistsInDB(db, productAttributeNameExistenceQuery, definitionInput.Name)
+		if err != nil || exists {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("product attribute `%s` already exists", definitionInput.Name))
+			return
+		}
+
+		definition := &ProductAttributeDefinition{
+			Name:     definitionInput.Name,
+			Type:     definitionInput.Type,
+			Required: definitionInput.Required,
+			Choices:  pq.StringArray(definitionInput.Choices),
+		}
+		if definition.Choices == nil {
+			definition.Choices = pq.StringArray{}
+		}
+		query, args := buildProductAttributeDefinitionCreationQuery(definition)
+		err = db.QueryRow(query, args...).Scan(definition.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "insert product attribute into database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(definition)
+	}
+}
+
+func buildProductAttributeDefinitionUpdateHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductAttributeDefinitionUpdateHandler is a request handler that updates whether a product attribute is required,
+	// and the choices for enum attributes
+	return func(res http.ResponseWriter, req *http.Request) {
+		definitionID := chi.URLParam(req, "attribute_id")
+
+		definitionInput := &ProductAttributeDefinitionUpdateInput{}
+		err := validateRequestInput(req, definitionInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		definition, err := retrieveProductAttributeDefinitionFromDB(db, definitionID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product attribute", definitionID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attribute from database")
+			return
+		}
+
+		if definitionInput.Required != nil {
+			definition.Required = *definitionInput.Required
+		}
+		if definitionInput.Choices != nil {
+			if err = validateProductAttributeChoices(definition.Type, definitionInput.Choices); err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+			definition.Choices = pq.StringArray(definitionInput.Choices)
+		}
+
+		query, args := buildProductAttributeDefinitionUpdateQuery(&definition)
+		err = db.QueryRow(query, args...).Scan(definition.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update product attribute in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(definition)
+	}
+}
+
+// archiveProductAttributeDefinition archives an attribute definition and removes its values from every product
+func archiveProductAttributeDefinition(db *sqlx.DB, definitionID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if err = tx.QueryRow(productAttributeDefinitionDeletionQuery, definitionID).Scan(&name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(productAttributeRemovalQuery, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func buildProductAttributeDefinitionDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductAttributeDefinitionDeletionHandler is a request handler that archives a product attribute definition
+	return func(res http.ResponseWriter, req *http.Request) {
+		definitionID := chi.URLParam(req, "attribute_id")
+
+		_, err := retrieveProductAttributeDefinitionFromDB(db, definitionID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product attribute", definitionID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attribute from database")
+			return
+		}
+
+		err = archiveProductAttributeDefinition(db, definitionID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive product attribute")
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	productAttributeDefinitionHeaders = strings.Split(strings.TrimSpace(productAttributeDefinitionsTableHeaders), ",\n\t\t")
+	exampleProductAttributeDefinition = ProductAttributeDefinition{
+		DBRow: DBRow{
+			ID:        4,
+			CreatedOn: generateExampleTimeForTests(),
+		},
+		Name:    "material",
+		Type:    productAttributeTypeEnum,
+		Choices: pq.StringArray{"oak", "maple"},
+	}
+	exampleNumericProductAttributeDefinition = ProductAttributeDefinition{
+		DBRow: DBRow{
+			ID:        5,
+			CreatedOn: generateExampleTimeForTests(),
+		},
+		Name:     "ply",
+		Type:     productAttributeTypeNumber,
+		Required: true,
+		Choices:  pq.StringArray{},
+	}
+)
+
+func productAttributeDefinitionRows(definitions []ProductAttributeDefinition) *sqlmock.Rows {
+	exampleRows := sqlmock.NewRows(productAttributeDefinitionHeaders)
+	for _, d := range definitions {
+		choices, _ := d.Choices.Value()
+		exampleRows = exampleRows.AddRow(d.ID, d.Name, d.Type, d.Required, choices, d.CreatedOn, nil, nil)
+	}
+	return exampleRows
+}
+
+func setExpectationsForProductAttributeDefinitionList(mock sqlmock.Sqlmock, names []string, definitions []ProductAttributeDefinition, err error) {
+	query, args := buildProductAttributeDefinitionsQuery(names)
+	mock.ExpectQuery(formatQueryForSQLMock(query) + "$").
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productAttributeDefinitionRows(definitions)).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductAttributeDefinitionRetrieval(mock sqlmock.Sqlmock, id string, err error) {
+	mock.ExpectQuery(formatQueryForSQLMock(productAttributeDefinitionRetrievalQuery)).
+		WithArgs(id).
+		WillReturnRows(productAttributeDefinitionRows([]ProductAttributeDefinition{exampleProductAttributeDefinition})).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductAttributeNameExistence(mock sqlmock.Sqlmock, name string, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(productAttributeNameExistenceQuery)).
+		WithArgs(name).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductAttributesValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := ProductAttributes(nil).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", v, "nil attributes should be stored as an empty object")
+
+	v, err = ProductAttributes{"material": "oak"}.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"material":"oak"}`, v)
+}
+
+func TestProductAttributesScan(t *testing.T) {
+	t.Parallel()
+
+	var a ProductAttributes
+	assert.Nil(t, a.Scan([]byte(`{"ply": 7}`)))
+	assert.Equal(t, ProductAttributes{"ply": float64(7)}, a)
+
+	assert.Nil(t, a.Scan(`{"material": "oak"}`))
+	assert.Equal(t, ProductAttributes{"material": "oak"}, a)
+
+	assert.Nil(t, a.Scan(nil))
+	assert.Equal(t, ProductAttributes{}, a)
+
+	assert.NotNil(t, a.Scan(12))
+}
+
+func TestProductAttributesWithoutNulls(t *testing.T) {
+	t.Parallel()
+	assert.Nil(t, ProductAttributes(nil).withoutNulls())
+	actual := ProductAttributes{"material": "oak", "ply": nil}.withoutNulls()
+	assert.Equal(t, ProductAttributes{"material": "oak"}, actual)
+}
+
+func TestValidateProductAttributeChoices(t *testing.T) {
+	t.Parallel()
+	assert.Nil(t, validateProductAttributeChoices(productAttributeTypeEnum, []string{"oak"}))
+	assert.NotNil(t, validateProductAttributeChoices(productAttributeTypeEnum, nil), "enums should need choices")
+	assert.Nil(t, validateProductAttributeChoices(productAttributeTypeText, nil))
+	assert.NotNil(t, validateProductAttributeChoices(productAttributeTypeBoolean, []string{"yes"}), "only enums should have choices")
+}
+
+func TestValidateProductAttributes(t *testing.T) {
+	t.Parallel()
+	definitions := []ProductAttributeDefinition{
+		exampleProductAttributeDefinition,
+		exampleNumericProductAttributeDefinition,
+		{Name: "waterproof", Type: productAttributeTypeBoolean},
+		{Name: "designer", Type: productAttributeTypeText},
+	}
+
+	testCases := []struct {
+		attributes ProductAttributes
+		valid      bool
+	}{
+		{ProductAttributes{"ply": float64(7)}, true},
+		{ProductAttributes{"ply": float64(7), "material": "oak", "waterproof": true, "designer": "Rodney"}, true},
+		{ProductAttributes{"material": "oak"}, false},
+		{ProductAttributes{"ply": "seven"}, false},
+		{ProductAttributes{"ply": float64(7), "material": "pine"}, false},
+		{ProductAttributes{"ply": float64(7), "waterproof": "yes"}, false},
+		{ProductAttributes{"ply": float64(7), "designer": 12}, false},
+		{ProductAttributes{"ply": float64(7), "colour": "red"}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateProductAttributes(definitions, tc.attributes)
+		assert.Equal(t, tc.valid, err == nil, "unexpected validation result for %v: %v", tc.attributes, err)
+	}
+}
+
+func TestParseAttributeFilterParams(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	definitions := []ProductAttributeDefinition{exampleProductAttributeDefinition, exampleNumericProductAttributeDefinition}
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"material", "ply"}, definitions, nil)
+
+	params := url.Values{
+		"attributes.material": {"oak"},
+		"attributes.ply":      {"7"},
+		"page":                {"2"},
+	}
+	actual, err := parseAttributeFilterParams(testUtil.DB, params)
+	assert.Nil(t, err)
+	assert.Equal(t, ProductAttributes{"material": "oak", "ply": float64(7)}, actual)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestParseAttributeFilterParamsWithoutAttributes(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	actual, err := parseAttributeFilterParams(testUtil.DB, url.Values{"page": {"2"}})
+	assert.Nil(t, err)
+	assert.Nil(t, actual)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestParseAttributeFilterParamsWithInvalidNumber(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	definitions := []ProductAttributeDefinition{exampleNumericProductAttributeDefinition}
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"ply"}, definitions, nil)
+
+	_, err := parseAttributeFilterParams(testUtil.DB, url.Values{"attributes.ply": {"seven"}})
+	_, isFilterErr := err.(*attributeFilterError)
+	assert.True(t, isFilterErr, "a bad filter value should be the client's fault")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestParseAttributeFilterParamsWithNonexistentAttribute(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"colour"}, nil, nil)
+
+	_, err := parseAttributeFilterParams(testUtil.DB, url.Values{"attributes.colour": {"red"}})
+	_, isFilterErr := err.(*attributeFilterError)
+	assert.True(t, isFilterErr, "filtering on an unknown attribute should be the client's fault")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithAttributeFilter(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"material"}, []ProductAttributeDefinition{exampleProductAttributeDefinition}, nil)
+	queryFilter := &QueryFilter{Page: 1, Limit: 25, PublishedOnly: true, Attributes: ProductAttributes{"material": "oak"}}
+	setExpectationsForRowCount(testUtil.Mock, "products", queryFilter, 1, nil)
+
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	query, args := buildProductListQuery(queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?attributes.material=oak", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithInvalidAttributeFilter(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"colour"}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?attributes.colour=red", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithErrorRetrievingAttributeDefinitions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, []string{"material"}, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?attributes.material=oak", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForRowCount(testUtil.Mock, "product_attribute_definitions", defaultQueryFilter, 1, nil)
+	query, _ := buildProductAttributeDefinitionListQuery(defaultQueryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(productAttributeDefinitionRows([]ProductAttributeDefinition{exampleProductAttributeDefinition}))
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_attributes", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductAttributeDefinitionsResponse{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual.Data))
+	assert.Equal(t, exampleProductAttributeDefinition.Choices, actual.Data[0].Choices)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionListHandlerWithErrorRetrievingCount(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForRowCount(testUtil.Mock, "product_attribute_definitions", defaultQueryFilter, 1, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_attributes", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionRetrievalHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionRetrieval(testUtil.Mock, "4", nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_attributes/4", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionRetrievalHandlerForNonexistentDefinition(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productAttributeDefinitionRetrievalQuery)).
+		WithArgs("4").
+		WillReturnRows(sqlmock.NewRows(productAttributeDefinitionHeaders))
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_attributes/4", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeNameExistence(testUtil.Mock, "material", false, nil)
+	newDefinition := &ProductAttributeDefinition{Name: "material", Type: productAttributeTypeEnum, Choices: pq.StringArray{"oak", "maple"}}
+	query, args := buildProductAttributeDefinitionCreationQuery(newDefinition)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productAttributeDefinitionRows([]ProductAttributeDefinition{exampleProductAttributeDefinition}))
+
+	body := `{"name": "material", "type": "enum", "choices": ["oak", "maple"]}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product_attributes", strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionCreationHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	invalidBodies := []string{
+		`{"name": "material"}`,
+		`{"name": "material", "type": "colour"}`,
+		`{"name": "material", "type": "enum"}`,
+		`{"name": "material", "type": "text", "choices": ["oak"]}`,
+		`{"name": "what a material!", "type": "text"}`,
+	}
+	for _, body := range invalidBodies {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_attributes", strings.NewReader(body))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for %s", body)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestProductAttributeDefinitionCreationHandlerWithExistingName(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeNameExistence(testUtil.Mock, "material", true, nil)
+
+	body := `{"name": "material", "type": "text"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product_attributes", strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionCreationHandlerWithoutAdminSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"name": "material", "type": "text"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product_attributes", strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionUpdateHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionRetrieval(testUtil.Mock, "4", nil)
+	updated := exampleProductAttributeDefinition
+	updated.Required = true
+	updated.Choices = pq.StringArray{"oak", "maple", "bamboo"}
+	query, args := buildProductAttributeDefinitionUpdateQuery(&updated)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productAttributeDefinitionRows([]ProductAttributeDefinition{updated}))
+
+	body := `{"required": true, "choices": ["oak", "maple", "bamboo"]}`
+	req, err := http.NewRequest(http.MethodPatch, "/v1/product_attributes/4", strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionUpdateHandlerWithInvalidChoices(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionRetrieval(testUtil.Mock, "4", nil)
+
+	req, err := http.NewRequest(http.MethodPatch, "/v1/product_attributes/4", strings.NewReader(`{"choices": []}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionRetrieval(testUtil.Mock, "4", nil)
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productAttributeDefinitionDeletionQuery)).
+		WithArgs("4").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("material"))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productAttributeRemovalQuery)).
+		WithArgs("material").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/product_attributes/4", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductAttributeDefinitionDeletionHandlerWithErrorRemovingValues(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionRetrieval(testUtil.Mock, "4", nil)
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productAttributeDefinitionDeletionQuery)).
+		WithArgs("4").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("material"))
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productAttributeRemovalQuery)).
+		WithArgs("material").
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/product_attributes/4", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	bundleDiscountTypePercentage = "percentage"
+	bundleDiscountTypeFlatAmount = "flat_amount"
+
+	productBundleExistenceQuery = `SELECT EXISTS(SELECT 1 FROM product_bundles WHERE product_id = $1 AND archived_on IS NULL)`
+)
+
+// errStockOperationOnBundle is what stock operations on a bundle get, since the quantity on a bundle's product row is only
+// a snapshot of what its components could make when it was created, and it's the components' stock that actually moves
+var errStockOperationOnBundle = errors.New("bundles don't hold stock of their own, so their components' stock has to be used instead")
+
+// ProductBundle describes a product that is sold as a set of other products. A bundle with no
+// discount type is sold at its own fixed price; otherwise its price is the sum of its components'
+// prices, less the discount.
+type ProductBundle struct {
+	DiscountType   string                   `json:"discount_type,omitempty"`
+	DiscountAmount Money                    `json:"discount_amount,omitempty"`
+	Components     []ProductBundleComponent `json:"components"`
+}
+
+// ProductBundleComponent is one of the products that make up a bundle, and how many of it the bundle contains
+type ProductBundleComponent struct {
+	ProductID uint64 `json:"product_id"`
+	SKU       string `json:"sku"`
+	Quantity  uint32 `json:"quantity"`
+
+	// these describe the component product itself, and are only used to derive the bundle's quantity and price
+	stock     int
+	unitPrice Money
+}
+
+// ProductBundleComponentInput is a struct that represents a bundle component in a product creation body
+type ProductBundleComponentInput struct {
+	SKU      string `json:"sku"`
+	Quantity uint32 `json:"quantity"`
+}
+
+// bundleComponentProduct is what we need to know about a product before it can be put in a bundle
+type bundleComponentProduct struct {
+	ProductBundleComponent
+	isBundle bool
+}
+
+func bundleDiscountTypeIsValid(discountType string) bool {
+	return discountType == "" || discountType == bundleDiscountTypePercentage || discountType == bundleDiscountTypeFlatAmount
+}
+
+// derivedQuantity is how many complete bundles can be assembled from the components in stock
+func (b *ProductBundle) derivedQuantity() int {
+	if len(b.Components) == 0 {
+		return 0
+	}
+	quantity := -1
+	for _, c := range b.Components {
+		available := 0
+		if c.stock > 0 {
+			available = c.stock / int(c.Quantity)
+		}
+		if quantity == -1 || available < quantity {
+			quantity = available
+		}
+	}
+	return quantity
+}
+
+// derivedPrice returns the price of a discounted bundle, and false for bundles sold at a fixed price
+func (b *ProductBundle) derivedPrice() (Money, bool) {
+	if b.DiscountType == "" {
+		return 0, false
+	}
+
+	var sum Money
+	for _, c := range b.Components {
+		sum = sum.Add(c.unitPrice.Times(int64(c.Quantity)))
+	}
+
+	price := sum
+	switch b.DiscountType {
+	case bundleDiscountTypePercentage:
+		price = sum.Sub(sum.Percent(b.DiscountAmount, RoundHalfUp))
+	case bundleDiscountTypeFlatAmount:
+		price = sum.Sub(b.DiscountAmount)
+	}
+	if price < 0 {
+		price = 0
+	}
+	return price, true
+}
+
+// apply sets the quantity and (for discounted bundles) the price of the bundle product
+func (b *ProductBundle) apply(p *Product) {
+	p.Bundle = b
+	p.Quantity = b.derivedQuantity()
+	if price, ok := b.derivedPrice(); ok {
+		p.Price = price
+		p.OnSale = false
+		p.SalePrice = 0
+	}
+}
+
+// productIsBundle reports whether a product is made up of other products
+func productIsBundle(db *sqlx.DB, productID uint64) (bool, error) {
+	return rowExistsInDB(db, productBundleExistenceQuery, fmt.Sprintf("%d", productID))
+}
+
+// validateBundleInput checks the bundle portion of a product creation body, and retrieves the products it's made of
+func validateBundleInput(db *sqlx.DB, in *ProductCreationInput) ([]ProductBundleComponent, error) {
+	if !bundleDiscountTypeIsValid(in.BundleDiscountType) {
+		return nil, fmt.Errorf("invalid bundle discount type: `%s`", in.BundleDiscountType)
+	}
+	if in.BundleDiscountType == bundleDiscountTypePercentage && in.BundleDiscountAmount > 100*centsPerUnit {
+		return nil, errors.New("bundles cannot be discounted by more than 100%")
+	}
+
+	skus := []string{}
+	seen := map[string]bool{}
+	for _, c := range in.Components {
+		if c.Quantity == 0 {
+			return nil, fmt.Errorf("bundle component `%s` must have a quantity of at least 1", c.SKU)
+		}
+		if c.SKU == in.SKU {
+			return nil, errors.New("a bundle cannot contain itself")
+		}
+		if seen[c.SKU] {
+			return nil, fmt.Errorf("bundle component `%s` appears more than once", c.SKU)
+		}
+		seen[c.SKU] = true
+		skus = append(skus, c.SKU)
+	}
+
+	componentProducts, err := retrieveBundleComponentProducts(db, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	components := []ProductBundleComponent{}
+	for _, c := range in.Components {
+		cp, ok := componentProducts[c.SKU]
+		if !ok {
+			return nil, fmt.Errorf("bundle component `%s` does not exist", c.SKU)
+		}
+		if cp.isBundle {
+			return nil, fmt.Errorf("bundle component `%s` is itself a bundle", c.SKU)
+		}
+		cp.Quantity = c.Quantity
+		components = append(components, cp.ProductBundleComponent)
+	}
+	return components, nil
+}
+
+// retrieveBundleComponentProducts retrieves the products with the given SKUs, keyed by SKU
+func retrieveBundleComponentProducts(db *sqlx.DB, skus []string) (map[string]bundleComponentProduct, error) {
+	out := map[string]bundleComponentProduct{}
+	query, args := buildBundleComponentProductQuery(skus)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for bundle components")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cp bundleComponentProduct
+		var onSale bool
+		var price, salePrice Money
+		err = rows.Scan(&cp.ProductID, &cp.SKU, &cp.stock, &price, &onSale, &salePrice, &cp.isBundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning bundle component")
+		}
+		cp.unitPrice = effectiveUnitPrice(price, onSale, salePrice)
+		out[cp.SKU] = cp
+	}
+	return out, rows.Err()
+}
+
+func effectiveUnitPrice(price Money, onSale bool, salePrice Money) Money {
+	if onSale {
+		return salePrice
+	}
+	return price
+}
+
+func createProductBundleInDB(tx *sql.Tx, bundleProductID uint64, b *ProductBundle) error {
+	query, args := buildProductBundleCreationQuery(bundleProductID, b)
+	_, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	query, args = buildProductBundleComponentCreationQuery(bundleProductID, b.Components)
+	_, err = tx.Exec(query, args...)
+	return err
+}
+
+// retrieveProductBundles retrieves the bundles among the given products, complete with their components, keyed by product ID
+func retrieveProductBundles(db *sqlx.DB, productIDs []uint64) (map[uint64]*ProductBundle, error) {
+	bundles := map[uint64]*ProductBundle{}
+	if len(productIDs) == 0 {
+		return bundles, nil
+	}
+
+	query, args := buildProductBundleListQuery(productIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product bundles")
+	}
+	defer rows.Close()
+
+	bundleIDs := []uint64{}
+	for rows.Next() {
+		var productID uint64
+		b := &ProductBundle{Components: []ProductBundleComponent{}}
+		err = rows.Scan(&productID, &b.DiscountType, &b.DiscountAmount)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product bundle")
+		}
+		bundles[productID] = b
+		bundleIDs = append(bundleIDs, productID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(bundleIDs) == 0 {
+		return bundles, nil
+	}
+
+	query, args = buildProductBundleComponentListQuery(bundleIDs)
+	componentRows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product bundle components")
+	}
+	defer componentRows.Close()
+
+	for componentRows.Next() {
+		var bundleID uint64
+		var c ProductBundleComponent
+		var onSale bool
+		var price, salePrice Money
+		err = componentRows.Scan(&bundleID, &c.ProductID, &c.SKU, &c.Quantity, &c.stock, &price, &onSale, &salePrice)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product bundle component")
+		}
+		c.unitPrice = effectiveUnitPrice(price, onSale, salePrice)
+		bundles[bundleID].Components = append(bundles[bundleID].Components, c)
+	}
+	return bundles, componentRows.Err()
+}
+
+// attachBundlesToProducts fills in the components of any bundles among the given products, and
+// derives their quantity (and price, if they're discounted) from those components
+func attachBundlesToProducts(db *sqlx.DB, products []Product) error {
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	bundles, err := retrieveProductBundles(db, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		if b, ok := bundles[products[i].ID]; ok {
+			b.apply(&products[i])
+		}
+	}
+	return nil
+}
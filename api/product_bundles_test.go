@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func exampleProductBundle() *ProductBundle {
+	return &ProductBundle{
+		DiscountType:   bundleDiscountTypePercentage,
+		DiscountAmount: 1000,
+		Components: []ProductBundleComponent{
+			{ProductID: 10, SKU: "deck", Quantity: 1, stock: 7, unitPrice: 5000},
+			{ProductID: 11, SKU: "wheel", Quantity: 4, stock: 20, unitPrice: 1000},
+		},
+	}
+}
+
+// setExpectationsForProductBundleList expects a query for bundles among the given products, followed by one
+// for their components if any of the given bundles are found. bundles is keyed by product ID.
+func setExpectationsForProductBundleList(mock sqlmock.Sqlmock, productIDs []uint64, bundles map[uint64]*ProductBundle, err error) {
+	exampleRows := sqlmock.NewRows([]string{"product_id", "discount_type", "discount_amount"})
+	bundleIDs := []uint64{}
+	for productID, b := range bundles {
+		exampleRows = exampleRows.AddRow(productID, b.DiscountType, b.DiscountAmount.String())
+		bundleIDs = append(bundleIDs, productID)
+	}
+	query, _ := buildProductBundleListQuery(productIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+
+	if len(bundleIDs) == 0 {
+		return
+	}
+	componentRows := sqlmock.NewRows([]string{"bundle_product_id", "component_product_id", "sku", "quantity", "stock", "price", "on_sale", "sale_price"})
+	for productID, b := range bundles {
+		for _, c := range b.Components {
+			componentRows = componentRows.AddRow(productID, c.ProductID, c.SKU, c.Quantity, c.stock, c.unitPrice.String(), false, "0.00")
+		}
+	}
+	query, _ = buildProductBundleComponentListQuery(bundleIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(componentRows)
+}
+
+func setExpectationsForProductBundleExistence(mock sqlmock.Sqlmock, productID uint64, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(strconv.FormatBool(exists))
+	mock.ExpectQuery(formatQueryForSQLMock(productBundleExistenceQuery)).
+		WithArgs(strconv.Itoa(int(productID))).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForBundleComponentProducts(mock sqlmock.Sqlmock, skus []string, components []bundleComponentProduct, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id", "sku", "quantity", "price", "on_sale", "sale_price", "is_bundle"})
+	for _, c := range components {
+		exampleRows = exampleRows.AddRow(c.ProductID, c.SKU, c.stock, c.unitPrice.String(), false, "0.00", c.isBundle)
+	}
+	query, _ := buildBundleComponentProductQuery(skus)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductBundleDerivedQuantity(t *testing.T) {
+	t.Parallel()
+	b := exampleProductBundle()
+	assert.Equal(t, 5, b.derivedQuantity(), "twenty wheels are only enough for five bundles")
+
+	b.Components[0].stock = 2
+	assert.Equal(t, 2, b.derivedQuantity(), "two decks are only enough for two bundles")
+
+	b.Components[1].stock = -3
+	assert.Equal(t, 0, b.derivedQuantity(), "oversold components shouldn't produce negative bundles")
+
+	assert.Equal(t, 0, (&ProductBundle{}).derivedQuantity(), "a bundle without components can't be sold")
+}
+
+func TestProductBundleDerivedPrice(t *testing.T) {
+	t.Parallel()
+	b := exampleProductBundle()
+
+	price, ok := b.derivedPrice()
+	assert.True(t, ok)
+	assert.Equal(t, Money(8100), price, "ten percent off of $90.00 should be $81.00")
+
+	b.DiscountType = bundleDiscountTypeFlatAmount
+	b.DiscountAmount = 1500
+	price, ok = b.derivedPrice()
+	assert.True(t, ok)
+	assert.Equal(t, Money(7500), price, "$15.00 off of $90.00 should be $75.00")
+
+	b.DiscountAmount = 10000
+	price, _ = b.derivedPrice()
+	assert.Equal(t, Money(0), price, "bundles should never have a negative price")
+
+	b.DiscountType = ""
+	_, ok = b.derivedPrice()
+	assert.False(t, ok, "bundles without a discount type have a fixed price")
+}
+
+func TestBundleDiscountTypeIsValid(t *testing.T) {
+	t.Parallel()
+	for _, valid := range []string{"", bundleDiscountTypePercentage, bundleDiscountTypeFlatAmount} {
+		assert.True(t, bundleDiscountTypeIsValid(valid), "`%s` should be a valid bundle discount type", valid)
+	}
+	assert.False(t, bundleDiscountTypeIsValid("buy_one_get_one"))
+}
+
+func TestProductRetrievalHandlerForBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, map[uint64]*ProductBundle{exampleProduct.ID: exampleProductBundle()}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, actual.Quantity, "bundle quantity should be derived from its components")
+	assert.Equal(t, Money(8100), actual.Price, "discounted bundle price should be derived from its components")
+	assert.Equal(t, 2, len(actual.Bundle.Components))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithErrorRetrievingBundles(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerForBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleBundleCreationInput := `
+		{
+			"sku": "skateboard-kit",
+			"name": "Skateboard Kit",
+			"price": 100,
+			"bundle_discount_type": "flat_amount",
+			"bundle_discount_amount": 10,
+			"components": [
+				{"sku": "deck", "quantity": 1},
+				{"sku": "wheel", "quantity": 4}
+			]
+		}
+	`
+	components := []bundleComponentProduct{
+		{ProductBundleComponent: ProductBundleComponent{ProductID: 10, SKU: "deck", stock: 7, unitPrice: 5000}},
+		{ProductBundleComponent: ProductBundleComponent{ProductID: 11, SKU: "wheel", stock: 20, unitPrice: 1000}},
+	}
+	expectedBundle := &ProductBundle{
+		DiscountType:   bundleDiscountTypeFlatAmount,
+		DiscountAmount: 1000,
+		Components: []ProductBundleComponent{
+			{ProductID: 10, SKU: "deck", Quantity: 1},
+			{ProductID: 11, SKU: "wheel", Quantity: 4},
+		},
+	}
+	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
+		DBRow:       DBRow{ID: 3},
+		Name:        "Skateboard Kit",
+		SKU:         "skateboard-kit",
+		Quantity:    5,
+		Price:       8000,
+	}
+
+	setExpectationsForProductExistence(testUtil.Mock, "skateboard-kit", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	setExpectationsForBundleComponentProducts(testUtil.Mock, []string{"deck", "wheel"}, components, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, nil)
+	query, args := buildProductBundleCreationQuery(expectedProduct.ID, expectedBundle)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	query, args = buildProductBundleComponentCreationQuery(expectedProduct.ID, expectedBundle.Components)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	setExpectationsForProductRevisionCreation(testUtil.Mock, expectedProduct.ID, "create", 0, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleBundleCreationInput))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, actual.Quantity)
+	assert.Equal(t, Money(8000), actual.Price)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerForBundleWithErrorCreatingComponents(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	exampleBundleCreationInput := `{"sku": "skateboard-kit", "name": "Skateboard Kit", "price": 100, "components": [{"sku": "deck", "quantity": 1}]}`
+	components := []bundleComponentProduct{
+		{ProductBundleComponent: ProductBundleComponent{ProductID: 10, SKU: "deck", stock: 7, unitPrice: 5000}},
+	}
+	expectedBundle := &ProductBundle{Components: []ProductBundleComponent{{ProductID: 10, SKU: "deck", Quantity: 1}}}
+	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
+		DBRow:       DBRow{ID: 3},
+		Name:        "Skateboard Kit",
+		SKU:         "skateboard-kit",
+		Quantity:    7,
+		Price:       10000,
+	}
+
+	setExpectationsForProductExistence(testUtil.Mock, "skateboard-kit", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	setExpectationsForBundleComponentProducts(testUtil.Mock, []string{"deck"}, components, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, nil)
+	query, args := buildProductBundleCreationQuery(expectedProduct.ID, expectedBundle)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleBundleCreationInput))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerForBundleWithInvalidComponents(t *testing.T) {
+	t.Parallel()
+
+	existingComponents := []bundleComponentProduct{
+		{ProductBundleComponent: ProductBundleComponent{ProductID: 10, SKU: "deck", stock: 7, unitPrice: 5000}},
+		{ProductBundleComponent: ProductBundleComponent{ProductID: 12, SKU: "starter-kit", stock: 1, unitPrice: 9000}, isBundle: true},
+	}
+	testCases := []struct {
+		components    string
+		extra         string
+		queriedSKUs   []string
+		expectsLookup bool
+	}{
+		// component doesn't exist
+		{components: `[{"sku": "deck", "quantity": 1}, {"sku": "bearing", "quantity": 8}]`, queriedSKUs: []string{"deck", "bearing"}, expectsLookup: true},
+		// component is a bundle
+		{components: `[{"sku": "starter-kit", "quantity": 1}]`, queriedSKUs: []string{"starter-kit"}, expectsLookup: true},
+		// zero quantity
+		{components: `[{"sku": "deck", "quantity": 0}]`},
+		// duplicate component
+		{components: `[{"sku": "deck", "quantity": 1}, {"sku": "deck", "quantity": 1}]`},
+		// invalid discount type
+		{components: `[{"sku": "deck", "quantity": 1}]`, extra: `"bundle_discount_type": "bogo",`},
+		// more than a 100% discount
+		{components: `[{"sku": "deck", "quantity": 1}]`, extra: `"bundle_discount_type": "percentage", "bundle_discount_amount": 101,`},
+	}
+
+	for _, tc := range testCases {
+		testUtil := setupTestVariables(t)
+		setExpectationsForProductExistence(testUtil.Mock, "skateboard-kit", false, nil)
+		setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+		if tc.expectsLookup {
+			setExpectationsForBundleComponentProducts(testUtil.Mock, tc.queriedSKUs, existingComponents, nil)
+		}
+
+		body := fmt.Sprintf(`{"sku": "skateboard-kit", "name": "Skateboard Kit", %s "components": %s}`, tc.extra, tc.components)
+		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+		assert.Nil(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "creating a bundle with components %s should respond 400", tc.components)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
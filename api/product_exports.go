@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatFeed   = "feed"
+
+	// exportBatchSize is how many products we pull from the database at a time while exporting
+	exportBatchSize = MaxLimit
+
+	googleFeedXML = "http://base.google.com/ns/1.0"
+)
+
+// productCSVExportHeaders are named after ProductCreationInput's fields so an export can be imported elsewhere as-is
+var productCSVExportHeaders = []string{
+	"sku",
+	"name",
+	"subtitle",
+	"description",
+	"upc",
+	"manufacturer",
+	"brand",
+	"quantity",
+	"taxable",
+	"price",
+	"on_sale",
+	"sale_price",
+	"cost",
+	"product_weight",
+	"product_height",
+	"product_width",
+	"product_length",
+	"package_weight",
+	"package_height",
+	"package_width",
+	"package_length",
+	"quantity_per_package",
+	"available_on",
+	"options",
+}
+
+// ExportedProduct is a product along with everything else we know about it, as it appears in NDJSON exports
+type ExportedProduct struct {
+	Product
+	Options []ProductOption `json:"options"`
+}
+
+// productExporter is implemented by each of the formats we can export products in
+type productExporter interface {
+	ContentType() string
+	Begin() error
+	Write(p *ExportedProduct) error
+	// Flush is called after every batch of products so the client starts receiving data right away
+	Flush() error
+	End() error
+}
+
+func newProductExporter(format string, w io.Writer, storeURL string) (productExporter, error) {
+	switch format {
+	case exportFormatCSV:
+		return &csvProductExporter{writer: csv.NewWriter(w)}, nil
+	case exportFormatNDJSON:
+		return &ndjsonProductExporter{encoder: json.NewEncoder(w)}, nil
+	case exportFormatFeed:
+		return &feedProductExporter{w: w, encoder: xml.NewEncoder(w), storeURL: strings.TrimRight(storeURL, "/")}, nil
+	}
+	return nil, fmt.Errorf("export format must be one of `%s`, `%s`, or `%s`", exportFormatCSV, exportFormatNDJSON, exportFormatFeed)
+}
+
+type ndjsonProductExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonProductExporter) ContentType() string            { return "application/x-ndjson" }
+func (e *ndjsonProductExporter) Begin() error                   { return nil }
+func (e *ndjsonProductExporter) Write(p *ExportedProduct) error { return e.encoder.Encode(p) }
+func (e *ndjsonProductExporter) Flush() error                   { return nil }
+func (e *ndjsonProductExporter) End() error                     { return nil }
+
+type csvProductExporter struct {
+	writer *csv.Writer
+}
+
+func formatFloatForCSV(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+// formatOptionsForCSV renders options the same way a ProductCreationInput would expect them in JSON
+func formatOptionsForCSV(options []ProductOption) (string, error) {
+	if len(options) == 0 {
+		return "", nil
+	}
+
+	in := []ProductOptionCreationInput{}
+	for _, option := range options {
+		o := ProductOptionCreationInput{Name: option.Name, Values: []ProductOptionValueCreationInput{}}
+		for _, v := range option.Values {
+			o.Values = append(o.Values, ProductOptionValueCreationInput{
+				Value:       v.Value,
+				PriceDelta:  v.PriceDelta,
+				WeightDelta: v.WeightDelta,
+				SKUSuffix:   v.SKUSuffix,
+			})
+		}
+		in = append(in, o)
+	}
+
+	b, err := json.Marshal(in)
+	return string(b), err
+}
+
+func (e *csvProductExporter) ContentType() string { return "text/csv" }
+func (e *csvProductExporter) Begin() error        { return e.writer.Write(productCSVExportHeaders) }
+
+func (e *csvProductExporter) Write(p *ExportedProduct) error {
+	options, err := formatOptionsForCSV(p.Options)
+	if err != nil {
+		return err
+	}
+
+	return e.writer.Write([]string{
+		p.SKU,
+		p.Name,
+		p.Subtitle.String,
+		p.Description,
+		p.UPC.String,
+		p.Manufacturer.String,
+		p.Brand.String,
+		strconv.Itoa(p.Quantity),
+		strconv.FormatBool(p.Taxable),
+		p.Price.String(),
+		strconv.FormatBool(p.OnSale),
+		p.SalePrice.String(),
+		p.Cost.String(),
+		formatFloatForCSV(p.ProductWeight),
+		formatFloatForCSV(p.ProductHeight),
+		formatFloatForCSV(p.ProductWidth),
+		formatFloatForCSV(p.ProductLength),
+		formatFloatForCSV(p.PackageWeight),
+		formatFloatForCSV(p.PackageHeight),
+		formatFloatForCSV(p.PackageWidth),
+		formatFloatForCSV(p.PackageLength),
+		strconv.Itoa(int(p.QuantityPerPackage)),
+		p.AvailableOn.Format(time.RFC3339),
+		options,
+	})
+}
+
+func (e *csvProductExporter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvProductExporter) End() error { return e.Flush() }
+
+// FeedItem represents a single product in a Google Merchant style product feed
+type FeedItem struct {
+	XMLName      xml.Name `xml:"item"`
+	ID           string   `xml:"g:id"`
+	Title        string   `xml:"title"`
+	Description  string   `xml:"description"`
+	Link         string   `xml:"link,omitempty"`
+	Price        string   `xml:"g:price"`
+	SalePrice    string   `xml:"g:sale_price,omitempty"`
+	Availability string   `xml:"g:availability"`
+	// AvailabilityDate is when backordered or pre-ordered products are expected to ship
+	AvailabilityDate string `xml:"g:availability_date,omitempty"`
+	Brand            string `xml:"g:brand,omitempty"`
+	GTIN             string `xml:"g:gtin,omitempty"`
+	Condition        string `xml:"g:condition"`
+}
+
+func formatPriceForFeed(price Money) string {
+	return fmt.Sprintf("%s %s", price, baseCurrency)
+}
+
+func newFeedItemFromProduct(p *Product, storeURL string) *FeedItem {
+	item := &FeedItem{
+		ID:           p.SKU,
+		Title:        p.Name,
+		Description:  p.Description,
+		Price:        formatPriceForFeed(p.Price),
+		Availability: p.availability(time.Now()),
+		Brand:        p.Brand.String,
+		GTIN:         p.UPC.String,
+		Condition:    "new",
+	}
+	if p.OnSale {
+		item.SalePrice = formatPriceForFeed(p.SalePrice)
+	}
+	switch item.Availability {
+	case productAvailabilityPreorder:
+		item.AvailabilityDate = p.AvailableOn.Format(time.RFC3339)
+	case productAvailabilityBackorder:
+		if p.RestockOn.Valid {
+			item.AvailabilityDate = p.RestockOn.Time.Format(time.RFC3339)
+		}
+	}
+	if storeURL != "" {
+		item.Link = fmt.Sprintf("%s/%s", storeURL, p.SKU)
+	}
+	return item
+}
+
+type feedProductExporter struct {
+	w        io.Writer
+	encoder  *xml.Encoder
+	storeURL string
+}
+
+func (e *feedProductExporter) ContentType() string { return "application/rss+xml" }
+
+func (e *feedProductExporter) Begin() error {
+	_, err := io.WriteString(e.w, xml.Header+fmt.Sprintf(`<rss version="2.0" xmlns:g="%s"><channel><title>Dairycart</title><link>`, googleFeedXML))
+	if err != nil {
+		return err
+	}
+	if err = xml.EscapeText(e.w, []byte(e.storeURL)); err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, `</link><description>Product feed</description>`)
+	return err
+}
+
+func (e *feedProductExporter) Write(p *ExportedProduct) error {
+	return e.encoder.Encode(newFeedItemFromProduct(&p.Product, e.storeURL))
+}
+
+func (e *feedProductExporter) Flush() error { return e.encoder.Flush() }
+
+func (e *feedProductExporter) End() error {
+	if err := e.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, `</channel></rss>`)
+	return err
+}
+
+// walkProductsForExport calls fn for every non-archived product in the database, fetching products
+// (and their options) one batch at a time so we never have the whole catalog in memory at once
+func walkProductsForExport(db *sqlx.DB, fn func(p *ExportedProduct) error, afterBatch func() error) error {
+	queryFilter := &QueryFilter{
+		Page:      1,
+		Limit:     exportBatchSize,
+		UseCursor: true,
+		SkipCount: true,
+	}
+
+	for {
+		var products []Product
+		query, args := buildProductListQuery(queryFilter)
+		err := retrieveListOfRowsFromDB(db, query, args, &products)
+		if err != nil {
+			return errors.Wrap(err, "Error retrieving products for export")
+		}
+		if len(products) == 0 {
+			return nil
+		}
+
+		productIDs := []uint64{}
+		for _, p := range products {
+			productIDs = append(productIDs, p.ID)
+		}
+		options, err := retrieveProductOptionsForProducts(db, productIDs)
+		if err != nil {
+			return err
+		}
+
+		deriveProductAvailabilities(products)
+		for _, p := range products {
+			if err = fn(&ExportedProduct{Product: p, Options: options[p.ID]}); err != nil {
+				return err
+			}
+		}
+		if err = afterBatch(); err != nil {
+			return err
+		}
+
+		if len(products) < exportBatchSize {
+			return nil
+		}
+		last := products[len(products)-1]
+		queryFilter.Cursor = &ListCursor{CreatedOn: last.CreatedOn, ID: last.ID}
+	}
+}
+
+func buildProductExportHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductExportHandler is a request handler that streams the entire catalog in a given format
+	return func(res http.ResponseWriter, req *http.Request) {
+		format := req.URL.Query().Get("format")
+		if format == "" {
+			format = exportFormatNDJSON
+		}
+
+		exporter, err := newProductExporter(format, res, req.URL.Query().Get("store_url"))
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		res.Header().Set("Content-Type", exporter.ContentType())
+		res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, strings.Replace(format, "feed", "xml", 1)))
+
+		// we hold off on writing anything until we have a product in hand, so that if the
+		// database is unavailable from the get-go we can still respond with a proper error.
+		started := false
+		begin := func() error {
+			if started {
+				return nil
+			}
+			started = true
+			return exporter.Begin()
+		}
+		write := func(p *ExportedProduct) error {
+			if err := begin(); err != nil {
+				return err
+			}
+			return exporter.Write(p)
+		}
+		flush := func() error {
+			if err := exporter.Flush(); err != nil {
+				return err
+			}
+			if f, ok := res.(http.Flusher); ok {
+				f.Flush()
+			}
+			return nil
+		}
+
+		err = walkProductsForExport(db, write, flush)
+		if err != nil && !started {
+			notifyOfInternalIssue(res, err, "export products")
+			return
+		} else if err != nil {
+			// once we've started writing the response the status code is set in stone, so all we can do is log
+			log.Printf("Encountered this error trying to export products: %v\n", err)
+			return
+		}
+
+		if err = begin(); err == nil {
+			err = exporter.End()
+		}
+		if err != nil {
+			log.Printf("Encountered this error trying to finish product export: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var exportQueryFilter = &QueryFilter{
+	Page:      1,
+	Limit:     exportBatchSize,
+	UseCursor: true,
+	SkipCount: true,
+}
+
+func setExpectationsForProductExport(mock sqlmock.Sqlmock, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	query, _ := buildProductListQuery(exportQueryFilter)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+	if err != nil {
+		return
+	}
+	setExpectationsForProductOptionListQueryForProducts(mock, []uint64{exampleProduct.ID}, exampleProductOption, nil)
+	setExpectationsForProductOptionValueListQueryForOptions(mock, []uint64{exampleProductOption.ID}, exampleProductOptionValue, nil)
+}
+
+func TestFormatOptionsForCSV(t *testing.T) {
+	t.Parallel()
+	options := []ProductOption{
+		{
+			Name: "color",
+			Values: []ProductOptionValue{
+				{Value: "red"},
+				{Value: "blue"},
+			},
+		},
+	}
+
+	actual, err := formatOptionsForCSV(options)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name":"color","values":["red","blue"]}]`, actual)
+
+	actual, err = formatOptionsForCSV(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", actual, "products without options should have an empty options column")
+}
+
+func TestNewFeedItemFromProduct(t *testing.T) {
+	t.Parallel()
+	p := *exampleProduct
+	p.OnSale = true
+	p.SalePrice = 8999
+	p.Quantity = 0
+
+	actual := newFeedItemFromProduct(&p, "https://store.com")
+	assert.Equal(t, "99.99 USD", actual.Price)
+	assert.Equal(t, "89.99 USD", actual.SalePrice, "products on sale should include their sale price")
+	assert.Equal(t, "out_of_stock", actual.Availability, "products without any stock should be out of stock")
+	assert.Equal(t, "https://store.com/skateboard", actual.Link)
+	assert.Equal(t, exampleProduct.UPC.String, actual.GTIN, "the product's UPC should be used as its GTIN")
+}
+
+func TestNewFeedItemFromBackorderedProduct(t *testing.T) {
+	t.Parallel()
+	p := *exampleProduct
+	p.Quantity = 0
+	p.StockPolicy = stockPolicyBackorder
+	p.RestockOn = NullTime{pq.NullTime{Time: generateExampleTimeForTests(), Valid: true}}
+
+	actual := newFeedItemFromProduct(&p, "")
+	assert.Equal(t, "backorder", actual.Availability)
+	assert.Equal(t, p.RestockOn.Time.Format(time.RFC3339), actual.AvailabilityDate, "backordered products should say when they'll be restocked")
+}
+
+func TestProductExportHandlerWithNDJSON(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductExport(testUtil.Mock, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export?format=ndjson", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	assert.Equal(t, "application/x-ndjson", testUtil.Response.Header().Get("Content-Type"))
+
+	actual := &ExportedProduct{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.SKU, actual.SKU)
+	assert.Equal(t, exampleProductOption.Name, actual.Options[0].Name, "exported products should include their options")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerWithCSV(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductExport(testUtil.Mock, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export?format=csv", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	records, err := csv.NewReader(testUtil.Response.Body).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records), "CSV export should have a header row and a row for the product")
+	assert.Equal(t, productCSVExportHeaders, records[0])
+	assert.Equal(t, exampleProduct.SKU, records[1][0])
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerWithCSVCanBeImported(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductExport(testUtil.Mock, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export?format=csv", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	reader, err := newCSVProductImportReader(testUtil.Response.Body, nil)
+	assert.Nil(t, err)
+	row, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, row.Err)
+	assert.Equal(t, exampleProduct.SKU, row.Input.SKU)
+	assert.Equal(t, exampleProductOption.Name, row.Input.Options[0].Name, "options should survive a round trip")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerWithFeed(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductExport(testUtil.Mock, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export?format=feed", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	body := testUtil.Response.Body.String()
+	assert.True(t, strings.HasSuffix(body, "</channel></rss>"), "feed should be a complete RSS document")
+	assert.Contains(t, body, "<g:id>skateboard</g:id>")
+	assert.Contains(t, body, "<g:availability>in_stock</g:availability>")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerWithInvalidFormat(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export?format=xlsx", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductExportHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductExport(testUtil.Mock, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products/export", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
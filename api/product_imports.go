@@ -0,0 +1,556 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	productIDRetrievalQueryBySKU = `SELECT id FROM products WHERE sku = $1 AND archived_on IS NULL`
+
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+
+	// maxImportLineSize is the longest single NDJSON line we're willing to read, which
+	// is comfortably more than a product with a novel for a description should need.
+	maxImportLineSize = 1 << 20
+)
+
+// ProductImportRowError describes why a single row of an import couldn't be loaded
+type ProductImportRowError struct {
+	Row     uint64 `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProductImportReport is what we respond with once we've processed a product import
+type ProductImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	Upsert    bool                    `json:"upsert"`
+	Atomic    bool                    `json:"atomic"`
+	Processed uint64                  `json:"processed"`
+	Created   uint64                  `json:"created"`
+	Updated   uint64                  `json:"updated"`
+	Failed    uint64                  `json:"failed"`
+	Errors    []ProductImportRowError `json:"errors"`
+}
+
+func (r *ProductImportReport) addRowError(row *productImportRow, err error) {
+	r.Failed++
+	rowErr := ProductImportRowError{Row: row.Number, Message: err.Error()}
+	if row.Input != nil {
+		rowErr.SKU = row.Input.SKU
+	}
+	r.Errors = append(r.Errors, rowErr)
+}
+
+func (r *ProductImportReport) tally(existingID uint64) {
+	if existingID != 0 {
+		r.Updated++
+	} else {
+		r.Created++
+	}
+}
+
+// productImportOptions represents the query parameters that control how an import behaves
+type productImportOptions struct {
+	Format string
+	DryRun bool
+	Upsert bool
+	Atomic bool
+	// Mapping maps CSV column headers to ProductCreationInput JSON field names. A header
+	// mapped to an empty string is ignored entirely.
+	Mapping map[string]string
+	// UserID is whoever is running the import, which is recorded in each product's revision history
+	UserID uint64
+}
+
+type productImportRow struct {
+	Number uint64
+	Input  *ProductCreationInput
+	// Fields are the JSON names of the fields the row actually provided, which are the only ones an upsert changes
+	Fields map[string]bool
+	Err    error
+}
+
+// productImportRowReader is implemented by anything that can turn an import file into products one row at a time
+type productImportRowReader interface {
+	// Next returns the next row of the import, or io.EOF once there are none left. Errors with an
+	// individual row are stored on the row itself; a returned error means the import can't continue.
+	Next() (*productImportRow, error)
+}
+
+func parseImportBool(rawParams map[string][]string, key string) (bool, error) {
+	values := rawParams[key]
+	if len(values) == 0 {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid value provided for %s: `%s`", key, values[0])
+	}
+	return b, nil
+}
+
+func parseProductImportOptions(req *http.Request) (*productImportOptions, error) {
+	rawParams := req.URL.Query()
+	opts := &productImportOptions{
+		Format: rawParams.Get("format"),
+	}
+
+	if opts.Format == "" {
+		contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		switch contentType {
+		case "text/csv":
+			opts.Format = importFormatCSV
+		case "application/x-ndjson", "application/jsonl":
+			opts.Format = importFormatNDJSON
+		}
+	}
+	if opts.Format != importFormatCSV && opts.Format != importFormatNDJSON {
+		return nil, errors.New("import format must be either `csv` or `ndjson`")
+	}
+
+	var err error
+	if opts.DryRun, err = parseImportBool(rawParams, "dry_run"); err != nil {
+		return nil, err
+	}
+	if opts.Upsert, err = parseImportBool(rawParams, "upsert"); err != nil {
+		return nil, err
+	}
+	if opts.Atomic, err = parseImportBool(rawParams, "atomic"); err != nil {
+		return nil, err
+	}
+
+	if mapping := rawParams.Get("mapping"); mapping != "" {
+		if opts.Format != importFormatCSV {
+			return nil, errors.New("column mappings only apply to CSV imports")
+		}
+		if err = json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			return nil, errors.Wrap(err, "invalid column mapping provided")
+		}
+	}
+
+	return opts, nil
+}
+
+func newProductImportRowReader(body io.Reader, opts *productImportOptions) (productImportRowReader, error) {
+	if opts.Format == importFormatCSV {
+		return newCSVProductImportReader(body, opts.Mapping)
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLineSize)
+	return &ndjsonProductImportReader{scanner: scanner}, nil
+}
+
+type ndjsonProductImportReader struct {
+	scanner *bufio.Scanner
+	line    uint64
+}
+
+func (r *ndjsonProductImportReader) Next() (*productImportRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		row := &productImportRow{Number: r.line, Input: &ProductCreationInput{}}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), row.Input); err != nil {
+			row.Input = nil
+			row.Err = errors.Wrap(err, "invalid JSON")
+		} else if err = json.Unmarshal([]byte(line), &fields); err == nil {
+			row.Fields = map[string]bool{}
+			for name := range fields {
+				row.Fields[name] = true
+			}
+		}
+		return row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type csvProductImportReader struct {
+	reader *csv.Reader
+	// fieldIndices holds the index of the ProductCreationInput field each column populates, or -1 for ignored columns
+	fieldIndices []int
+	row          uint64
+}
+
+// productCreationInputFieldIndices maps the JSON name of every ProductCreationInput field to its index
+func productCreationInputFieldIndices() map[string]int {
+	out := map[string]int{}
+	t := reflect.TypeOf(ProductCreationInput{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		out[name] = i
+	}
+	return out
+}
+
+func newCSVProductImportReader(body io.Reader, mapping map[string]string) (*csvProductImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV import must have a header row")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to read CSV header row")
+	}
+
+	knownFields := productCreationInputFieldIndices()
+	fieldIndices := make([]int, len(headers))
+	for i, header := range headers {
+		header = strings.TrimSpace(header)
+		fieldName, mapped := mapping[header]
+		if !mapped {
+			fieldName = strings.ToLower(header)
+		}
+		if fieldName == "" {
+			fieldIndices[i] = -1
+			continue
+		}
+
+		index, ok := knownFields[fieldName]
+		if !ok {
+			return nil, fmt.Errorf("CSV column `%s` doesn't correspond to an importable product field", header)
+		}
+		fieldIndices[i] = index
+	}
+
+	r := &csvProductImportReader{
+		reader:       reader,
+		fieldIndices: fieldIndices,
+		// the header was row one
+		row: 1,
+	}
+	return r, nil
+}
+
+func setProductInputFieldFromString(field reflect.Value, raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Ptr:
+		// optional numbers like max_oversell are left nil unless they're given
+		field.Set(reflect.New(field.Type().Elem()))
+		return setProductInputFieldFromString(field.Elem(), raw)
+	case reflect.Slice, reflect.Map:
+		// options and attributes are provided as JSON, exactly as they would be in a ProductCreationInput
+		return json.Unmarshal([]byte(raw), field.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func (r *csvProductImportReader) Next() (*productImportRow, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	r.row++
+
+	row := &productImportRow{Number: r.row}
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		row.Err = parseErr
+		return row, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	row.Input = &ProductCreationInput{}
+	// empty cells are treated the same as missing columns
+	row.Fields = map[string]bool{}
+	in := reflect.ValueOf(row.Input).Elem()
+	for i, raw := range record {
+		if r.fieldIndices[i] < 0 {
+			continue
+		}
+		field := in.Field(r.fieldIndices[i])
+		column := strings.Split(in.Type().Field(r.fieldIndices[i]).Tag.Get("json"), ",")[0]
+		raw = strings.TrimSpace(raw)
+		if err := setProductInputFieldFromString(field, raw); err != nil {
+			row.Err = fmt.Errorf("invalid value for %s: `%s`", column, raw)
+			break
+		}
+		if raw != "" {
+			row.Fields[column] = true
+		}
+	}
+	return row, nil
+}
+
+func retrieveProductIDBySKU(db sqlx.Queryer, sku string) (uint64, error) {
+	var id uint64
+	err := sqlx.Get(db, &id, productIDRetrievalQueryBySKU, sku)
+	return id, err
+}
+
+func updateProductInTransaction(tx *sql.Tx, p *Product, fields map[string]bool) error {
+	query, args := buildProductImportUpdateQuery(p, fields)
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// writeImportedProduct creates the product in the import row, or updates it if it already exists.
+// Options are only created alongside new products; upserting an existing product only changes the
+// fields the row provided, and leaves everything else (its options included) be.
+func writeImportedProduct(tx *sql.Tx, row *productImportRow, existingID uint64, userID uint64) error {
+	in := row.Input
+	p := newProductFromCreationInput(in)
+	if existingID != 0 {
+		p.ID = existingID
+		// a quantity set by an import is a correction as far as the product's ledger is concerned
+		var quantity int
+		var stockedAtLocations bool
+		if row.Fields["quantity"] {
+			if err := tx.QueryRow(productStockQuery, existingID).Scan(&quantity, &stockedAtLocations); err != nil {
+				return err
+			}
+		}
+		if err := updateProductInTransaction(tx, p, row.Fields); err != nil {
+			return err
+		}
+		if row.Fields["quantity"] && !stockedAtLocations {
+			if err := recordQuantityCorrection(tx, existingID, quantity, p.Quantity, userID); err != nil {
+				return err
+			}
+		}
+		return recordProductRevision(tx, existingID, "update", userID)
+	}
+
+	newProductID, err := createProductInDB(tx, p)
+	if err != nil {
+		return err
+	}
+	if err = recordInitialStock(tx, newProductID, p.Quantity, userID); err != nil {
+		return err
+	}
+	for _, optionAndValues := range in.Options {
+		_, err = createProductOptionAndValuesInDBFromInput(tx, optionAndValues, newProductID)
+		if err != nil {
+			return err
+		}
+	}
+	return recordProductRevision(tx, newProductID, "create", userID)
+}
+
+// validateImportRow checks everything about a row that doesn't require the database
+func validateImportRow(row *productImportRow, seenSKUs map[string]uint64) error {
+	if row.Err != nil {
+		return row.Err
+	}
+	if !restrictedStringIsValid(row.Input.SKU) {
+		return fmt.Errorf("The sku received (%s) is invalid", row.Input.SKU)
+	}
+	if row.Input.Status != "" && !productStatusIsValid(row.Input.Status) {
+		return fmt.Errorf("The status received (%s) is invalid", row.Input.Status)
+	}
+	if row.Input.StockPolicy != "" && !stockPolicyIsValid(row.Input.StockPolicy) {
+		return fmt.Errorf("The stock policy received (%s) is invalid", row.Input.StockPolicy)
+	}
+	if err := normalizeProductUPC(&row.Input.UPC); err != nil {
+		return err
+	}
+	for _, option := range row.Input.Options {
+		if err := validateProductOptionCreationInput(option); err != nil {
+			return err
+		}
+	}
+	if len(row.Input.Components) > 0 {
+		return errors.New("bundles can't be imported, and must be created individually")
+	}
+	if previousRow, ok := seenSKUs[row.Input.SKU]; ok {
+		return fmt.Errorf("sku `%s` already appeared on row %d", row.Input.SKU, previousRow)
+	}
+	seenSKUs[row.Input.SKU] = row.Number
+	return nil
+}
+
+func importProducts(db *sqlx.DB, rows productImportRowReader, opts *productImportOptions) (*ProductImportReport, error) {
+	report := &ProductImportReport{
+		DryRun: opts.DryRun,
+		Upsert: opts.Upsert,
+		Atomic: opts.Atomic,
+		Errors: []ProductImportRowError{},
+	}
+	seenSKUs := map[string]uint64{}
+
+	definitions, err := retrieveProductAttributeDefinitions(db, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// in atomic mode every row shares one transaction, which we only commit if every row succeeded,
+	// and which is also where we have to look for existing products to see the ones earlier rows wrote
+	var atomicTx *sql.Tx
+	var queryer sqlx.Queryer = db
+	if opts.Atomic && !opts.DryRun {
+		tx, err := db.Beginx()
+		if err != nil {
+			return nil, err
+		}
+		atomicTx, queryer = tx.Tx, tx
+	}
+
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if atomicTx != nil {
+				atomicTx.Rollback()
+			}
+			return nil, err
+		}
+		report.Processed++
+
+		if err = validateImportRow(row, seenSKUs); err != nil {
+			report.addRowError(row, err)
+			continue
+		}
+
+		existingID, err := retrieveProductIDBySKU(queryer, row.Input.SKU)
+		if err != nil && err != sql.ErrNoRows {
+			if atomicTx != nil {
+				atomicTx.Rollback()
+			}
+			return nil, err
+		}
+		if existingID != 0 && !opts.Upsert {
+			report.addRowError(row, fmt.Errorf("product with sku `%s` already exists", row.Input.SKU))
+			continue
+		}
+
+		// upserted rows without any attributes leave the product's attributes be
+		if existingID == 0 || row.Input.Attributes != nil {
+			if err = validateProductAttributes(definitions, row.Input.Attributes.withoutNulls()); err != nil {
+				report.addRowError(row, err)
+				continue
+			}
+		}
+
+		// there's no point in writing anything else once an atomic import has failed
+		if opts.DryRun || (atomicTx != nil && report.Failed > 0) {
+			report.tally(existingID)
+			continue
+		}
+
+		tx := atomicTx
+		if tx == nil {
+			if tx, err = db.Begin(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = writeImportedProduct(tx, row, existingID, opts.UserID); err != nil {
+			log.Printf("Encountered this error trying to import product on row %d: %v\n", row.Number, err)
+			report.addRowError(row, errors.New("unable to save product"))
+			if atomicTx == nil {
+				tx.Rollback()
+			}
+			continue
+		}
+
+		if atomicTx == nil {
+			if err = tx.Commit(); err != nil {
+				return nil, err
+			}
+		}
+		report.tally(existingID)
+	}
+
+	if atomicTx != nil {
+		if report.Failed > 0 {
+			// nothing was actually saved, so we shouldn't claim otherwise
+			report.Created, report.Updated = 0, 0
+			return report, atomicTx.Rollback()
+		}
+		return report, atomicTx.Commit()
+	}
+	return report, nil
+}
+
+func buildProductImportHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductImportHandler is a request handler that creates (or updates) products in bulk from a CSV or NDJSON body
+	return func(res http.ResponseWriter, req *http.Request) {
+		opts, err := parseProductImportOptions(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		opts.UserID, _ = retrieveUserIDFromSession(req, store)
+
+		rows, err := newProductImportRowReader(req.Body, opts)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		report, err := importProducts(db, rows, opts)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "import products")
+			return
+		}
+
+		if opts.Atomic && report.Failed > 0 {
+			res.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(res).Encode(report)
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const (
+	exampleProductImportNDJSON = `
+		{"name": "Skateboard", "sku": "skateboard", "price": 99.99, "quantity": 123}
+
+		{"name": "Helmet", "sku": "helmet", "price": 49.99, "quantity": 12}
+	`
+	exampleProductImportCSV = "Product Name,sku,Retail Price,Internal Notes\nSkateboard,skateboard,99.99,ignore me\n"
+)
+
+func setExpectationsForProductIDRetrievalBySKU(mock sqlmock.Sqlmock, sku string, id uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id"})
+	if id != 0 {
+		exampleRows = exampleRows.AddRow(id)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productIDRetrievalQueryBySKU)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func buildProductImportRequest(t *testing.T, body string, params map[string]string) *http.Request {
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	req, err := http.NewRequest(http.MethodPost, "/v1/products/import?"+values.Encode(), strings.NewReader(body))
+	assert.Nil(t, err)
+	return req
+}
+
+func TestParseProductImportOptions(t *testing.T) {
+	t.Parallel()
+	req := buildProductImportRequest(t, "", map[string]string{
+		"format":  "csv",
+		"dry_run": "true",
+		"atomic":  "true",
+		"mapping": `{"Product Name": "name"}`,
+	})
+
+	expected := &productImportOptions{
+		Format:  importFormatCSV,
+		DryRun:  true,
+		Atomic:  true,
+		Mapping: map[string]string{"Product Name": "name"},
+	}
+	actual, err := parseProductImportOptions(req)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual, "import options should be parsed from query params")
+}
+
+func TestParseProductImportOptionsInfersFormatFromContentType(t *testing.T) {
+	t.Parallel()
+	req := buildProductImportRequest(t, "", nil)
+	req.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+
+	actual, err := parseProductImportOptions(req)
+	assert.Nil(t, err)
+	assert.Equal(t, importFormatNDJSON, actual.Format, "import format should be inferred from the content type")
+}
+
+func TestParseProductImportOptionsWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testCases := []map[string]string{
+		{},
+		{"format": "xlsx"},
+		{"format": "csv", "upsert": "sure"},
+		{"format": "csv", "mapping": "{"},
+		{"format": "ndjson", "mapping": `{"a": "name"}`},
+	}
+
+	for _, params := range testCases {
+		_, err := parseProductImportOptions(buildProductImportRequest(t, "", params))
+		assert.NotNil(t, err, "parsing import options from %v should fail", params)
+	}
+}
+
+func TestCSVProductImportReader(t *testing.T) {
+	t.Parallel()
+	mapping := map[string]string{
+		"Product Name":   "name",
+		"Retail Price":   "price",
+		"Internal Notes": "",
+	}
+	reader, err := newCSVProductImportReader(strings.NewReader(exampleProductImportCSV), mapping)
+	assert.Nil(t, err)
+
+	row, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, row.Err)
+	assert.Equal(t, uint64(2), row.Number, "the first product should be on the second row of the file")
+	assert.Equal(t, &ProductCreationInput{Name: "Skateboard", SKU: "skateboard", Price: 9999}, row.Input)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVProductImportReaderWithUnknownColumn(t *testing.T) {
+	t.Parallel()
+	_, err := newCSVProductImportReader(strings.NewReader("sku,flavor\nskateboard,grape\n"), nil)
+	assert.NotNil(t, err)
+}
+
+func TestCSVProductImportReaderWithInvalidValue(t *testing.T) {
+	t.Parallel()
+	reader, err := newCSVProductImportReader(strings.NewReader("sku,quantity\nskateboard,lots\n"), nil)
+	assert.Nil(t, err)
+
+	row, err := reader.Next()
+	assert.Nil(t, err)
+	assert.NotNil(t, row.Err, "a row with an unparseable quantity should carry an error")
+}
+
+func TestCSVProductImportReaderWithStockPolicy(t *testing.T) {
+	t.Parallel()
+	reader, err := newCSVProductImportReader(strings.NewReader("sku,stock_policy,max_oversell\nskateboard,backorder,20\nhelmet,deny,\n"), nil)
+	assert.Nil(t, err)
+
+	row, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, row.Err)
+	assert.Equal(t, stockPolicyBackorder, row.Input.StockPolicy)
+	assert.Equal(t, uint32(20), *row.Input.MaxOversell)
+
+	row, err = reader.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, row.Err)
+	assert.Nil(t, row.Input.MaxOversell, "an empty max_oversell should leave the product without a limit")
+}
+
+func TestCSVProductImportReaderTracksProvidedFields(t *testing.T) {
+	t.Parallel()
+	reader, err := newCSVProductImportReader(strings.NewReader("sku,price,cost\nskateboard,99.99,\n"), nil)
+	assert.Nil(t, err)
+
+	row, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"sku": true, "price": true}, row.Fields, "empty cells shouldn't count as provided")
+}
+
+func TestNDJSONProductImportReader(t *testing.T) {
+	t.Parallel()
+	reader, err := newProductImportRowReader(strings.NewReader(exampleProductImportNDJSON+"\n{nope"), &productImportOptions{Format: importFormatNDJSON})
+	assert.Nil(t, err)
+
+	var rows []*productImportRow
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		rows = append(rows, row)
+	}
+
+	assert.Equal(t, 3, len(rows), "blank lines should be skipped")
+	assert.Equal(t, "helmet", rows[1].Input.SKU)
+	assert.Equal(t, map[string]bool{"name": true, "sku": true, "price": true, "quantity": true}, rows[1].Fields)
+	assert.NotNil(t, rows[2].Err, "invalid JSON should be reported for the row it appears on")
+}
+
+func TestProductImportHandlerWithDryRun(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", 0, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", 0, nil)
+
+	req := buildProductImportRequest(t, exampleProductImportNDJSON, map[string]string{"format": "ndjson", "dry_run": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), actual.Created, "a dry run should report what it would have created")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithUpsert(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	body := `{"name": "Skateboard", "sku": "skateboard", "price": 99.99, "quantity": 123}`
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", exampleProduct.ID, nil)
+	testUtil.Mock.ExpectBegin()
+	// the import doesn't say anything about the product's status, availability, or stock policy, so those are left alone
+	fields := map[string]bool{"name": true, "sku": true, "price": true, "quantity": true}
+	setExpectationsForProductStock(testUtil.Mock, exampleProduct.ID, 100, false)
+	updateQuery, _ := buildProductImportUpdateQuery(&Product{DBRow: DBRow{ID: exampleProduct.ID}}, fields)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(updateQuery) + "$").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// the quantity the import set is recorded in the product's ledger as a correction
+	setExpectationsForStockRecord(testUtil.Mock, &InventoryMovement{
+		ProductID:     exampleProduct.ID,
+		Delta:         23,
+		QuantityAfter: 123,
+		Reason:        inventoryMovementReasonCorrection,
+		Reference:     "product update",
+	})
+	setExpectationsForProductRevisionCreation(testUtil.Mock, exampleProduct.ID, "update", 0, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson", "upsert": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Updated, "an existing sku should be updated when upserting")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithPartialCSVUpsert(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	body := "sku,subtitle,sale_price,on_sale,cost\nskateboard,Now with wheels,89.99,true,\n"
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", exampleProduct.ID, nil)
+	testUtil.Mock.ExpectBegin()
+	// price, quantity, and the empty cost are left alone rather than zeroed out
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock("UPDATE products SET on_sale = $1, sale_price = $2, sku = $3, subtitle = $4, updated_on = NOW() WHERE id = $5")+"$").
+		WithArgs(true, Money(8999), "skateboard", "Now with wheels", exampleProduct.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	setExpectationsForProductRevisionCreation(testUtil.Mock, exampleProduct.ID, "update", 0, nil)
+	testUtil.Mock.ExpectCommit()
+
+	req := buildProductImportRequest(t, body, map[string]string{"format": "csv", "upsert": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Updated, "an existing sku should be updated when upserting")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithExistingSKUAndNoUpsert(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	body := `{"name": "Skateboard", "sku": "skateboard", "price": 99.99, "quantity": 123}`
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", exampleProduct.ID, nil)
+
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Failed)
+	assert.Equal(t, "product with sku `skateboard` already exists", actual.Errors[0].Message)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithAtomicFailure(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	body := exampleProductImportNDJSON + `{"name": "Bad", "sku": "pooƃ ou sᴉ nʞs sᴉɥʇ"}` + "\n" + `{"name": "Helmet", "sku": "helmet"}`
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", 0, nil)
+	setExpectationsForProductCreation(testUtil.Mock, newProductFromCreationInput(&ProductCreationInput{Name: "Skateboard", SKU: "skateboard", Price: 9999, Quantity: 123}), nil)
+	setExpectationsForInitialStock(testUtil.Mock, 0, 123)
+	setExpectationsForProductRevisionCreation(testUtil.Mock, 0, "create", 0, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", 0, nil)
+	setExpectationsForProductCreation(testUtil.Mock, newProductFromCreationInput(&ProductCreationInput{Name: "Helmet", SKU: "helmet", Price: 4999, Quantity: 12}), nil)
+	setExpectationsForInitialStock(testUtil.Mock, 0, 12)
+	setExpectationsForProductRevisionCreation(testUtil.Mock, 0, "create", 0, nil)
+	testUtil.Mock.ExpectRollback()
+
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson", "atomic": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), actual.Failed, "both the invalid sku and the duplicate sku should be reported")
+	assert.Zero(t, actual.Created, "nothing should be reported as created when an atomic import fails")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", 0, arbitraryError)
+
+	req := buildProductImportRequest(t, exampleProductImportNDJSON, map[string]string{"format": "ndjson"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
+	body := `{"name": "Skateboard Kit", "sku": "skateboard-kit", "price": 99.99, "components": [{"sku": "deck", "quantity": 1}]}`
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson", "dry_run": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Failed, "bundles shouldn't be importable")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductImportHandlerWithInvalidAttributes(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	definitions := []ProductAttributeDefinition{exampleProductAttributeDefinition}
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, definitions, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "skateboard", 0, nil)
+
+	body := `{"name": "Skateboard", "sku": "skateboard", "price": 99.99, "attributes": {"material": "pine"}}`
+	req := buildProductImportRequest(t, body, map[string]string{"format": "ndjson", "dry_run": "true"})
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductImportReport{}
+	err := json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), actual.Failed, "products with invalid attributes shouldn't be importable")
+	assert.Equal(t, "attribute `material` must be one of: oak, maple", actual.Errors[0].Message)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
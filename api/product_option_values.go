@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/jmoiron/sqlx"
@@ -13,11 +14,24 @@ import (
 )
 
 const (
+	productOptionValuesHeaders = `id,
+		product_option_id,
+		value,
+		created_on,
+		updated_on,
+		archived_on,
+		price_delta,
+		weight_delta,
+		sku_suffix,
+		position
+	`
 	productOptionValueExistenceQuery            = `SELECT EXISTS(SELECT 1 FROM product_option_values WHERE id = $1 AND archived_on IS NULL)`
 	productOptionValueExistenceForOptionIDQuery = `SELECT EXISTS(SELECT 1 FROM product_option_values WHERE product_option_id = $1 AND value = $2 AND archived_on IS NULL)`
 	productOptionValueRetrievalQuery            = `SELECT * FROM product_option_values WHERE id = $1`
-	productOptionValueRetrievalForOptionIDQuery = `SELECT * FROM product_option_values WHERE product_option_id = $1 AND archived_on IS NULL`
+	productOptionValueRetrievalForOptionIDQuery = `SELECT * FROM product_option_values WHERE product_option_id = $1 AND archived_on IS NULL ORDER BY position, id`
 	productOptionValueDeletionQuery             = `UPDATE product_option_values SET archived_on = NOW() WHERE id = $1 AND archived_on IS NULL`
+	productOptionValueIDsForOptionQuery         = `SELECT id FROM product_option_values WHERE product_option_id = $1 AND archived_on IS NULL ORDER BY position, id`
+	productOptionValuePositionUpdateQuery       = `UPDATE product_option_values SET position = $1, updated_on = NOW() WHERE id = $2`
 )
 
 // ProductOptionValue represents a product's option values. If you have a t-shirt that comes in three colors
@@ -27,6 +41,14 @@ type ProductOptionValue struct {
 	DBRow
 	ProductOptionID uint64 `json:"product_option_id"`
 	Value           string `json:"value"`
+
+	// Modifiers are how a value like "XXL" can cost or weigh more than "S". They're added to the
+	// product's own price and package weight, and the suffix is appended to its SKU.
+	PriceDelta  Money   `json:"price_delta"`
+	WeightDelta float32 `json:"weight_delta"`
+	SKUSuffix   string  `json:"sku_suffix"`
+
+	Position uint32 `json:"position"`
 }
 
 func (pav *ProductOptionValue) generateScanArgs() []interface{} {
@@ -37,18 +59,58 @@ func (pav *ProductOptionValue) generateScanArgs() []interface{} {
 		&pav.CreatedOn,
 		&pav.UpdatedOn,
 		&pav.ArchivedOn,
+		&pav.PriceDelta,
+		&pav.WeightDelta,
+		&pav.SKUSuffix,
+		&pav.Position,
 	}
 }
 
-// ProductOptionValueCreationInput is a struct to use for creating product option values
+// ProductOptionValueCreationInput is a struct to use for creating product option values alongside their option.
+// Values without modifiers can be provided as plain strings, which is all they used to be.
 type ProductOptionValueCreationInput struct {
-	ProductOptionID uint64
-	Value           string `json:"value"`
+	Value       string  `json:"value"`
+	PriceDelta  Money   `json:"price_delta"`
+	WeightDelta float32 `json:"weight_delta"`
+	SKUSuffix   string  `json:"sku_suffix"`
 }
 
-// ProductOptionValueUpdateInput is a struct to use for updating product option values
+// productOptionValueCreationInput exists so that ProductOptionValueCreationInput's JSON methods can decode and
+// encode its fields without calling themselves
+type productOptionValueCreationInput ProductOptionValueCreationInput
+
+// UnmarshalJSON accepts either a bare string value or an object with modifiers
+func (in *ProductOptionValueCreationInput) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		*in = ProductOptionValueCreationInput{}
+		return json.Unmarshal(b, &in.Value)
+	}
+	return json.Unmarshal(b, (*productOptionValueCreationInput)(in))
+}
+
+// MarshalJSON renders values without modifiers as bare strings
+func (in ProductOptionValueCreationInput) MarshalJSON() ([]byte, error) {
+	if in.PriceDelta == 0 && in.WeightDelta == 0 && in.SKUSuffix == "" {
+		return json.Marshal(in.Value)
+	}
+	return json.Marshal(productOptionValueCreationInput(in))
+}
+
+// ProductOptionValueUpdateInput is a struct to use for updating product option values. The modifiers are
+// pointers so that they can be updated to zero.
 type ProductOptionValueUpdateInput struct {
-	Value string `json:"value"`
+	Value       string   `json:"value"`
+	PriceDelta  *Money   `json:"price_delta"`
+	WeightDelta *float32 `json:"weight_delta"`
+	SKUSuffix   *string  `json:"sku_suffix"`
+}
+
+// validateSKUSuffix makes sure a value's SKU suffix would leave its product with a SKU we'd accept
+func validateSKUSuffix(suffix string) error {
+	if suffix != "" && !restrictedStringIsValid(suffix) {
+		return fmt.Errorf("The sku suffix received (%s) is invalid", suffix)
+	}
+	return nil
 }
 
 // retrieveProductOptionValue retrieves a ProductOptionValue with a given ID from the database
@@ -78,6 +140,216 @@ func retrieveProductOptionValueForOptionFromDB(db *sqlx.DB, optionID uint64) ([]
 	return values, nil
 }
 
+// retrieveProductOptionValuesForOptions retrieves the values for a batch of product options, keyed by option ID
+func retrieveProductOptionValuesForOptions(db *sqlx.DB, optionIDs []uint64) (map[uint64][]ProductOptionValue, error) {
+	out := map[uint64][]ProductOptionValue{}
+	if len(optionIDs) == 0 {
+		return out, nil
+	}
+
+	query, args := buildProductOptionValueListQueryForOptions(optionIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product option values")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value ProductOptionValue
+		if err = rows.Scan(value.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product option value")
+		}
+		out[value.ProductOptionID] = append(out[value.ProductOptionID], value)
+	}
+	return out, rows.Err()
+}
+
+// productVariant is what a product becomes once a value has been chosen for some of its options
+type productVariant struct {
+	SKUSuffix   string
+	PriceDelta  Money
+	WeightDelta float32
+}
+
+// parseOptionValueIDsParam reads the comma separated option value IDs a client has chosen for a product
+func parseOptionValueIDsParam(req *http.Request) ([]uint64, error) {
+	rawIDs := req.URL.Query().Get("option_value_ids")
+	if rawIDs == "" {
+		return nil, nil
+	}
+
+	ids := []uint64{}
+	seen := map[uint64]bool{}
+	for _, rawID := range strings.Split(rawIDs, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(rawID), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid option value ID: `%s`", rawID)
+		}
+		if !seen[id] {
+			ids = append(ids, id)
+			seen[id] = true
+		}
+	}
+	return ids, nil
+}
+
+// retrieveProductOptionValuesForProduct retrieves the option values with the given IDs, so long as they belong to the product
+func retrieveProductOptionValuesForProduct(db *sqlx.DB, productID uint64, valueIDs []uint64) ([]ProductOptionValue, error) {
+	values := []ProductOptionValue{}
+	if len(valueIDs) == 0 {
+		return values, nil
+	}
+
+	query, args := buildProductOptionValueListQueryForProduct(productID, valueIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product option values")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value ProductOptionValue
+		if err = rows.Scan(value.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product option value")
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// combineOptionValues adds up the modifiers of the chosen option values, which have to include every value
+// that was asked for, and no more than one value per option
+func combineOptionValues(values []ProductOptionValue, valueIDs []uint64) (*productVariant, error) {
+	found := map[uint64]bool{}
+	chosenOptions := map[uint64]bool{}
+	variant := &productVariant{}
+	for _, v := range values {
+		if chosenOptions[v.ProductOptionID] {
+			return nil, fmt.Errorf("more than one value was chosen for product option %d", v.ProductOptionID)
+		}
+		chosenOptions[v.ProductOptionID] = true
+		found[v.ID] = true
+
+		variant.SKUSuffix += v.SKUSuffix
+		variant.PriceDelta = variant.PriceDelta.Add(v.PriceDelta)
+		variant.WeightDelta += v.WeightDelta
+	}
+
+	for _, id := range valueIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("option value %d doesn't belong to this product", id)
+		}
+	}
+	return variant, nil
+}
+
+func buildProductOptionValueListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueListHandler is a request handler that returns an option's values in display order
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionID := chi.URLParam(req, "option_id")
+		// eating this error because Chi should validate this for us.
+		optionIDInt, _ := strconv.ParseUint(optionID, 10, 64)
+
+		optionExists, err := rowExistsInDB(db, productOptionExistenceQuery, optionID)
+		if err != nil || !optionExists {
+			respondThatRowDoesNotExist(req, res, "product option", optionID)
+			return
+		}
+
+		values, err := retrieveProductOptionValueForOptionFromDB(db, optionIDInt)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+			return
+		}
+		if values == nil {
+			values = []ProductOptionValue{}
+		}
+
+		err = translateProductOptionValues(db, values, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value translations from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(values)
+	}
+}
+
+func buildProductOptionValueRetrievalHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueRetrievalHandler is a request handler that returns a single product option value
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionValueID := chi.URLParam(req, "option_value_id")
+		// eating this error because Chi should validate this for us.
+		optionValueIDInt, _ := strconv.ParseUint(optionValueID, 10, 64)
+
+		optionValueExists, err := rowExistsInDB(db, productOptionValueExistenceQuery, optionValueID)
+		if err != nil || !optionValueExists {
+			respondThatRowDoesNotExist(req, res, "product option value", optionValueID)
+			return
+		}
+
+		value, err := retrieveProductOptionValueFromDB(db, optionValueIDInt)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value from the database")
+			return
+		}
+
+		values := []ProductOptionValue{*value}
+		err = translateProductOptionValues(db, values, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value translations from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(values[0])
+	}
+}
+
+func buildProductOptionValueReorderHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueReorderHandler is a request handler that changes the order an option's values are displayed in
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionID := chi.URLParam(req, "option_id")
+		// eating this error because Chi should validate this for us.
+		optionIDInt, _ := strconv.ParseUint(optionID, 10, 64)
+
+		optionExists, err := rowExistsInDB(db, productOptionExistenceQuery, optionID)
+		if err != nil || !optionExists {
+			respondThatRowDoesNotExist(req, res, "product option", optionID)
+			return
+		}
+
+		in := &ReorderInput{}
+		err = validateRequestInput(req, in)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		var existingIDs []uint64
+		err = db.Select(&existingIDs, productOptionValueIDsForOptionQuery, optionIDInt)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+			return
+		}
+		orderedIDs, err := reorderIDs(existingIDs, in.IDs)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		err = updatePositionsInDB(db, productOptionValuePositionUpdateQuery, orderedIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update product option value positions in the database")
+			return
+		}
+
+		values, err := retrieveProductOptionValueForOptionFromDB(db, optionIDInt)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(values)
+	}
+}
+
 func updateProductOptionValueInDB(db *sqlx.DB, v *ProductOptionValue) error {
 	valueUpdateQuery, queryArgs := buildProductOptionValueUpdateQuery(v)
 	err := db.QueryRow(valueUpdateQuery, queryArgs...).Scan(v.generateScanArgs()...)
@@ -98,19 +370,36 @@ func buildProductOptionValueUpdateHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		updatedValueData := &ProductOptionValue{}
+		updatedValueData := &ProductOptionValueUpdateInput{}
 		err = validateRequestInput(req, updatedValueData)
 		if err != nil {
 			notifyOfInvalidRequestBody(res, err)
 			return
 		}
+		if updatedValueData.SKUSuffix != nil {
+			if err = validateSKUSuffix(*updatedValueData.SKUSuffix); err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+		}
 
 		existingOptionValue, err := retrieveProductOptionValueFromDB(db, uint64(optionValueIDInt))
 		if err != nil {
 			notifyOfInternalIssue(res, err, "retrieve product option value from the database")
 			return
 		}
-		existingOptionValue.Value = updatedValueData.Value
+		if updatedValueData.Value != "" {
+			existingOptionValue.Value = updatedValueData.Value
+		}
+		if updatedValueData.PriceDelta != nil {
+			existingOptionValue.PriceDelta = *updatedValueData.PriceDelta
+		}
+		if updatedValueData.WeightDelta != nil {
+			existingOptionValue.WeightDelta = *updatedValueData.WeightDelta
+		}
+		if updatedValueData.SKUSuffix != nil {
+			existingOptionValue.SKUSuffix = *updatedValueData.SKUSuffix
+		}
 
 		err = updateProductOptionValueInDB(db, existingOptionValue)
 		if err != nil {
@@ -162,6 +451,10 @@ func buildProductOptionValueCreationHandler(db *sqlx.DB) http.HandlerFunc {
 			notifyOfInvalidRequestBody(res, err)
 			return
 		}
+		if err = validateSKUSuffix(newProductOptionValue.SKUSuffix); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
 		newProductOptionValue.ProductOptionID = uint64(optionIDInt)
 
 		// can't create a product option value that already exists
@@ -3,6 +3,7 @@ package main
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
@@ -42,7 +43,7 @@ func init() {
 		ProductOptionID: 123, // == exampleProductOption.ID
 		Value:           "something else",
 	}
-	productOptionValueHeaders = []string{"id", "product_option_id", "value", "created_on", "updated_on", "archived_on"}
+	productOptionValueHeaders = []string{"id", "product_option_id", "value", "created_on", "updated_on", "archived_on", "price_delta", "weight_delta", "sku_suffix", "position"}
 	productOptionValueData = []driver.Value{
 		exampleProductOptionValue.ID,
 		exampleProductOptionValue.ProductOptionID,
@@ -50,6 +51,10 @@ func init() {
 		generateExampleTimeForTests(),
 		nil,
 		nil,
+		exampleProductOptionValue.PriceDelta.String(),
+		exampleProductOptionValue.WeightDelta,
+		exampleProductOptionValue.SKUSuffix,
+		exampleProductOptionValue.Position,
 	}
 }
 
@@ -83,16 +88,16 @@ func setExpectationsForProductOptionValueRetrieval(mock sqlmock.Sqlmock, v *Prod
 
 func setExpectationsForProductOptionValueCreation(mock sqlmock.Sqlmock, v *ProductOptionValue, err error) {
 	exampleRows := sqlmock.NewRows([]string{"id"}).AddRow(exampleProductOptionValue.ID)
-	query, _ := buildProductOptionValueCreationQuery(v)
+	query, args := buildProductOptionValueCreationQuery(v)
 	mock.ExpectQuery(formatQueryForSQLMock(query)).
-		WithArgs(v.ProductOptionID, v.Value).
+		WithArgs(argsToDriverValues(args)...).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
 }
 
 func setExpectationsForProductOptionValueUpdate(mock sqlmock.Sqlmock, v *ProductOptionValue, err error) {
-	exampleRows := sqlmock.NewRows(productOptionHeaders).
-		AddRow([]driver.Value{v.ID, v.ProductOptionID, v.Value, generateExampleTimeForTests(), nil, nil}...)
+	exampleRows := sqlmock.NewRows(productOptionValueHeaders).
+		AddRow([]driver.Value{v.ID, v.ProductOptionID, v.Value, generateExampleTimeForTests(), nil, nil, v.PriceDelta.String(), v.WeightDelta, v.SKUSuffix, v.Position}...)
 	query, args := buildProductOptionValueUpdateQuery(v)
 	queryArgs := argsToDriverValues(args)
 	mock.ExpectQuery(formatQueryForSQLMock(query)).
@@ -117,6 +122,15 @@ func setExpectationsForProductOptionValueDeletion(mock sqlmock.Sqlmock, id uint6
 		WillReturnError(err)
 }
 
+func setExpectationsForProductOptionValueListQueryForOptions(mock sqlmock.Sqlmock, optionIDs []uint64, v *ProductOptionValue, err error) {
+	exampleRows := sqlmock.NewRows(productOptionValueHeaders).
+		AddRow([]driver.Value{v.ID, v.ProductOptionID, v.Value, generateExampleTimeForTests(), nil, nil, v.PriceDelta.String(), v.WeightDelta, v.SKUSuffix, v.Position}...)
+	query, _ := buildProductOptionValueListQueryForOptions(optionIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
 func TestRetrieveProductOptionValueFromDB(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -482,3 +496,371 @@ func TestProductOptionValueDeletionHandlerWithErrorDeletingValue(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
+
+func TestProductOptionValueCreationInputUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	actual := []ProductOptionValueCreationInput{}
+	err := json.Unmarshal([]byte(`["S", {"value": "XXL", "price_delta": 2.50, "weight_delta": 0.25, "sku_suffix": "-xxl"}]`), &actual)
+	assert.Nil(t, err)
+
+	expected := []ProductOptionValueCreationInput{
+		{Value: "S"},
+		{Value: "XXL", PriceDelta: 250, WeightDelta: 0.25, SKUSuffix: "-xxl"},
+	}
+	assert.Equal(t, expected, actual)
+
+	err = json.Unmarshal([]byte(`[12]`), &actual)
+	assert.NotNil(t, err, "values should be either strings or objects")
+}
+
+func TestProductOptionValueCreationInputMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	in := []ProductOptionValueCreationInput{
+		{Value: "S"},
+		{Value: "XXL", PriceDelta: 250, SKUSuffix: "-xxl"},
+	}
+	actual, err := json.Marshal(in)
+	assert.Nil(t, err)
+	assert.Equal(t, `["S",{"value":"XXL","price_delta":2.50,"weight_delta":0,"sku_suffix":"-xxl"}]`, string(actual), "values without modifiers should be plain strings")
+}
+
+func TestParseOptionValueIDsParam(t *testing.T) {
+	t.Parallel()
+	testCases := map[string][]uint64{
+		"":           nil,
+		"1":          {1},
+		"3,1, 2":     {3, 1, 2},
+		"256,256,12": {256, 12},
+	}
+	for rawIDs, expected := range testCases {
+		req, err := http.NewRequest(http.MethodGet, "/v1/product/skateboard/price?option_value_ids="+rawIDs, nil)
+		assert.Nil(t, err)
+		actual, err := parseOptionValueIDsParam(req)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "unexpected IDs parsed from `%s`", rawIDs)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product/skateboard/price?option_value_ids=1,two", nil)
+	assert.Nil(t, err)
+	_, err = parseOptionValueIDsParam(req)
+	assert.NotNil(t, err)
+}
+
+func TestCombineOptionValues(t *testing.T) {
+	t.Parallel()
+
+	values := []ProductOptionValue{
+		{DBRow: DBRow{ID: 1}, ProductOptionID: 10, Value: "red", SKUSuffix: "-red"},
+		{DBRow: DBRow{ID: 4}, ProductOptionID: 11, Value: "XXL", PriceDelta: 250, WeightDelta: 0.5, SKUSuffix: "-xxl"},
+	}
+	actual, err := combineOptionValues(values, []uint64{4, 1})
+	assert.Nil(t, err)
+	assert.Equal(t, &productVariant{SKUSuffix: "-red-xxl", PriceDelta: 250, WeightDelta: 0.5}, actual)
+
+	_, err = combineOptionValues(values, []uint64{4, 1, 7})
+	assert.NotNil(t, err, "values that weren't found shouldn't be ignored")
+
+	values = append(values, ProductOptionValue{DBRow: DBRow{ID: 5}, ProductOptionID: 11, Value: "S"})
+	_, err = combineOptionValues(values, []uint64{4, 1, 5})
+	assert.NotNil(t, err, "only one value per option should be allowed")
+}
+
+func TestProductOptionValueCreationHandlerWithModifiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	expected := &ProductOptionValue{
+		ProductOptionID: exampleProductOption.ID,
+		Value:           "XXL",
+		PriceDelta:      250,
+		WeightDelta:     0.5,
+		SKUSuffix:       "-xxl",
+	}
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueForOptionExistence(testUtil.Mock, exampleProductOption, expected, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductOptionValueCreation(testUtil.Mock, expected, nil)
+	testUtil.Mock.ExpectCommit()
+
+	body := `{"value": "XXL", "price_delta": 2.50, "weight_delta": 0.5, "sku_suffix": "-xxl"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product_options", "123", "value"), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &ProductOptionValue{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(250), actual.PriceDelta)
+	assert.Equal(t, "-xxl", actual.SKUSuffix)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueCreationHandlerWithInvalidSKUSuffix(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+
+	body := `{"value": "XXL", "sku_suffix": "/xxl"}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product_options", "123", "value"), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueUpdateHandlerWithOnlyModifiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	optionValueIDString := strconv.Itoa(int(exampleProductOptionValue.ID))
+	expected := *exampleProductOptionValue
+	expected.PriceDelta = 0
+	expected.SKUSuffix = "-xxl"
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, true, nil)
+	setExpectationsForProductOptionValueRetrieval(testUtil.Mock, exampleProductOptionValue, nil)
+	setExpectationsForProductOptionValueUpdate(testUtil.Mock, &expected, nil)
+
+	body := `{"price_delta": 0, "sku_suffix": "-xxl"}`
+	req, err := http.NewRequest(http.MethodPatch, buildRoute("v1", "product_option_values", optionValueIDString), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductOptionValue{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProductOptionValue.Value, actual.Value, "the value shouldn't change when it isn't provided")
+	assert.Equal(t, "-xxl", actual.SKUSuffix)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueUpdateHandlerWithInvalidSKUSuffix(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	optionValueIDString := strconv.Itoa(int(exampleProductOptionValue.ID))
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, true, nil)
+
+	req, err := http.NewRequest(http.MethodPatch, buildRoute("v1", "product_option_values", optionValueIDString), strings.NewReader(`{"sku_suffix": "x x l"}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func setExpectationsForProductOptionValueListForProduct(mock sqlmock.Sqlmock, productID uint64, valueIDs []uint64, values []ProductOptionValue, err error) {
+	exampleRows := sqlmock.NewRows(productOptionValueHeaders)
+	for _, v := range values {
+		exampleRows = exampleRows.AddRow(v.ID, v.ProductOptionID, v.Value, generateExampleTimeForTests(), nil, nil, v.PriceDelta.String(), v.WeightDelta, v.SKUSuffix, v.Position)
+	}
+	query, args := buildProductOptionValueListQueryForProduct(productID, valueIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductOptionValueIDsForOption(mock sqlmock.Sqlmock, optionID uint64, ids []uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		exampleRows = exampleRows.AddRow(id)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productOptionValueIDsForOptionQuery)).
+		WithArgs(optionID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductOptionValueListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values"), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductOptionValue{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual))
+	assert.Equal(t, exampleProductOptionValue.Value, actual[0].Value)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueListHandlerWithAcceptLanguage(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+	query, args := buildProductOptionValueTranslationListQueryForValues([]uint64{exampleProductOptionValue.ID}, []string{"es"})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionValueTranslationHeaders).
+			AddRow(1, exampleProductOptionValue.ID, "es", "otra cosa", generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values"), nil)
+	assert.Nil(t, err)
+	req.Header.Set("Accept-Language", "es")
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductOptionValue{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "otra cosa", actual[0].Value)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueListHandlerWithNonexistentOption(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, false, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values"), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueListHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values"), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueRetrievalHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, true, nil)
+	setExpectationsForProductOptionValueRetrieval(testUtil.Mock, exampleProductOptionValue, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_option_values", strconv.Itoa(int(exampleProductOptionValue.ID))), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductOptionValue{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProductOptionValue.ID, actual.ID)
+	assert.Equal(t, exampleProductOptionValue.Value, actual.Value)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueRetrievalHandlerWithNonexistentValue(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, false, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_option_values", strconv.Itoa(int(exampleProductOptionValue.ID))), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueRetrievalHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, true, nil)
+	setExpectationsForProductOptionValueRetrieval(testUtil.Mock, exampleProductOptionValue, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "product_option_values", strconv.Itoa(int(exampleProductOptionValue.ID))), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueReorderHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueIDsForOption(testUtil.Mock, exampleProductOption.ID, []uint64{256, 257, 258}, nil)
+	testUtil.Mock.ExpectBegin()
+	for position, id := range []uint64{258, 256, 257} {
+		testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionValuePositionUpdateQuery)).
+			WithArgs(position, id).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values", "order"), strings.NewReader(`{"ids": [258, 256]}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueReorderHandlerWithValueFromAnotherOption(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueIDsForOption(testUtil.Mock, exampleProductOption.ID, []uint64{256, 257}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values", "order"), strings.NewReader(`{"ids": [999]}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueReorderHandlerWithErrorUpdatingPositions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	setExpectationsForProductOptionValueIDsForOption(testUtil.Mock, exampleProductOption.ID, []uint64{256}, nil)
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionValuePositionUpdateQuery)).
+		WithArgs(0, 256).
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values", "order"), strings.NewReader(`{"ids": [256]}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueReorderHandlerForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product_options", strconv.Itoa(int(exampleProductOption.ID)), "values", "order"), strings.NewReader(`{"ids": [256]}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
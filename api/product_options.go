@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
@@ -18,13 +19,16 @@ const (
 		product_id,
 		created_on,
 		updated_on,
-		archived_on
+		archived_on,
+		position
 	`
 	productOptionExistenceQuery                 = `SELECT EXISTS(SELECT 1 FROM product_options WHERE id = $1 AND archived_on IS NULL)`
 	productOptionRetrievalQuery                 = `SELECT * FROM product_options WHERE id = $1`
 	productOptionExistenceQueryForProductByName = `SELECT EXISTS(SELECT 1 FROM product_options WHERE name = $1 AND product_id = $2 and archived_on IS NULL)`
 	productOptionDeletionQuery                  = `UPDATE product_options SET archived_on = NOW() WHERE id = $1 AND archived_on IS NULL`
 	productOptionValuesDeletionQueryByOptionID  = `UPDATE product_option_values SET archived_on = NOW() WHERE option_id = $1 AND archived_on IS NULL`
+	productOptionIDsForProductQuery             = `SELECT id FROM product_options WHERE product_id = $1 AND archived_on IS NULL ORDER BY position, id`
+	productOptionPositionUpdateQuery            = `UPDATE product_options SET position = $1, updated_on = NOW() WHERE id = $2`
 )
 
 // ProductOption represents a products variant options. If you have a t-shirt that comes in three colors
@@ -33,6 +37,7 @@ type ProductOption struct {
 	DBRow
 	Name      string               `json:"name"`
 	ProductID uint64               `json:"product_id"`
+	Position  uint32               `json:"position"`
 	Values    []ProductOptionValue `json:"values"`
 }
 
@@ -44,15 +49,10 @@ func (a *ProductOption) generateScanArgs() []interface{} {
 		&a.CreatedOn,
 		&a.UpdatedOn,
 		&a.ArchivedOn,
+		&a.Position,
 	}
 }
 
-func (a *ProductOption) generateScanArgsWithCount(count *uint64) []interface{} {
-	scanArgs := []interface{}{count}
-	optionScanArgs := a.generateScanArgs()
-	return append(scanArgs, optionScanArgs...)
-}
-
 // ProductOptionsResponse is a product option response struct
 type ProductOptionsResponse struct {
 	ListResponse
@@ -64,10 +64,68 @@ type ProductOptionUpdateInput struct {
 	Name string `json:"name"`
 }
 
+// ReorderInput is a struct to use for putting a product's options, or an option's values, in a new order.
+// Anything left out of IDs keeps its current order, after everything that was included.
+type ReorderInput struct {
+	IDs []uint64 `json:"ids" validate:"required,min=1"`
+}
+
+// reorderIDs puts the requested IDs first, followed by the rest of the existing IDs in the order they were already in
+func reorderIDs(existing []uint64, requested []uint64) ([]uint64, error) {
+	known := map[uint64]bool{}
+	for _, id := range existing {
+		known[id] = true
+	}
+
+	ordered := []uint64{}
+	placed := map[uint64]bool{}
+	for _, id := range requested {
+		if !known[id] {
+			return nil, fmt.Errorf("%d can't be reordered, since it isn't one of the rows being ordered", id)
+		}
+		if placed[id] {
+			return nil, fmt.Errorf("%d appears more than once", id)
+		}
+		placed[id] = true
+		ordered = append(ordered, id)
+	}
+	for _, id := range existing {
+		if !placed[id] {
+			ordered = append(ordered, id)
+		}
+	}
+	return ordered, nil
+}
+
+// updatePositionsInDB sets the position of every ID to its index with the given update query
+func updatePositionsInDB(db *sqlx.DB, positionUpdateQuery string, ids []uint64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for position, id := range ids {
+		if _, err = tx.Exec(positionUpdateQuery, position, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // ProductOptionCreationInput is a struct to use for creating product options
 type ProductOptionCreationInput struct {
-	Name   string   `json:"name"`
-	Values []string `json:"values"`
+	Name   string                            `json:"name"`
+	Values []ProductOptionValueCreationInput `json:"values"`
+}
+
+// validateProductOptionCreationInput checks the parts of a ProductOptionCreationInput that the validator can't
+func validateProductOptionCreationInput(in *ProductOptionCreationInput) error {
+	for _, v := range in.Values {
+		if err := validateSKUSuffix(v.SKUSuffix); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // FIXME: this function should be abstracted
@@ -97,6 +155,13 @@ func getProductOptionsForProduct(db *sqlx.DB, productID uint64, queryFilter *Que
 	var options []ProductOption
 	var count uint64
 
+	if !queryFilter.SkipCount {
+		query, args := buildProductOptionCountQuery(productID, queryFilter)
+		if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+			return nil, 0, errors.Wrap(err, "Error encountered counting product options")
+		}
+	}
+
 	query, args := buildProductOptionListQuery(productID, queryFilter)
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -106,12 +171,10 @@ func getProductOptionsForProduct(db *sqlx.DB, productID uint64, queryFilter *Que
 	defer rows.Close()
 	for rows.Next() {
 		var option ProductOption
-		var queryCount uint64
-
-		scanArgs := option.generateScanArgsWithCount(&queryCount)
-		_ = rows.Scan(scanArgs...)
+		if err = rows.Scan(option.generateScanArgs()...); err != nil {
+			return nil, 0, errors.Wrap(err, "Error scanning product option")
+		}
 
-		count = queryCount
 		optionValues, err := retrieveProductOptionValueForOptionFromDB(db, option.ID)
 		if err != nil {
 			return options, 0, errors.Wrap(err, "Error retrieving product option values for option")
@@ -123,11 +186,66 @@ func getProductOptionsForProduct(db *sqlx.DB, productID uint64, queryFilter *Que
 	return options, count, nil
 }
 
-func buildProductOptionListHandler(db *sqlx.DB) http.HandlerFunc {
+// retrieveProductOptionsForProducts retrieves the options (and their values) for a batch of products at once,
+// keyed by product ID, so that callers dealing with many products don't have to query for each one
+func retrieveProductOptionsForProducts(db *sqlx.DB, productIDs []uint64) (map[uint64][]ProductOption, error) {
+	out := map[uint64][]ProductOption{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+
+	query, args := buildProductOptionListQueryForProducts(productIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product options")
+	}
+	defer rows.Close()
+
+	var options []ProductOption
+	var optionIDs []uint64
+	for rows.Next() {
+		var option ProductOption
+		if err = rows.Scan(option.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product option")
+		}
+		options = append(options, option)
+		optionIDs = append(optionIDs, option.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	values, err := retrieveProductOptionValuesForOptions(db, optionIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving product option values for options")
+	}
+
+	for _, option := range options {
+		option.Values = values[option.ID]
+		out[option.ProductID] = append(out[option.ProductID], option)
+	}
+	return out, nil
+}
+
+func buildProductOptionListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		productID := chi.URLParam(req, "product_id")
 		rawFilterParams := req.URL.Query()
-		queryFilter := parseRawFilterParams(rawFilterParams)
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		archived, err := parseArchivedFilterParam(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if archived != "" && !sessionBelongsToAdmin(req, store) {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+		queryFilter.Archived = archived
 		productIDInt, _ := strconv.Atoi(productID)
 
 		options, count, err := getProductOptionsForProduct(db, uint64(productIDInt), queryFilter)
@@ -136,13 +254,18 @@ func buildProductOptionListHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		err = translateProductOptions(db, options, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option translations from the database")
+			return
+		}
+
 		optionsResponse := &ProductOptionsResponse{
-			ListResponse: ListResponse{
-				Page:  queryFilter.Page,
-				Limit: queryFilter.Limit,
-				Count: count,
-			},
-			Data: options,
+			ListResponse: newListResponse(queryFilter, count),
+			Data:         options,
+		}
+		if len(options) > 0 {
+			optionsResponse.NextCursor = buildNextCursor(queryFilter, len(options), options[len(options)-1].DBRow)
 		}
 		json.NewEncoder(res).Encode(optionsResponse)
 	}
@@ -215,7 +338,10 @@ func createProductOptionAndValuesInDBFromInput(tx *sql.Tx, in *ProductOptionCrea
 	for _, value := range in.Values {
 		newOptionValue := ProductOptionValue{
 			ProductOptionID: newProductOption.ID,
-			Value:           value,
+			Value:           value.Value,
+			PriceDelta:      value.PriceDelta,
+			WeightDelta:     value.WeightDelta,
+			SKUSuffix:       value.SKUSuffix,
 		}
 		newOptionValueID, err := createProductOptionValueInDB(tx, &newOptionValue)
 		if err != nil {
@@ -249,6 +375,10 @@ func buildProductOptionCreationHandler(db *sqlx.DB) http.HandlerFunc {
 			notifyOfInvalidRequestBody(res, err)
 			return
 		}
+		if err = validateProductOptionCreationInput(newOptionData); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
 
 		// can't create an option that already exists!
 		optionExists, err := productOptionAlreadyExistsForProduct(db, newOptionData, productID)
@@ -281,6 +411,53 @@ func buildProductOptionCreationHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
+func buildProductOptionReorderHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionReorderHandler is a request handler that changes the order a product's options are displayed in
+	return func(res http.ResponseWriter, req *http.Request) {
+		productID := chi.URLParam(req, "product_id")
+		// eating this error because Chi should validate this for us.
+		productIDInt, _ := strconv.ParseUint(productID, 10, 64)
+
+		productExists, err := rowExistsInDB(db, productExistenceQuery, productID)
+		if err != nil || !productExists {
+			respondThatRowDoesNotExist(req, res, "product", productID)
+			return
+		}
+
+		in := &ReorderInput{}
+		err = validateRequestInput(req, in)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		var existingIDs []uint64
+		err = db.Select(&existingIDs, productOptionIDsForProductQuery, productIDInt)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product options from the database")
+			return
+		}
+		orderedIDs, err := reorderIDs(existingIDs, in.IDs)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		err = updatePositionsInDB(db, productOptionPositionUpdateQuery, orderedIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update product option positions in the database")
+			return
+		}
+
+		options, err := retrieveProductOptionsForProducts(db, []uint64{productIDInt})
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product options from the database")
+			return
+		}
+		json.NewEncoder(res).Encode(options[productIDInt])
+	}
+}
+
 func archiveProductOption(db *sqlx.Tx, optionID uint64) error {
 	_, err := db.Exec(productOptionDeletionQuery, optionID)
 	return err
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -56,7 +57,7 @@ func init() {
 		Name:      "something else",
 		ProductID: exampleProductOption.ProductID,
 	}
-	productOptionHeaders = []string{"id", "name", "product_id", "created_on", "updated_on", "archived_on"}
+	productOptionHeaders = []string{"id", "name", "product_id", "created_on", "updated_on", "archived_on", "position"}
 
 	expectedCreatedProductOption = &ProductOption{
 		DBRow: DBRow{
@@ -89,7 +90,7 @@ func init() {
 
 	exampleProductOptionInput = &ProductOptionCreationInput{
 		Name:   "something",
-		Values: []string{"one", "two", "three"},
+		Values: []ProductOptionValueCreationInput{{Value: "one"}, {Value: "two"}, {Value: "three"}},
 	}
 }
 
@@ -114,7 +115,7 @@ func setExpectationsForProductOptionExistenceByName(mock sqlmock.Sqlmock, a *Pro
 
 func setExpectationsForProductOptionRetrievalQuery(mock sqlmock.Sqlmock, a *ProductOption, err error) {
 	exampleRows := sqlmock.NewRows(productOptionHeaders).
-		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil}...)
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...)
 	query := formatQueryForSQLMock(productOptionRetrievalQuery)
 	mock.ExpectQuery(query).
 		WithArgs(a.ID).
@@ -122,17 +123,33 @@ func setExpectationsForProductOptionRetrievalQuery(mock sqlmock.Sqlmock, a *Prod
 		WillReturnError(err)
 }
 
+func setExpectationsForProductOptionCount(mock sqlmock.Sqlmock, queryFilter *QueryFilter, count uint64) {
+	query, _ := buildProductOptionCountQuery(exampleProduct.ID, queryFilter)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
 func setExpectationsForProductOptionListQueryWithCount(mock sqlmock.Sqlmock, a *ProductOption, err error) {
-	exampleRows := sqlmock.NewRows([]string{"count", "id", "name", "product_id", "created_on", "updated_on", "archived_on"}).
-		AddRow([]driver.Value{3, a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil}...).
-		AddRow([]driver.Value{3, a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil}...).
-		AddRow([]driver.Value{3, a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil}...)
+	setExpectationsForProductOptionCount(mock, defaultQueryFilter, 3)
+	exampleRows := sqlmock.NewRows([]string{"id", "name", "product_id", "created_on", "updated_on", "archived_on", "position"}).
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...).
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...).
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...)
 	query, _ := buildProductOptionListQuery(exampleProduct.ID, defaultQueryFilter)
 	mock.ExpectQuery(formatQueryForSQLMock(query)).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
 }
 
+func setExpectationsForProductOptionListQueryForProducts(mock sqlmock.Sqlmock, productIDs []uint64, a *ProductOption, err error) {
+	exampleRows := sqlmock.NewRows(productOptionHeaders).
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...)
+	query, _ := buildProductOptionListQueryForProducts(productIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
 func setExpectationsForProductOptionCreation(mock sqlmock.Sqlmock, a *ProductOption, productID uint64, err error) {
 	exampleRows := sqlmock.NewRows([]string{"id"}).AddRow(exampleProductOption.ID)
 	query, args := buildProductOptionCreationQuery(a, productID)
@@ -145,7 +162,7 @@ func setExpectationsForProductOptionCreation(mock sqlmock.Sqlmock, a *ProductOpt
 
 func setExpectationsForProductOptionUpdate(mock sqlmock.Sqlmock, a *ProductOption, err error) {
 	exampleRows := sqlmock.NewRows(productOptionHeaders).
-		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil}...)
+		AddRow([]driver.Value{a.ID, a.Name, a.ProductID, generateExampleTimeForTests(), nil, nil, a.Position}...)
 	query, args := buildProductOptionUpdateQuery(a)
 	queryArgs := argsToDriverValues(args)
 	mock.ExpectQuery(formatQueryForSQLMock(query)).
@@ -168,6 +185,33 @@ func setExpectationsForProductOptionValuesDeletionByOptionID(mock sqlmock.Sqlmoc
 		WillReturnError(err)
 }
 
+func TestRetrieveProductOptionsForProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productIDs := []uint64{exampleProduct.ID}
+	setExpectationsForProductOptionListQueryForProducts(testUtil.Mock, productIDs, exampleProductOption, nil)
+	setExpectationsForProductOptionValueListQueryForOptions(testUtil.Mock, []uint64{exampleProductOption.ID}, exampleProductOptionValue, nil)
+
+	actual, err := retrieveProductOptionsForProducts(testUtil.DB, productIDs)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual[exampleProduct.ID]), "options should be keyed by the product they belong to")
+	assert.Equal(t, exampleProductOptionValue.Value, actual[exampleProduct.ID][0].Values[0].Value, "options should come with their values")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestRetrieveProductOptionsForProductsWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productIDs := []uint64{exampleProduct.ID}
+	setExpectationsForProductOptionListQueryForProducts(testUtil.Mock, productIDs, exampleProductOption, arbitraryError)
+
+	_, err := retrieveProductOptionsForProducts(testUtil.DB, productIDs)
+	assert.NotNil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestRetrieveProductOptionFromDB(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -342,7 +386,6 @@ func TestProductOptionListHandler(t *testing.T) {
 		ListResponse: ListResponse{
 			Page:  1,
 			Limit: 25,
-			Count: 3,
 		},
 	}
 
@@ -353,7 +396,43 @@ func TestProductOptionListHandler(t *testing.T) {
 
 	assert.Equal(t, expected.Page, actual.Page, "expected and actual product option pages should be equal")
 	assert.Equal(t, expected.Limit, actual.Limit, "expected and actual product option limits should be equal")
-	assert.Equal(t, expected.Count, actual.Count, "expected and actual product option counts should be equal")
+	assert.Equal(t, uint64(3), *actual.Count, "expected and actual product option counts should be equal")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionListHandlerWithArchivedOptions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	queryFilter := &QueryFilter{Page: 1, Limit: 25, Archived: archivedRowsIncluded}
+	setExpectationsForProductOptionCount(testUtil.Mock, queryFilter, 1)
+	exampleRows := sqlmock.NewRows([]string{"id", "name", "product_id", "created_on", "updated_on", "archived_on", "position"}).
+		AddRow(exampleProductOption.ID, exampleProductOption.Name, exampleProductOption.ProductID, generateExampleTimeForTests(), nil, generateExampleTimeForTests(), exampleProductOption.Position)
+	query, _ := buildProductOptionListQuery(exampleProduct.ID, queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+
+	productOptionEndpoint := buildRoute("v1", "product", strconv.Itoa(int(exampleProduct.ID)), "options")
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?archived=include", productOptionEndpoint), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionListHandlerWithArchivedOptionsForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productOptionEndpoint := buildRoute("v1", "product", strconv.Itoa(int(exampleProduct.ID)), "options")
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?archived=only", productOptionEndpoint), nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
@@ -375,6 +454,33 @@ func TestProductOptionListHandlerWithErrorsRetrievingValues(t *testing.T) {
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
+func TestProductOptionListHandlerWithCursor(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// cursor pagination doesn't count options unless it's asked to
+	queryFilter := &QueryFilter{Page: 1, Limit: 25, UseCursor: true, SkipCount: true}
+	exampleRows := sqlmock.NewRows([]string{"id", "name", "product_id", "created_on", "updated_on", "archived_on", "position"}).
+		AddRow(exampleProductOption.ID, exampleProductOption.Name, exampleProductOption.ProductID, generateExampleTimeForTests(), nil, nil, exampleProductOption.Position)
+	query, _ := buildProductOptionListQuery(exampleProduct.ID, queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+
+	productOptionEndpoint := buildRoute("v1", "product", strconv.Itoa(int(exampleProduct.ID)), "options")
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s?cursor=", productOptionEndpoint), nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductOptionsResponse{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Nil(t, actual.Count, "cursor pagination shouldn't count options unless asked to")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestProductOptionListHandlerWithDBErrors(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -751,3 +857,95 @@ func TestProductOptionDeletionHandlerWithErrorCommittingTransaction(t *testing.T
 	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
+
+func TestProductOptionCreationHandlerWithInvalidSKUSuffix(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productIDString := strconv.Itoa(int(exampleProductOption.ProductID))
+	setExpectationsForProductExistenceByID(testUtil.Mock, productIDString, true, nil)
+
+	body := `{"name": "size", "values": ["S", {"value": "XXL", "sku_suffix": "-xx/l"}]}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "product", productIDString, "options"), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReorderIDs(t *testing.T) {
+	t.Parallel()
+
+	actual, err := reorderIDs([]uint64{1, 2, 3, 4}, []uint64{3, 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{3, 1, 2, 4}, actual, "IDs left out should keep their order after the ones that were included")
+
+	_, err = reorderIDs([]uint64{1, 2}, []uint64{3})
+	assert.NotNil(t, err, "IDs that aren't being ordered should be rejected")
+
+	_, err = reorderIDs([]uint64{1, 2}, []uint64{2, 2})
+	assert.NotNil(t, err, "IDs shouldn't be allowed to appear more than once")
+}
+
+func TestProductOptionReorderHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productID := strconv.Itoa(int(exampleProduct.ID))
+	setExpectationsForProductExistenceByID(testUtil.Mock, productID, true, nil)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productOptionIDsForProductQuery)).
+		WithArgs(exampleProduct.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(122).AddRow(exampleProductOption.ID))
+	testUtil.Mock.ExpectBegin()
+	for position, id := range []uint64{exampleProductOption.ID, 122} {
+		testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionPositionUpdateQuery)).
+			WithArgs(position, id).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductOptionListQueryForProducts(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductOption, nil)
+	setExpectationsForProductOptionValueListQueryForOptions(testUtil.Mock, []uint64{exampleProductOption.ID}, exampleProductOptionValue, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d]}`, exampleProductOption.ID)
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", productID, "options", "order"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductOption{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProductOption.ID, actual[0].ID)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionReorderHandlerWithNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productID := strconv.Itoa(int(exampleProduct.ID))
+	setExpectationsForProductExistenceByID(testUtil.Mock, productID, false, nil)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", productID, "options", "order"), strings.NewReader(`{"ids": [1]}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionReorderHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	productID := strconv.Itoa(int(exampleProduct.ID))
+	setExpectationsForProductExistenceByID(testUtil.Mock, productID, true, nil)
+
+	req, err := http.NewRequest(http.MethodPut, buildRoute("v1", "product", productID, "options", "order"), strings.NewReader(`{"ids": []}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	productPriceTiersTableHeaders = `id,
+		product_id,
+		min_quantity,
+		unit_price,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	productPriceTierDeletionQuery = `UPDATE product_price_tiers SET archived_on = NOW() WHERE product_id = $1 AND min_quantity = $2 AND archived_on IS NULL`
+)
+
+// ProductPriceTier is a unit price that applies when at least MinQuantity of a product is bought at once
+type ProductPriceTier struct {
+	DBRow
+	ProductID   uint64 `json:"product_id"`
+	MinQuantity uint32 `json:"min_quantity"`
+	UnitPrice   Money  `json:"unit_price"`
+}
+
+func (t *ProductPriceTier) generateScanArgs() []interface{} {
+	return []interface{}{
+		&t.ID,
+		&t.ProductID,
+		&t.MinQuantity,
+		&t.UnitPrice,
+		&t.CreatedOn,
+		&t.UpdatedOn,
+		&t.ArchivedOn,
+	}
+}
+
+// ProductPriceTierInput is a struct to use for setting the unit price of a tier
+type ProductPriceTierInput struct {
+	UnitPrice Money `json:"unit_price" validate:"required"`
+}
+
+// ProductPriceQuote is the response to a request for the price of some quantity of a product
+type ProductPriceQuote struct {
+	SKU       string `json:"sku"`
+	Quantity  uint32 `json:"quantity"`
+	UnitPrice Money  `json:"unit_price"`
+	Total     Money  `json:"total"`
+	Currency  string `json:"currency,omitempty"`
+	// TierMinQuantity is the min_quantity of the tier that set the unit price, if one did
+	TierMinQuantity uint32 `json:"tier_min_quantity,omitempty"`
+
+	// OptionValueIDs are the option values the price was resolved for, whose price deltas are
+	// included in the unit price. VariantSKU is the SKU with their suffixes appended.
+	OptionValueIDs []uint64 `json:"option_value_ids,omitempty"`
+	VariantSKU     string   `json:"variant_sku,omitempty"`
+	// ShippingWeight is the weight of one unit's package, including any option value weight deltas
+	ShippingWeight float32 `json:"shipping_weight"`
+}
+
+// retrieveProductPriceTiers retrieves the price tiers for a batch of products, keyed by product ID and ordered by min_quantity
+func retrieveProductPriceTiers(db *sqlx.DB, productIDs []uint64) (map[uint64][]ProductPriceTier, error) {
+	out := map[uint64][]ProductPriceTier{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+
+	query, args := buildProductPriceTierListQuery(productIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product price tiers")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t ProductPriceTier
+		err = rows.Scan(t.generateScanArgs()...)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product price tier")
+		}
+		out[t.ProductID] = append(out[t.ProductID], t)
+	}
+	return out, rows.Err()
+}
+
+// attachPriceTiersToProducts fills in the tier table of the given products
+func attachPriceTiersToProducts(db *sqlx.DB, products []Product) error {
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	tiers, err := retrieveProductPriceTiers(db, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].PriceTiers = tiers[products[i].ID]
+	}
+	return nil
+}
+
+// resolveUnitPrice determines what one unit of a product costs when buying the given quantity.
+// The product's tiers must already be attached. A tier only wins if it's cheaper than the price
+// the product would otherwise be sold at, so a sale is never made worse by buying in bulk.
+func resolveUnitPrice(p *Product, quantity uint32) (Money, *ProductPriceTier) {
+	unitPrice := effectiveUnitPrice(p.Price, p.OnSale, p.SalePrice)
+
+	var applied *ProductPriceTier
+	for i := range p.PriceTiers {
+		t := &p.PriceTiers[i]
+		if t.MinQuantity > quantity {
+			continue
+		}
+		if applied == nil || t.MinQuantity > applied.MinQuantity {
+			applied = t
+		}
+	}
+
+	if applied == nil || applied.UnitPrice >= unitPrice {
+		return unitPrice, nil
+	}
+	return applied.UnitPrice, applied
+}
+
+func parseQuantityParam(req *http.Request) (uint32, error) {
+	rawQuantity := req.URL.Query().Get("quantity")
+	if rawQuantity == "" {
+		return 1, nil
+	}
+	quantity, err := strconv.ParseUint(rawQuantity, 10, 32)
+	if err != nil || quantity == 0 {
+		return 0, fmt.Errorf("invalid quantity: `%s`", rawQuantity)
+	}
+	return uint32(quantity), nil
+}
+
+func buildProductPriceTierListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductPriceTierListHandler is a request handler that returns a product's price tiers
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		tiers, err := retrieveProductPriceTiers(db, []uint64{productID})
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product price tiers from the database")
+			return
+		}
+
+		out := tiers[productID]
+		if out == nil {
+			out = []ProductPriceTier{}
+		}
+		json.NewEncoder(res).Encode(out)
+	}
+}
+
+func buildProductPriceTierUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductPriceTierUpsertHandler is a request handler that sets the unit price for a product's quantity tier
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		// we can eat this error because Mux takes care of validating route params for us
+		minQuantity, _ := strconv.ParseUint(chi.URLParam(req, "min_quantity"), 10, 32)
+		if minQuantity == 0 {
+			notifyOfInvalidRequestBody(res, errors.New("min_quantity must be at least 1"))
+			return
+		}
+
+		tierInput := &ProductPriceTierInput{}
+		err := validateRequestInput(req, tierInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		tier := &ProductPriceTier{
+			ProductID:   productID,
+			MinQuantity: uint32(minQuantity),
+			UnitPrice:   tierInput.UnitPrice,
+		}
+		query, args := buildProductPriceTierUpsertQuery(tier)
+		err = db.QueryRow(query, args...).Scan(tier.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product price tier in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(tier)
+	}
+}
+
+func buildProductPriceTierDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductPriceTierDeletionHandler is a request handler that removes one of a product's price tiers
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		minQuantity := chi.URLParam(req, "min_quantity")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		result, err := db.Exec(productPriceTierDeletionQuery, productID, minQuantity)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product price tier")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product price tier", minQuantity)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildProductPriceResolutionHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductPriceResolutionHandler is a request handler that returns the effective unit price of a product at a given quantity,
+	// with any chosen option values
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		quantity, err := parseQuantityParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		currency, err := parseCurrencyParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		optionValueIDs, err := parseOptionValueIDsParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		product, err := retrieveProductForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieving product from database")
+			return
+		}
+
+		optionValues, err := retrieveProductOptionValuesForProduct(db, product.ID, optionValueIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option values from the database")
+			return
+		}
+		variant, err := combineOptionValues(optionValues, optionValueIDs)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		products := []Product{product}
+		err = attachPriceTiersToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product price tiers from the database")
+			return
+		}
+
+		err = attachBundlesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundles from the database")
+			return
+		}
+
+		err = applyCustomerGroupPrices(db, store, req, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group prices from the database")
+			return
+		}
+
+		err = convertProductPrices(db, products, currency)
+		if err != nil {
+			notifyOfCurrencyConversionFailure(res, err)
+			return
+		}
+
+		// price deltas are in the base currency like everything else, so they need converting too
+		priceDelta := variant.PriceDelta
+		if currency != "" && priceDelta != 0 {
+			rate, err := retrieveExchangeRate(db, currency)
+			if err != nil {
+				notifyOfCurrencyConversionFailure(res, err)
+				return
+			}
+			priceDelta = convertMoney(priceDelta, rate, currency)
+		}
+
+		unitPrice, tier := resolveUnitPrice(&products[0], quantity)
+		unitPrice = unitPrice.Add(priceDelta)
+		quote := &ProductPriceQuote{
+			SKU:            sku,
+			Quantity:       quantity,
+			UnitPrice:      unitPrice,
+			Total:          unitPrice.Times(int64(quantity)),
+			Currency:       products[0].Currency,
+			ShippingWeight: products[0].PackageWeight + variant.WeightDelta,
+		}
+		if tier != nil {
+			quote.TierMinQuantity = tier.MinQuantity
+		}
+		if len(optionValueIDs) > 0 {
+			quote.OptionValueIDs = optionValueIDs
+			quote.VariantSKU = sku + variant.SKUSuffix
+		}
+		json.NewEncoder(res).Encode(quote)
+	}
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var productPriceTierHeaders = strings.Split(strings.TrimSpace(productPriceTiersTableHeaders), ",\n\t\t")
+
+func setExpectationsForProductPriceTierList(mock sqlmock.Sqlmock, productIDs []uint64, tiers []ProductPriceTier, err error) {
+	exampleRows := sqlmock.NewRows(productPriceTierHeaders)
+	for _, t := range tiers {
+		exampleRows = exampleRows.AddRow(t.ID, t.ProductID, t.MinQuantity, t.UnitPrice.String(), generateExampleTimeForTests(), nil, nil)
+	}
+	query, _ := buildProductPriceTierListQuery(productIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductPriceTierUpsert(mock sqlmock.Sqlmock, t *ProductPriceTier, err error) {
+	exampleRows := sqlmock.NewRows(productPriceTierHeaders).
+		AddRow(1, t.ProductID, t.MinQuantity, t.UnitPrice.String(), generateExampleTimeForTests(), nil, nil)
+	query, args := buildProductPriceTierUpsertQuery(t)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func exampleProductPriceTiers(productID uint64) []ProductPriceTier {
+	return []ProductPriceTier{
+		{DBRow: DBRow{ID: 1}, ProductID: productID, MinQuantity: 10, UnitPrice: 8999},
+		{DBRow: DBRow{ID: 2}, ProductID: productID, MinQuantity: 50, UnitPrice: 7999},
+	}
+}
+
+func TestResolveUnitPrice(t *testing.T) {
+	t.Parallel()
+	p := &Product{Price: 9999, SalePrice: 8500, PriceTiers: exampleProductPriceTiers(1)}
+
+	testCases := []struct {
+		onSale          bool
+		quantity        uint32
+		expectedPrice   Money
+		expectedMinimum uint32
+	}{
+		{onSale: false, quantity: 1, expectedPrice: 9999},
+		{onSale: false, quantity: 10, expectedPrice: 8999, expectedMinimum: 10},
+		{onSale: false, quantity: 49, expectedPrice: 8999, expectedMinimum: 10},
+		{onSale: false, quantity: 500, expectedPrice: 7999, expectedMinimum: 50},
+		{onSale: true, quantity: 1, expectedPrice: 8500},
+		{onSale: true, quantity: 10, expectedPrice: 8500},
+		{onSale: true, quantity: 50, expectedPrice: 7999, expectedMinimum: 50},
+	}
+
+	for _, tc := range testCases {
+		p.OnSale = tc.onSale
+		actualPrice, tier := resolveUnitPrice(p, tc.quantity)
+		assert.Equal(t, tc.expectedPrice, actualPrice, "unit price for %d (on sale: %v) should be %s", tc.quantity, tc.onSale, tc.expectedPrice)
+		if tc.expectedMinimum == 0 {
+			assert.Nil(t, tier, "no tier should apply for %d (on sale: %v)", tc.quantity, tc.onSale)
+		} else {
+			assert.Equal(t, tc.expectedMinimum, tier.MinQuantity, "the %d tier should apply for %d (on sale: %v)", tc.expectedMinimum, tc.quantity, tc.onSale)
+		}
+	}
+}
+
+func TestParseQuantityParam(t *testing.T) {
+	t.Parallel()
+	inputOutputMap := map[string]uint32{
+		"":   1,
+		"1":  1,
+		"25": 25,
+	}
+	for input, expected := range inputOutputMap {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/example/price?quantity=%s", input), nil)
+		assert.Nil(t, err)
+		actual, err := parseQuantityParam(req)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	for _, input := range []string{"0", "-1", "lots"} {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/example/price?quantity=%s", input), nil)
+		assert.Nil(t, err)
+		_, err = parseQuantityParam(req)
+		assert.NotNil(t, err, "`%s` shouldn't be a valid quantity", input)
+	}
+}
+
+func TestProductRetrievalHandlerIncludesPriceTiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual.PriceTiers), "product should include its price tiers")
+	assert.Equal(t, Money(8999), actual.PriceTiers[0].UnitPrice)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithErrorRetrievingPriceTiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price_tiers", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductPriceTier{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierListHandlerWithoutTiers(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price_tiers", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	assert.Equal(t, "[]\n", testUtil.Response.Body.String(), "products without tiers should respond with an empty list")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceTierUpsert(testUtil.Mock, &ProductPriceTier{ProductID: exampleProduct.ID, MinQuantity: 10, UnitPrice: 8999}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/price_tiers/10", exampleProduct.SKU), strings.NewReader(`{"unit_price": 89.99}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierUpsertHandlerWithZeroMinQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/price_tiers/0", exampleProduct.SKU), strings.NewReader(`{"unit_price": 89.99}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierUpsertHandlerForNonAdminUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/price_tiers/10", exampleProduct.SKU), strings.NewReader(`{"unit_price": 89.99}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierUpsertHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceTierUpsert(testUtil.Mock, &ProductPriceTier{ProductID: exampleProduct.ID, MinQuantity: 10, UnitPrice: 8999}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/price_tiers/10", exampleProduct.SKU), strings.NewReader(`{"unit_price": 89.99}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productPriceTierDeletionQuery)).
+		WithArgs(exampleProduct.ID, "10").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/price_tiers/10", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceTierDeletionHandlerForNonexistentTier(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productPriceTierDeletionQuery)).
+		WithArgs(exampleProduct.ID, "10").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/price_tiers/10", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?quantity=12", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	expected := &ProductPriceQuote{
+		SKU:             exampleProduct.SKU,
+		Quantity:        12,
+		UnitPrice:       8999,
+		Total:           107988,
+		TierMinQuantity: 10,
+		ShippingWeight:  exampleProduct.PackageWeight,
+	}
+	actual := &ProductPriceQuote{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "EUR", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.50000000", nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?quantity=50&currency=EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductPriceQuote{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(4000), actual.UnitPrice, "tier prices should be converted too")
+	assert.Equal(t, "EUR", actual.Currency)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithInvalidQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?quantity=0", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithOptionValues(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	values := []ProductOptionValue{
+		{DBRow: DBRow{ID: 1}, ProductOptionID: 10, Value: "red", SKUSuffix: "-red"},
+		{DBRow: DBRow{ID: 4}, ProductOptionID: 11, Value: "XXL", PriceDelta: 250, WeightDelta: 0.5, SKUSuffix: "-xxl"},
+	}
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{4, 1}, values, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?quantity=12&option_value_ids=4,1", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	expected := &ProductPriceQuote{
+		SKU:             exampleProduct.SKU,
+		Quantity:        12,
+		UnitPrice:       9249,
+		Total:           110988,
+		TierMinQuantity: 10,
+		OptionValueIDs:  []uint64{4, 1},
+		VariantSKU:      "skateboard-red-xxl",
+		ShippingWeight:  exampleProduct.PackageWeight + 0.5,
+	}
+	actual := &ProductPriceQuote{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithOptionValuesAndCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	values := []ProductOptionValue{{DBRow: DBRow{ID: 4}, ProductOptionID: 11, Value: "XXL", PriceDelta: 250}}
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{4}, values, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, exampleProductPriceTiers(exampleProduct.ID), nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "EUR", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.50000000", nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.50000000", nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?quantity=50&currency=EUR&option_value_ids=4", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductPriceQuote{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(4125), actual.UnitPrice, "price deltas should be converted too")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithInvalidOptionValueIDs(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?option_value_ids=red", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithAnotherProductsOptionValue(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{999}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?option_value_ids=999", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceResolutionHandlerWithErrorRetrievingOptionValues(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductOptionValueListForProduct(testUtil.Mock, exampleProduct.ID, []uint64{4}, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/price?option_value_ids=4", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
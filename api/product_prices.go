@@ -0,0 +1,188 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	productPricesTableHeaders = `id,
+		product_id,
+		currency,
+		price,
+		sale_price,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	productPriceDeletionQuery = `UPDATE product_prices SET archived_on = NOW() WHERE product_id = $1 AND currency = $2 AND archived_on IS NULL`
+)
+
+// ProductPrice is an explicit price for a product in a currency other than the base currency,
+// for when a converted price won't do (like when you want a nice round €20 instead of €18.37)
+type ProductPrice struct {
+	DBRow
+	ProductID uint64 `json:"product_id"`
+	Currency  string `json:"currency"`
+	Price     Money  `json:"price"`
+	SalePrice Money  `json:"sale_price"`
+}
+
+func (pp *ProductPrice) generateScanArgs() []interface{} {
+	return []interface{}{
+		&pp.ID,
+		&pp.ProductID,
+		&pp.Currency,
+		&pp.Price,
+		&pp.SalePrice,
+		&pp.CreatedOn,
+		&pp.UpdatedOn,
+		&pp.ArchivedOn,
+	}
+}
+
+// ProductPriceInput is a struct to use for setting a product's price in a given currency
+type ProductPriceInput struct {
+	Price     Money `json:"price" validate:"required"`
+	SalePrice Money `json:"sale_price"`
+}
+
+func retrieveProductPrices(db *sqlx.DB, productIDs []uint64, currency string) ([]ProductPrice, error) {
+	prices := []ProductPrice{}
+	query, args := buildProductPriceListQuery(productIDs, currency)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product prices")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pp ProductPrice
+		err = rows.Scan(pp.generateScanArgs()...)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product price")
+		}
+		prices = append(prices, pp)
+	}
+	return prices, rows.Err()
+}
+
+// retrieveProductPricesForProducts retrieves the explicit prices a batch of products have in a currency, keyed by product ID
+func retrieveProductPricesForProducts(db *sqlx.DB, productIDs []uint64, currency string) (map[uint64]ProductPrice, error) {
+	prices, err := retrieveProductPrices(db, productIDs, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[uint64]ProductPrice{}
+	for _, pp := range prices {
+		out[pp.ProductID] = pp
+	}
+	return out, nil
+}
+
+func buildProductPriceListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductPriceListHandler is a request handler that returns every explicit price a product has
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		prices, err := retrieveProductPrices(db, []uint64{productID}, "")
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product prices from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(prices)
+	}
+}
+
+func buildProductPriceUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductPriceUpsertHandler is a request handler that sets a product's price in a given currency
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		currency := strings.ToUpper(chi.URLParam(req, "currency"))
+		if !currencyIsSupported(currency) || currency == baseCurrency {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("cannot set an explicit price in currency `%s`", currency))
+			return
+		}
+
+		priceInput := &ProductPriceInput{}
+		err := validateRequestInput(req, priceInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		price := &ProductPrice{
+			ProductID: productID,
+			Currency:  currency,
+			Price:     priceInput.Price,
+			SalePrice: priceInput.SalePrice,
+		}
+		query, args := buildProductPriceUpsertQuery(price)
+		err = db.QueryRow(query, args...).Scan(price.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product price in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(price)
+	}
+}
+
+func buildProductPriceDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductPriceDeletionHandler is a request handler that removes a product's explicit price in a currency,
+	// after which prices in that currency are converted from the base currency again
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		currency := strings.ToUpper(chi.URLParam(req, "currency"))
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		result, err := db.Exec(productPriceDeletionQuery, productID, currency)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product price")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product price", currency)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
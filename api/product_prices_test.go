@@ -0,0 +1,188 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var productPriceHeaders = strings.Split(strings.TrimSpace(productPricesTableHeaders), ",\n\t\t")
+
+// setExpectationsForProductPriceList expects a query for the given products' prices, returning pp if it isn't nil
+func setExpectationsForProductPriceList(mock sqlmock.Sqlmock, productIDs []uint64, currency string, pp *ProductPrice, err error) {
+	exampleRows := sqlmock.NewRows(productPriceHeaders)
+	if pp != nil {
+		exampleRows = exampleRows.AddRow(1, pp.ProductID, pp.Currency, pp.Price.String(), pp.SalePrice.String(), generateExampleTimeForTests(), nil, nil)
+	}
+	query, _ := buildProductPriceListQuery(productIDs, currency)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductPriceUpsert(mock sqlmock.Sqlmock, pp *ProductPrice, err error) {
+	exampleRows := sqlmock.NewRows(productPriceHeaders).
+		AddRow(1, pp.ProductID, pp.Currency, pp.Price.String(), pp.SalePrice.String(), generateExampleTimeForTests(), nil, nil)
+	query, args := buildProductPriceUpsertQuery(pp)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductPriceListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "", &ProductPrice{ProductID: exampleProduct.ID, Currency: "EUR", Price: 8000}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/prices", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductPrice{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, Money(8000), actual[0].Price)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceListHandlerForAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// admins can see the prices of products that haven't been published yet
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, []uint64{exampleProduct.ID}, "", &ProductPrice{ProductID: exampleProduct.ID, Currency: "EUR", Price: 8000}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/prices", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceListHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/prices", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceUpsert(testUtil.Mock, &ProductPrice{ProductID: exampleProduct.ID, Currency: "EUR", Price: 8000}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/prices/eur", exampleProduct.SKU), strings.NewReader(`{"price": 80}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceUpsertHandlerInBaseCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/prices/%s", exampleProduct.SKU, baseCurrency), strings.NewReader(`{"price": 80}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceUpsertHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/prices/EUR", exampleProduct.SKU), strings.NewReader(`{"price": 80.001}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceUpsertHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductPriceUpsert(testUtil.Mock, &ProductPrice{ProductID: exampleProduct.ID, Currency: "EUR", Price: 8000}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/prices/EUR", exampleProduct.SKU), strings.NewReader(`{"price": 80}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productPriceDeletionQuery)).
+		WithArgs(exampleProduct.ID, "EUR").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/prices/EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceDeletionHandlerForNonexistentPrice(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productPriceDeletionQuery)).
+		WithArgs(exampleProduct.ID, "EUR").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/prices/EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductPriceDeletionHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, sql.ErrConnDone)
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/prices/EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	productRelationsTableHeaders = `id,
+		product_id,
+		related_product_id,
+		kind,
+		position,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	productRelationDeletionQuery = `UPDATE product_relations SET archived_on = NOW() WHERE product_id = $1 AND related_product_id = $2 AND kind = $3 AND archived_on IS NULL`
+)
+
+// productRelationKinds are the ways one product can be related to another
+var productRelationKinds = []string{"related", "upsell", "cross-sell", "accessory"}
+
+// ProductRelation links a product to another one that should be shown alongside it
+type ProductRelation struct {
+	DBRow
+	ProductID        uint64 `json:"product_id"`
+	RelatedProductID uint64 `json:"related_product_id"`
+	RelatedSKU       string `json:"related_sku"`
+	Kind             string `json:"kind"`
+	Position         uint32 `json:"position"`
+
+	// Product is the related product itself, which is only included when asked for
+	Product *Product `json:"product,omitempty"`
+}
+
+func (r *ProductRelation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&r.ID,
+		&r.ProductID,
+		&r.RelatedProductID,
+		&r.Kind,
+		&r.Position,
+		&r.CreatedOn,
+		&r.UpdatedOn,
+		&r.ArchivedOn,
+	}
+}
+
+// ProductRelationInput is a struct to use for relating one product to another
+type ProductRelationInput struct {
+	SKU      string `json:"sku" validate:"required"`
+	Kind     string `json:"kind" validate:"required"`
+	Position uint32 `json:"position"`
+}
+
+func productRelationKindIsValid(kind string) bool {
+	for _, k := range productRelationKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// includeRequested reports whether the request asked for something extra to be embedded in the response with `include`
+func includeRequested(req *http.Request, thing string) bool {
+	for _, included := range strings.Split(req.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(included) == thing {
+			return true
+		}
+	}
+	return false
+}
+
+// retrieveProductRelations retrieves a product's relations to products that haven't been archived, ordered by kind and then position
+func retrieveProductRelations(db *sqlx.DB, productID uint64) ([]ProductRelation, error) {
+	query, args := buildProductRelationListQuery(productID)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product relations")
+	}
+	defer rows.Close()
+
+	relations := []ProductRelation{}
+	for rows.Next() {
+		var r ProductRelation
+		err = rows.Scan(&r.ID, &r.ProductID, &r.RelatedProductID, &r.RelatedSKU, &r.Kind, &r.Position, &r.CreatedOn, &r.UpdatedOn, &r.ArchivedOn)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product relation")
+		}
+		relations = append(relations, r)
+	}
+	return relations, rows.Err()
+}
+
+// retrieveRelatedProducts retrieves the products on the other end of the given relations
+func retrieveRelatedProducts(db *sqlx.DB, relations []ProductRelation, publishedOnly bool) ([]Product, error) {
+	related := []Product{}
+	if len(relations) == 0 {
+		return related, nil
+	}
+
+	productIDs := []uint64{}
+	for _, r := range relations {
+		productIDs = append(productIDs, r.RelatedProductID)
+	}
+	query, args := buildProductListQueryByIDs(productIDs, publishedOnly)
+	err := retrieveListOfRowsFromDB(db, query, args, &related)
+	return related, err
+}
+
+// attachRelationsToProduct embeds the related products in their relations, and the relations in the product
+func attachRelationsToProduct(p *Product, relations []ProductRelation, related []Product) {
+	relatedByID := map[uint64]*Product{}
+	for i := range related {
+		relatedByID[related[i].ID] = &related[i]
+	}
+	// relations to products the caller isn't allowed to see are left out
+	visible := []ProductRelation{}
+	for _, r := range relations {
+		if r.Product = relatedByID[r.RelatedProductID]; r.Product != nil {
+			visible = append(visible, r)
+		}
+	}
+	p.Relations = visible
+}
+
+func buildProductRelationListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductRelationListHandler is a request handler that returns the products related to a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		relations, err := retrieveProductRelations(db, productID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product relations from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(relations)
+	}
+}
+
+func buildProductRelationUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductRelationUpsertHandler is a request handler that relates one product to another, or
+	// moves an existing relation to a new position
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		relationInput := &ProductRelationInput{}
+		err := validateRequestInput(req, relationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if !productRelationKindIsValid(relationInput.Kind) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("invalid relation kind: `%s`", relationInput.Kind))
+			return
+		}
+		if relationInput.SKU == sku {
+			notifyOfInvalidRequestBody(res, errors.New("a product cannot be related to itself"))
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		relatedProductID, err := retrieveProductIDBySKU(db, relationInput.SKU)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", relationInput.SKU)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve related product from the database")
+			return
+		}
+
+		relation := &ProductRelation{
+			ProductID:        productID,
+			RelatedProductID: relatedProductID,
+			Kind:             relationInput.Kind,
+			Position:         relationInput.Position,
+		}
+		query, args := buildProductRelationUpsertQuery(relation)
+		err = db.QueryRow(query, args...).Scan(relation.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product relation in database")
+			return
+		}
+		relation.RelatedSKU = relationInput.SKU
+
+		json.NewEncoder(res).Encode(relation)
+	}
+}
+
+func buildProductRelationDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductRelationDeletionHandler is a request handler that removes a relation between two products
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		kind := chi.URLParam(req, "kind")
+		relatedSKU := chi.URLParam(req, "related_sku")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		relatedProductID, err := retrieveProductIDBySKU(db, relatedSKU)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product relation", relatedSKU)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve related product from the database")
+			return
+		}
+
+		result, err := db.Exec(productRelationDeletionQuery, productID, relatedProductID, kind)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product relation")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product relation", relatedSKU)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const exampleRelatedProductID = 4
+
+var productRelationHeaders = strings.Split(strings.TrimSpace(productRelationsTableHeaders), ",\n\t\t")
+
+func exampleProductRelation() ProductRelation {
+	return ProductRelation{
+		DBRow:            DBRow{ID: 1, CreatedOn: generateExampleTimeForTests()},
+		ProductID:        exampleProduct.ID,
+		RelatedProductID: exampleRelatedProductID,
+		RelatedSKU:       "helmet",
+		Kind:             "accessory",
+		Position:         1,
+	}
+}
+
+func setExpectationsForProductRelationList(mock sqlmock.Sqlmock, productID uint64, relations []ProductRelation, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id", "product_id", "related_product_id", "sku", "kind", "position", "created_on", "updated_on", "archived_on"})
+	for _, r := range relations {
+		exampleRows = exampleRows.AddRow(r.ID, r.ProductID, r.RelatedProductID, r.RelatedSKU, r.Kind, r.Position, r.CreatedOn, nil, nil)
+	}
+	query, args := buildProductRelationListQuery(productID)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForRelatedProductList(mock sqlmock.Sqlmock, productIDs []uint64, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders)
+	for _, id := range productIDs {
+		data := make([]driver.Value, len(exampleProductData))
+		copy(data, exampleProductData)
+		for i, header := range productHeaders {
+			switch header {
+			case "id":
+				data[i] = id
+			case "sku":
+				data[i] = fmt.Sprintf("related-%d", id)
+			}
+		}
+		exampleRows = exampleRows.AddRow(data...)
+	}
+	query, _ := buildProductListQueryByIDs(productIDs, true)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductRelationUpsert(mock sqlmock.Sqlmock, r *ProductRelation, err error) {
+	exampleRows := sqlmock.NewRows(productRelationHeaders).
+		AddRow(1, r.ProductID, r.RelatedProductID, r.Kind, r.Position, generateExampleTimeForTests(), nil, nil)
+	query, args := buildProductRelationUpsertQuery(r)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestProductRelationKindIsValid(t *testing.T) {
+	t.Parallel()
+	for _, kind := range []string{"related", "upsell", "cross-sell", "accessory"} {
+		assert.True(t, productRelationKindIsValid(kind), "`%s` should be a valid relation kind", kind)
+	}
+	for _, kind := range []string{"", "cross_sell", "downsell"} {
+		assert.False(t, productRelationKindIsValid(kind), "`%s` should not be a valid relation kind", kind)
+	}
+}
+
+func TestIncludeRequested(t *testing.T) {
+	t.Parallel()
+	req, err := http.NewRequest(http.MethodGet, "/v1/product/skateboard?include=reviews,%20relations", nil)
+	assert.Nil(t, err)
+	assert.True(t, includeRequested(req, "relations"))
+	assert.True(t, includeRequested(req, "reviews"))
+	assert.False(t, includeRequested(req, "options"))
+
+	req, err = http.NewRequest(http.MethodGet, "/v1/product/skateboard", nil)
+	assert.Nil(t, err)
+	assert.False(t, includeRequested(req, "relations"))
+}
+
+func TestAttachRelationsToProduct(t *testing.T) {
+	t.Parallel()
+	p := &Product{}
+	relations := []ProductRelation{exampleProductRelation()}
+	related := []Product{{DBRow: DBRow{ID: exampleRelatedProductID}, SKU: "helmet"}}
+
+	attachRelationsToProduct(p, relations, related)
+	assert.Equal(t, 1, len(p.Relations))
+	assert.Equal(t, "helmet", p.Relations[0].Product.SKU)
+}
+
+func TestAttachRelationsToProductLeavesOutHiddenProducts(t *testing.T) {
+	t.Parallel()
+	p := &Product{}
+	relations := []ProductRelation{exampleProductRelation()}
+
+	attachRelationsToProduct(p, relations, []Product{})
+	assert.Empty(t, p.Relations, "relations to products the caller can't see should be left out")
+}
+
+func TestRetrieveRelatedProductsWithoutRelations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	related, err := retrieveRelatedProducts(testUtil.DB, nil, true)
+	assert.Nil(t, err)
+	assert.Empty(t, related)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestSingleProductHandlerWithRelations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	allIDs := []uint64{exampleProduct.ID, exampleRelatedProductID}
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductRelationList(testUtil.Mock, exampleProduct.ID, []ProductRelation{exampleProductRelation()}, nil)
+	setExpectationsForRelatedProductList(testUtil.Mock, []uint64{exampleRelatedProductID}, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, allIDs, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, allIDs, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, allIDs, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?include=relations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual.Relations), "product should include its relations")
+	assert.Equal(t, "accessory", actual.Relations[0].Kind)
+	assert.Equal(t, "related-4", actual.Relations[0].Product.SKU, "relations should embed the related product")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestSingleProductHandlerWithRelationsAndCurrency(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	allIDs := []uint64{exampleProduct.ID, exampleRelatedProductID}
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductRelationList(testUtil.Mock, exampleProduct.ID, []ProductRelation{exampleProductRelation()}, nil)
+	setExpectationsForRelatedProductList(testUtil.Mock, []uint64{exampleRelatedProductID}, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, allIDs, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, allIDs, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, allIDs, nil, nil)
+	setExpectationsForProductPriceList(testUtil.Mock, allIDs, "EUR", nil, nil)
+	setExpectationsForExchangeRateRetrieval(testUtil.Mock, "EUR", "0.5", nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?include=relations&currency=EUR", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "EUR", actual.Relations[0].Product.Currency, "related products should be priced in the requested currency")
+	assert.Equal(t, Money(5000), actual.Relations[0].Product.Price)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestSingleProductHandlerWithErrorRetrievingRelations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductRelationList(testUtil.Mock, exampleProduct.ID, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?include=relations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestSingleProductHandlerWithErrorRetrievingRelatedProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductRelationList(testUtil.Mock, exampleProduct.ID, []ProductRelation{exampleProductRelation()}, nil)
+	setExpectationsForRelatedProductList(testUtil.Mock, []uint64{exampleRelatedProductID}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s?include=relations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductRelationList(testUtil.Mock, exampleProduct.ID, []ProductRelation{exampleProductRelation()}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductRelation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual))
+	assert.Equal(t, "helmet", actual[0].RelatedSKU)
+	assert.Nil(t, actual[0].Product, "the relation list shouldn't embed related products")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationListHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	relation := exampleProductRelation()
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", exampleRelatedProductID, nil)
+	setExpectationsForProductRelationUpsert(testUtil.Mock, &relation, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), strings.NewReader(`{"sku": "helmet", "kind": "accessory", "position": 1}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductRelation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "helmet", actual.RelatedSKU)
+	assert.Equal(t, uint32(1), actual.Position)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationUpsertHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	inputs := []string{
+		exampleGarbageInput,
+		`{"sku": "helmet", "kind": "downsell"}`,
+		fmt.Sprintf(`{"sku": "%s", "kind": "related"}`, exampleProduct.SKU),
+	}
+
+	for _, input := range inputs {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), strings.NewReader(input))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for %s", input)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestProductRelationUpsertHandlerForNonexistentRelatedProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), strings.NewReader(`{"sku": "helmet", "kind": "accessory"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationUpsertHandlerWithErrorSavingRelation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	relation := exampleProductRelation()
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", exampleRelatedProductID, nil)
+	setExpectationsForProductRelationUpsert(testUtil.Mock, &relation, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), strings.NewReader(`{"sku": "helmet", "kind": "accessory", "position": 1}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationUpsertHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/relations", exampleProduct.SKU), strings.NewReader(`{"sku": "helmet", "kind": "accessory"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", exampleRelatedProductID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productRelationDeletionQuery)).
+		WithArgs(exampleProduct.ID, exampleRelatedProductID, "cross-sell").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/relations/cross-sell/helmet", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationDeletionHandlerForNonexistentRelation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", exampleRelatedProductID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productRelationDeletionQuery)).
+		WithArgs(exampleProduct.ID, exampleRelatedProductID, "upsell").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/relations/upsell/helmet", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRelationDeletionHandlerForNonexistentRelatedProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, "helmet", 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/relations/upsell/helmet", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
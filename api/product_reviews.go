@@ -0,0 +1,363 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	productReviewsTableHeaders = `id,
+		product_id,
+		user_id,
+		rating,
+		title,
+		body,
+		verified_purchase,
+		status,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	reviewStatusPending  = "pending"
+	reviewStatusApproved = "approved"
+	reviewStatusRejected = "rejected"
+
+	productReviewRetrievalQuery        = `SELECT * FROM product_reviews WHERE id = $1 AND archived_on IS NULL`
+	productReviewExistenceQueryForUser = `SELECT EXISTS(SELECT 1 FROM product_reviews WHERE product_id = $1 AND user_id = $2 AND archived_on IS NULL)`
+)
+
+// ProductReview is a customer's rating of a product. Reviews are only visible to other
+// customers (and only count towards a product's rating) once an admin approves them.
+type ProductReview struct {
+	DBRow
+	ProductID        uint64 `json:"product_id"`
+	UserID           uint64 `json:"user_id"`
+	Rating           uint8  `json:"rating"`
+	Title            string `json:"title"`
+	Body             string `json:"body"`
+	VerifiedPurchase bool   `json:"verified_purchase"`
+	Status           string `json:"status"`
+}
+
+func (r *ProductReview) generateScanArgs() []interface{} {
+	return []interface{}{
+		&r.ID,
+		&r.ProductID,
+		&r.UserID,
+		&r.Rating,
+		&r.Title,
+		&r.Body,
+		&r.VerifiedPurchase,
+		&r.Status,
+		&r.CreatedOn,
+		&r.UpdatedOn,
+		&r.ArchivedOn,
+	}
+}
+
+// ProductReviewsResponse is a product review response struct
+type ProductReviewsResponse struct {
+	ListResponse
+	Data []ProductReview `json:"data"`
+}
+
+// ProductReviewCreationInput is a struct to use for submitting product reviews
+type ProductReviewCreationInput struct {
+	Rating uint8  `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// ProductReviewModerationInput is a struct admins use to approve or reject product reviews.
+// We don't keep track of orders yet, so whether or not a review comes from a verified
+// purchase is also up to the moderator.
+type ProductReviewModerationInput struct {
+	Status           string `json:"status" validate:"required"`
+	VerifiedPurchase *bool  `json:"verified_purchase"`
+}
+
+// productReviewSummary is the aggregate of a product's approved reviews
+type productReviewSummary struct {
+	AverageRating float64
+	ReviewCount   uint64
+}
+
+func reviewStatusIsValid(status string) bool {
+	return status == reviewStatusPending || status == reviewStatusApproved || status == reviewStatusRejected
+}
+
+func userHasReviewedProduct(db *sqlx.DB, productID uint64, userID uint64) (bool, error) {
+	var exists string
+
+	err := db.QueryRow(productReviewExistenceQueryForUser, productID, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return exists == "true", err
+}
+
+func retrieveProductReviewFromDB(db *sqlx.DB, id uint64) (*ProductReview, error) {
+	review := &ProductReview{}
+	err := db.QueryRow(productReviewRetrievalQuery, id).Scan(review.generateScanArgs()...)
+	return review, err
+}
+
+func createProductReviewInDB(db *sqlx.DB, r *ProductReview) error {
+	query, args := buildProductReviewCreationQuery(r)
+	return db.QueryRow(query, args...).Scan(r.generateScanArgs()...)
+}
+
+func updateProductReviewInDB(db *sqlx.DB, r *ProductReview) error {
+	query, args := buildProductReviewUpdateQuery(r)
+	return db.QueryRow(query, args...).Scan(r.generateScanArgs()...)
+}
+
+func getProductReviews(db *sqlx.DB, productID uint64, status string, queryFilter *QueryFilter) ([]ProductReview, uint64, error) {
+	var reviews []ProductReview
+	var count uint64
+
+	if !queryFilter.SkipCount {
+		query, args := buildProductReviewCountQuery(productID, status, queryFilter)
+		if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+			return nil, 0, errors.Wrap(err, "Error encountered counting product reviews")
+		}
+	}
+
+	query, args := buildProductReviewListQuery(productID, status, queryFilter)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Error encountered querying for product reviews")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var review ProductReview
+		err = rows.Scan(review.generateScanArgs()...)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "Error scanning product review")
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, count, rows.Err()
+}
+
+// retrieveProductReviewSummaries aggregates the approved reviews for a batch of products at once, keyed by product ID.
+// Products without any approved reviews are absent from the result.
+func retrieveProductReviewSummaries(db *sqlx.DB, productIDs []uint64) (map[uint64]productReviewSummary, error) {
+	out := map[uint64]productReviewSummary{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+
+	query, args := buildProductReviewSummaryQuery(productIDs)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product review summaries")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uint64
+		var summary productReviewSummary
+		err = rows.Scan(&productID, &summary.AverageRating, &summary.ReviewCount)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error scanning product review summary")
+		}
+		out[productID] = summary
+	}
+	return out, rows.Err()
+}
+
+// attachReviewSummariesToProducts fills in the rating fields of the given products
+func attachReviewSummariesToProducts(db *sqlx.DB, products []Product) error {
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+
+	summaries, err := retrieveProductReviewSummaries(db, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		summary := summaries[products[i].ID]
+		products[i].AverageRating = summary.AverageRating
+		products[i].ReviewCount = summary.ReviewCount
+	}
+	return nil
+}
+
+func respondWithProductReviews(res http.ResponseWriter, queryFilter *QueryFilter, reviews []ProductReview, count uint64) {
+	reviewsResponse := &ProductReviewsResponse{
+		ListResponse: newListResponse(queryFilter, count),
+		Data:         reviews,
+	}
+	if len(reviews) > 0 {
+		reviewsResponse.NextCursor = buildNextCursor(queryFilter, len(reviews), reviews[len(reviews)-1].DBRow)
+	}
+	json.NewEncoder(res).Encode(reviewsResponse)
+}
+
+func buildProductReviewListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductReviewListHandler is a request handler that returns the approved reviews for a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		queryFilter, err := parseRawFilterParams(req.URL.Query())
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		reviews, count, err := getProductReviews(db, productID, reviewStatusApproved, queryFilter)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product reviews from the database")
+			return
+		}
+
+		respondWithProductReviews(res, queryFilter, reviews, count)
+	}
+}
+
+func buildProductReviewCreationHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductReviewCreationHandler is a request handler that lets a logged in user review a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		userID, ok := retrieveUserIDFromSession(req, store)
+		if !ok {
+			notifyOfUnauthorizedRequest(res)
+			return
+		}
+
+		reviewInput := &ProductReviewCreationInput{}
+		err := validateRequestInput(req, reviewInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDForSession(db, req, store, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		// one review per customer per product
+		exists, err := userHasReviewedProduct(db, productID, userID)
+		if err != nil || exists {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("user has already reviewed product `%s`", sku))
+			return
+		}
+
+		newReview := &ProductReview{
+			ProductID: productID,
+			UserID:    userID,
+			Rating:    reviewInput.Rating,
+			Title:     reviewInput.Title,
+			Body:      reviewInput.Body,
+			Status:    reviewStatusPending,
+		}
+		err = createProductReviewInDB(db, newReview)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "insert product review in database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(newReview)
+	}
+}
+
+func buildProductReviewModerationQueueHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductReviewModerationQueueHandler is a request handler that lists reviews awaiting moderation
+	return func(res http.ResponseWriter, req *http.Request) {
+		rawFilterParams := req.URL.Query()
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		status := rawFilterParams.Get("status")
+		if status == "" {
+			status = reviewStatusPending
+		}
+		if !reviewStatusIsValid(status) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("invalid review status: `%s`", status))
+			return
+		}
+
+		reviews, count, err := getProductReviews(db, 0, status, queryFilter)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product reviews from the database")
+			return
+		}
+
+		respondWithProductReviews(res, queryFilter, reviews, count)
+	}
+}
+
+func buildProductReviewModerationHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductReviewModerationHandler is a request handler that approves or rejects a review
+	return func(res http.ResponseWriter, req *http.Request) {
+		reviewID := chi.URLParam(req, "review_id")
+		// eating this error because Chi should validate this for us.
+		reviewIDInt, _ := strconv.ParseUint(reviewID, 10, 64)
+
+		moderationInput := &ProductReviewModerationInput{}
+		err := validateRequestInput(req, moderationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if !reviewStatusIsValid(moderationInput.Status) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("invalid review status: `%s`", moderationInput.Status))
+			return
+		}
+
+		review, err := retrieveProductReviewFromDB(db, reviewIDInt)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product review", reviewID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product review from the database")
+			return
+		}
+
+		review.Status = moderationInput.Status
+		if moderationInput.VerifiedPurchase != nil {
+			review.VerifiedPurchase = *moderationInput.VerifiedPurchase
+		}
+
+		err = updateProductReviewInDB(db, review)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "update product review in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(review)
+	}
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const exampleProductReviewCreationInput = `{"rating": 4, "title": "Rad", "body": "Would shred again"}`
+
+var (
+	productReviewHeaders []string
+	exampleProductReview *ProductReview
+)
+
+func init() {
+	productReviewHeaders = strings.Split(strings.TrimSpace(productReviewsTableHeaders), ",\n\t\t")
+	exampleProductReview = &ProductReview{
+		DBRow: DBRow{
+			ID:        42,
+			CreatedOn: generateExampleTimeForTests(),
+		},
+		// this can't reference exampleProduct, which isn't set up until products_test.go's init runs
+		ProductID: 2,
+		UserID:    1,
+		Rating:    4,
+		Title:     "Rad",
+		Body:      "Would shred again",
+		Status:    reviewStatusPending,
+	}
+}
+
+func exampleProductReviewData(r *ProductReview) []driver.Value {
+	return []driver.Value{r.ID, r.ProductID, r.UserID, r.Rating, r.Title, r.Body, r.VerifiedPurchase, r.Status, r.CreatedOn, nil, nil}
+}
+
+func setExpectationsForProductReviewSummaries(mock sqlmock.Sqlmock, productIDs []uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"product_id", "round", "count"}).
+		AddRow(exampleProduct.ID, 4.5, 2)
+	query, _ := buildProductReviewSummaryQuery(productIDs)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductReviewCount(mock sqlmock.Sqlmock, productID uint64, status string, queryFilter *QueryFilter, count uint64) {
+	query, _ := buildProductReviewCountQuery(productID, status, queryFilter)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func setExpectationsForProductReviewList(mock sqlmock.Sqlmock, productID uint64, status string, err error) {
+	setExpectationsForProductReviewCount(mock, productID, status, defaultQueryFilter, 1)
+	exampleRows := sqlmock.NewRows(productReviewHeaders).AddRow(exampleProductReviewData(exampleProductReview)...)
+	query, _ := buildProductReviewListQuery(productID, status, defaultQueryFilter)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForUserReviewExistence(mock sqlmock.Sqlmock, productID uint64, userID uint64, exists bool, err error) {
+	exampleRows := sqlmock.NewRows([]string{""}).AddRow(fmt.Sprintf("%t", exists))
+	mock.ExpectQuery(formatQueryForSQLMock(productReviewExistenceQueryForUser)).
+		WithArgs(productID, userID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductReviewCreation(mock sqlmock.Sqlmock, r *ProductReview, err error) {
+	exampleRows := sqlmock.NewRows(productReviewHeaders).AddRow(exampleProductReviewData(r)...)
+	query, args := buildProductReviewCreationQuery(r)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductReviewRetrieval(mock sqlmock.Sqlmock, r *ProductReview, err error) {
+	exampleRows := sqlmock.NewRows(productReviewHeaders).AddRow(exampleProductReviewData(r)...)
+	mock.ExpectQuery(formatQueryForSQLMock(productReviewRetrievalQuery)).
+		WithArgs(r.ID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductReviewUpdate(mock sqlmock.Sqlmock, r *ProductReview, err error) {
+	exampleRows := sqlmock.NewRows(productReviewHeaders).AddRow(exampleProductReviewData(r)...)
+	query, args := buildProductReviewUpdateQuery(r)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestAttachReviewSummariesToProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	otherProduct := *exampleProduct
+	otherProduct.ID = 3
+	products := []Product{*exampleProduct, otherProduct}
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID, otherProduct.ID}, nil)
+
+	err := attachReviewSummariesToProducts(testUtil.DB, products)
+	assert.Nil(t, err)
+	assert.Equal(t, 4.5, products[0].AverageRating)
+	assert.Equal(t, uint64(2), products[0].ReviewCount)
+	assert.Zero(t, products[1].ReviewCount, "products without any approved reviews shouldn't have a review count")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestAttachReviewSummariesToProductsWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, arbitraryError)
+
+	err := attachReviewSummariesToProducts(testUtil.DB, []Product{*exampleProduct})
+	assert.NotNil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductReviewList(testUtil.Mock, exampleProduct.ID, reviewStatusApproved, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductReviewsResponse{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), *actual.Count)
+	assert.Equal(t, exampleProductReview.Title, actual.Data[0].Title)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewListHandlerPastTheLastPage(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	queryFilter := &QueryFilter{Page: 3, Limit: 25}
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductReviewCount(testUtil.Mock, exampleProduct.ID, reviewStatusApproved, queryFilter, 30)
+	query, _ := buildProductReviewListQuery(exampleProduct.ID, reviewStatusApproved, queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(sqlmock.NewRows(productReviewHeaders))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/reviews?page=3", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductReviewsResponse{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(30), *actual.Count, "an empty page shouldn't change the count")
+	assert.Empty(t, actual.Data)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewListHandlerWithCursor(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	queryFilter := &QueryFilter{Page: 1, Limit: 25, UseCursor: true, SkipCount: true}
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	query, _ := buildProductReviewListQuery(exampleProduct.ID, reviewStatusApproved, queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(sqlmock.NewRows(productReviewHeaders).AddRow(exampleProductReviewData(exampleProductReview)...))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/reviews?cursor=", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductReviewsResponse{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Nil(t, actual.Count, "cursor pages shouldn't be counted unless asked")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewListHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewListHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductReviewList(testUtil.Mock, exampleProduct.ID, reviewStatusApproved, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForUserReviewExistence(testUtil.Mock, exampleProduct.ID, exampleProductReview.UserID, false, nil)
+	setExpectationsForProductReviewCreation(testUtil.Mock, &ProductReview{
+		ProductID: exampleProduct.ID,
+		UserID:    exampleProductReview.UserID,
+		Rating:    4,
+		Title:     "Rad",
+		Body:      "Would shred again",
+		Status:    reviewStatusPending,
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(exampleProductReviewCreationInput))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, exampleProductReview.UserID, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &ProductReview{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, reviewStatusPending, actual.Status, "new reviews should await moderation")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandlerWithoutSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(exampleProductReviewCreationInput))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusUnauthorized, testUtil.Response.Code, "status code should be 401")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandlerWithInvalidRating(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(`{"rating": 6}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, exampleProductReview.UserID, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(exampleProductReviewCreationInput))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, exampleProductReview.UserID, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandlerWhenUserHasAlreadyReviewedProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForUserReviewExistence(testUtil.Mock, exampleProduct.ID, exampleProductReview.UserID, true, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(exampleProductReviewCreationInput))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, exampleProductReview.UserID, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewCreationHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForUserReviewExistence(testUtil.Mock, exampleProduct.ID, exampleProductReview.UserID, false, nil)
+	setExpectationsForProductReviewCreation(testUtil.Mock, &ProductReview{
+		ProductID: exampleProduct.ID,
+		UserID:    exampleProductReview.UserID,
+		Rating:    4,
+		Title:     "Rad",
+		Body:      "Would shred again",
+		Status:    reviewStatusPending,
+	}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/reviews", exampleProduct.SKU), strings.NewReader(exampleProductReviewCreationInput))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, exampleProductReview.UserID, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationQueueHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductReviewList(testUtil.Mock, 0, reviewStatusPending, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_reviews", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationQueueHandlerForNonAdminUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_reviews", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationQueueHandlerWithInvalidStatus(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/product_reviews?status=spam", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	approvedReview := *exampleProductReview
+	approvedReview.Status = reviewStatusApproved
+	approvedReview.VerifiedPurchase = true
+	setExpectationsForProductReviewRetrieval(testUtil.Mock, exampleProductReview, nil)
+	setExpectationsForProductReviewUpdate(testUtil.Mock, &approvedReview, nil)
+
+	body := `{"status": "approved", "verified_purchase": true}`
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_reviews/%d", exampleProductReview.ID), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductReview{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, reviewStatusApproved, actual.Status)
+	assert.True(t, actual.VerifiedPurchase)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationHandlerWithInvalidStatus(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_reviews/%d", exampleProductReview.ID), strings.NewReader(`{"status": "spam"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationHandlerForNonexistentReview(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductReviewRetrieval(testUtil.Mock, exampleProductReview, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_reviews/%d", exampleProductReview.ID), strings.NewReader(`{"status": "rejected"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductReviewModerationHandlerWithDBErrorUpdatingReview(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	rejectedReview := *exampleProductReview
+	rejectedReview.Status = reviewStatusRejected
+	setExpectationsForProductReviewRetrieval(testUtil.Mock, exampleProductReview, nil)
+	setExpectationsForProductReviewUpdate(testUtil.Mock, &rejectedReview, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_reviews/%d", exampleProductReview.ID), strings.NewReader(`{"status": "rejected"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
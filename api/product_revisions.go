@@ -0,0 +1,280 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	productRevisionsTableHeaders = `id,
+		product_id,
+		revision,
+		action,
+		snapshot,
+		user_id,
+		created_on
+	`
+
+	// revisions are snapshotted by the database, so they always reflect exactly what was written in the same transaction
+	productRevisionCreationQuery = `INSERT INTO product_revisions (product_id, revision, action, snapshot, user_id) SELECT id, COALESCE((SELECT MAX(revision) FROM product_revisions WHERE product_id = $1), 0) + 1, $2, to_jsonb(products), NULLIF($3, 0) FROM products WHERE id = $1`
+	productRevisionListQuery     = `SELECT ` + productRevisionsTableHeaders + ` FROM product_revisions WHERE product_id = $1 ORDER BY revision`
+	productRevisionSKUQuery      = `SELECT snapshot->>'sku' FROM product_revisions WHERE product_id = $1 AND revision = $2`
+
+	// archived products keep their revisions, so the history of a sku that isn't live anymore can still be looked at
+	productIDRetrievalQueryForRevisions = `SELECT id FROM products WHERE sku = $1 ORDER BY archived_on IS NOT NULL, archived_on DESC LIMIT 1`
+
+	// reverting lays the snapshot over the current row, so any column a snapshot predates keeps its current value.
+	// Quantity is left alone, since stock has moved on since the revision was made regardless of what the catalog said.
+	productRevertQuery = `UPDATE products SET
+		name = r.name,
+		subtitle = r.subtitle,
+		description = r.description,
+		sku = r.sku,
+		upc = r.upc,
+		manufacturer = r.manufacturer,
+		brand = r.brand,
+		taxable = r.taxable,
+		price = r.price,
+		on_sale = r.on_sale,
+		sale_price = r.sale_price,
+		cost = r.cost,
+		product_weight = r.product_weight,
+		product_height = r.product_height,
+		product_width = r.product_width,
+		product_length = r.product_length,
+		package_weight = r.package_weight,
+		package_height = r.package_height,
+		package_width = r.package_width,
+		package_length = r.package_length,
+		quantity_per_package = r.quantity_per_package,
+		available_on = r.available_on,
+		status = r.status,
+		discontinued_on = r.discontinued_on,
+		attributes = r.attributes,
+		digital = r.digital,
+		reorder_threshold = r.reorder_threshold,
+		stock_policy = r.stock_policy,
+		max_oversell = r.max_oversell,
+		restock_on = r.restock_on,
+		updated_on = NOW()
+	FROM (SELECT (jsonb_populate_record(p, v.snapshot)).* FROM products p JOIN product_revisions v ON v.product_id = p.id WHERE p.id = $1 AND v.revision = $2) r
+	WHERE products.id = $1 AND products.archived_on IS NULL RETURNING products.*`
+)
+
+// these change on every write, so they'd only clutter up a diff
+var productRevisionFieldsIgnoredInDiffs = map[string]bool{
+	"id":         true,
+	"created_on": true,
+	"updated_on": true,
+}
+
+// ProductRevision is a snapshot of a product as it was after it was created, updated, archived, restored, or reverted
+type ProductRevision struct {
+	ID        uint64          `json:"id"`
+	ProductID uint64          `json:"product_id"`
+	Revision  uint32          `json:"revision"`
+	Action    string          `json:"action"`
+	Snapshot  json.RawMessage `json:"snapshot"`
+	UserID    *uint64         `json:"user_id"`
+	CreatedOn time.Time       `json:"created_on"`
+
+	// Changes are the fields that differ from the previous revision
+	Changes []ProductFieldChange `json:"changes"`
+}
+
+func (r *ProductRevision) generateScanArgs() []interface{} {
+	return []interface{}{
+		&r.ID,
+		&r.ProductID,
+		&r.Revision,
+		&r.Action,
+		&r.Snapshot,
+		&r.UserID,
+		&r.CreatedOn,
+	}
+}
+
+// ProductFieldChange describes a single field that changed between two revisions
+type ProductFieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// recordProductRevision saves the product's current state as its next revision
+func recordProductRevision(tx *sql.Tx, productID uint64, action string, userID uint64) error {
+	_, err := tx.Exec(productRevisionCreationQuery, productID, action, userID)
+	return err
+}
+
+// diffProductSnapshots returns the fields that differ between two snapshots, sorted by field name
+func diffProductSnapshots(before, after json.RawMessage) ([]ProductFieldChange, error) {
+	beforeFields := map[string]json.RawMessage{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &beforeFields); err != nil {
+			return nil, err
+		}
+	}
+	afterFields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(after, &afterFields); err != nil {
+		return nil, err
+	}
+
+	fields := []string{}
+	for field := range afterFields {
+		fields = append(fields, field)
+	}
+	for field := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []ProductFieldChange{}
+	for _, field := range fields {
+		if productRevisionFieldsIgnoredInDiffs[field] {
+			continue
+		}
+		if string(beforeFields[field]) != string(afterFields[field]) {
+			changes = append(changes, ProductFieldChange{Field: field, From: beforeFields[field], To: afterFields[field]})
+		}
+	}
+	return changes, nil
+}
+
+// retrieveProductRevisions retrieves every revision of a product, each with the changes made since the one before it
+func retrieveProductRevisions(db *sqlx.DB, productID uint64) ([]ProductRevision, error) {
+	rows, err := db.Query(productRevisionListQuery, productID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product revisions")
+	}
+	defer rows.Close()
+
+	revisions := []ProductRevision{}
+	var previous json.RawMessage
+	for rows.Next() {
+		var r ProductRevision
+		if err = rows.Scan(r.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product revision")
+		}
+
+		// the first revision has nothing to be compared to
+		r.Changes = []ProductFieldChange{}
+		if previous != nil {
+			r.Changes, err = diffProductSnapshots(previous, r.Snapshot)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error comparing product revisions")
+			}
+		}
+		previous = r.Snapshot
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+func buildProductRevisionListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductRevisionListHandler is a request handler that returns the revision history of a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		var productID uint64
+		err := db.Get(&productID, productIDRetrievalQueryForRevisions, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		revisions, err := retrieveProductRevisions(db, productID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product revisions from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(revisions)
+	}
+}
+
+func buildProductRevertHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ProductRevertHandler is a request handler that puts a product back the way it was at a prior revision
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		revisionParam := chi.URLParam(req, "revision")
+		revision, _ := strconv.ParseUint(revisionParam, 10, 32)
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		var revisionSKU string
+		err = db.Get(&revisionSKU, productRevisionSKUQuery, productID, revision)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product revision", revisionParam)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product revision from the database")
+			return
+		}
+
+		// the product's old sku may have been given to another product since
+		if revisionSKU != sku {
+			exists, err := rowExistsInDB(db, skuExistenceQuery, revisionSKU)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "check for products with the same sku")
+				return
+			}
+			if exists {
+				notifyOfSKUConflict(res, revisionSKU)
+				return
+			}
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		product := &Product{}
+		err = tx.QueryRowx(productRevertQuery, productID, revision).StructScan(product)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "revert product in database")
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		err = recordProductRevision(tx.Tx, productID, "revert", userID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "record product revision in database")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "closing out transaction")
+			return
+		}
+
+		product.Availability = product.availability(time.Now())
+		json.NewEncoder(res).Encode(product)
+	}
+}
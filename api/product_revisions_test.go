@@ -0,0 +1,277 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var productRevisionHeaders = []string{"id", "product_id", "revision", "action", "snapshot", "user_id", "created_on"}
+
+func setExpectationsForProductRevisionCreation(mock sqlmock.Sqlmock, productID uint64, action string, userID uint64, err error) {
+	mock.ExpectExec(formatQueryForSQLMock(productRevisionCreationQuery)).
+		WithArgs(productID, action, userID).
+		WillReturnResult(sqlmock.NewResult(1, 1)).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductIDRetrievalForRevisions(mock sqlmock.Sqlmock, sku string, id uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id"})
+	if id != 0 {
+		exampleRows = exampleRows.AddRow(id)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productIDRetrievalQueryForRevisions)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductRevisionList(mock sqlmock.Sqlmock, productID uint64, snapshots []string, err error) {
+	exampleRows := sqlmock.NewRows(productRevisionHeaders)
+	for i, snapshot := range snapshots {
+		exampleRows = exampleRows.AddRow(i+1, productID, i+1, "update", []byte(snapshot), 1, generateExampleTimeForTests())
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productRevisionListQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductRevisionSKURetrieval(mock sqlmock.Sqlmock, productID uint64, revision uint64, sku string, err error) {
+	exampleRows := sqlmock.NewRows([]string{"sku"})
+	if sku != "" {
+		exampleRows = exampleRows.AddRow(sku)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(productRevisionSKUQuery)).
+		WithArgs(productID, revision).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductRevert(mock sqlmock.Sqlmock, productID uint64, revision uint64, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	mock.ExpectBegin()
+	mock.ExpectQuery(formatQueryForSQLMock(productRevertQuery)).
+		WithArgs(productID, revision).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+	if err != nil {
+		mock.ExpectRollback()
+		return
+	}
+	setExpectationsForProductRevisionCreation(mock, productID, "revert", 1, nil)
+	mock.ExpectCommit()
+}
+
+func TestDiffProductSnapshots(t *testing.T) {
+	t.Parallel()
+	before := json.RawMessage(`{"id": 2, "name": "Skateboard", "price": 99.99, "updated_on": null}`)
+	after := json.RawMessage(`{"id": 2, "name": "Skateboard", "price": 89.99, "brand": "Zero", "updated_on": "2017-07-14T00:00:00"}`)
+
+	expected := []ProductFieldChange{
+		{Field: "brand", From: nil, To: json.RawMessage(`"Zero"`)},
+		{Field: "price", From: json.RawMessage(`99.99`), To: json.RawMessage(`89.99`)},
+	}
+	actual, err := diffProductSnapshots(before, after)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual, "only changed fields that aren't updated on every write should be reported")
+}
+
+func TestDiffProductSnapshotsWithInvalidSnapshot(t *testing.T) {
+	t.Parallel()
+	_, err := diffProductSnapshots(json.RawMessage(`{}`), json.RawMessage(`[`))
+	assert.NotNil(t, err)
+}
+
+func TestRetrieveProductRevisions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRevisionList(testUtil.Mock, exampleProduct.ID, []string{`{"name": "Skateboard"}`, `{"name": "Longboard"}`}, nil)
+
+	actual, err := retrieveProductRevisions(testUtil.DB, exampleProduct.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Empty(t, actual[0].Changes, "the first revision shouldn't have any changes")
+	assert.Equal(t, []ProductFieldChange{{Field: "name", From: json.RawMessage(`"Skateboard"`), To: json.RawMessage(`"Longboard"`)}}, actual[1].Changes)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestRetrieveProductRevisionsWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductRevisionList(testUtil.Mock, exampleProduct.ID, nil, arbitraryError)
+
+	_, err := retrieveProductRevisions(testUtil.DB, exampleProduct.ID)
+	assert.NotNil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevisionListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalForRevisions(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionList(testUtil.Mock, exampleProduct.ID, []string{`{"price": 99.99}`, `{"price": 89.99}`}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/revisions", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductRevision{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	assert.Equal(t, "price", actual[1].Changes[0].Field)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevisionListHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalForRevisions(testUtil.Mock, exampleSKU, 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/revisions", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevisionListHandlerWithErrorRetrievingRevisions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalForRevisions(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionList(testUtil.Mock, exampleProduct.ID, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/revisions", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevisionListHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/revisions", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionSKURetrieval(testUtil.Mock, exampleProduct.ID, 1, exampleSKU, nil)
+	setExpectationsForProductRevert(testUtil.Mock, exampleProduct.ID, 1, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, exampleProduct.SKU, actual.SKU)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerWithOldSKU(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionSKURetrieval(testUtil.Mock, exampleProduct.ID, 1, "old-sku", nil)
+	setExpectationsForProductExistence(testUtil.Mock, "old-sku", false, nil)
+	setExpectationsForProductRevert(testUtil.Mock, exampleProduct.ID, 1, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerWhenOldSKUHasBeenTaken(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionSKURetrieval(testUtil.Mock, exampleProduct.ID, 1, "old-sku", nil)
+	setExpectationsForProductExistence(testUtil.Mock, "old-sku", true, nil)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusConflict, testUtil.Response.Code, "status code should be 409")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, 0, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerForNonexistentRevision(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionSKURetrieval(testUtil.Mock, exampleProduct.ID, 9, "", sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/9/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerWithErrorReverting(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleSKU, exampleProduct.ID, nil)
+	setExpectationsForProductRevisionSKURetrieval(testUtil.Mock, exampleProduct.ID, 1, exampleSKU, nil)
+	setExpectationsForProductRevert(testUtil.Mock, exampleProduct.ID, 1, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRevertHandlerRequiresAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product/%s/revisions/1/revert", exampleSKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -0,0 +1,727 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBaseLocale = "en"
+
+	productTranslationsTableHeaders = `id,
+		product_id,
+		locale,
+		name,
+		subtitle,
+		description,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	productOptionTranslationsTableHeaders = `id,
+		product_option_id,
+		locale,
+		name,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	productOptionValueTranslationsTableHeaders = `id,
+		product_option_value_id,
+		locale,
+		value,
+		created_on,
+		updated_on,
+		archived_on
+	`
+
+	productTranslationDeletionQuery            = `UPDATE product_translations SET archived_on = NOW() WHERE product_id = $1 AND locale = $2 AND archived_on IS NULL`
+	productOptionTranslationDeletionQuery      = `UPDATE product_option_translations SET archived_on = NOW() WHERE product_option_id = $1 AND locale = $2 AND archived_on IS NULL`
+	productOptionValueTranslationDeletionQuery = `UPDATE product_option_value_translations SET archived_on = NOW() WHERE product_option_value_id = $1 AND locale = $2 AND archived_on IS NULL`
+)
+
+// baseLocale is the locale the content in the products, product_options, and product_option_values tables is written in,
+// and what clients get whenever there's no translation in a language they'll accept. It's set at startup from the
+// DAIRYCART_BASE_LOCALE environment variable.
+var baseLocale = defaultBaseLocale
+
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// normalizeLocale validates a language tag like `de-at` and returns it in its conventional casing (`de-AT`)
+func normalizeLocale(locale string) (string, error) {
+	if !localePattern.MatchString(locale) {
+		return "", fmt.Errorf("invalid locale: `%s`", locale)
+	}
+	subtags := strings.Split(locale, "-")
+	subtags[0] = strings.ToLower(subtags[0])
+	for i := 1; i < len(subtags); i++ {
+		switch len(subtags[i]) {
+		case 2:
+			// regions, like the AT in de-AT
+			subtags[i] = strings.ToUpper(subtags[i])
+		case 4:
+			// scripts, like the Hant in zh-Hant
+			subtags[i] = strings.ToUpper(subtags[i][:1]) + strings.ToLower(subtags[i][1:])
+		default:
+			subtags[i] = strings.ToLower(subtags[i])
+		}
+	}
+	return strings.Join(subtags, "-"), nil
+}
+
+func setBaseLocale(locale string) error {
+	normalized, err := normalizeLocale(locale)
+	if err != nil {
+		return errors.Wrap(err, "unsupported base locale")
+	}
+	baseLocale = normalized
+	return nil
+}
+
+// parseAcceptLanguage returns the locales in an Accept-Language header from most to least preferred. Each regional
+// locale is followed by its language, so that someone asking for de-AT gets German content if there's no Austrian German.
+func parseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale string
+		weight float64
+	}
+
+	weighted := []weightedLocale{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale, err := normalizeLocale(strings.TrimSpace(fields[0]))
+		if err != nil {
+			// this also skips the `*` wildcard, which the base locale already covers
+			continue
+		}
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if weight, err = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err != nil {
+					weight = 0
+				}
+			}
+		}
+		if weight > 0 {
+			weighted = append(weighted, weightedLocale{locale: locale, weight: weight})
+		}
+	}
+	sort.SliceStable(weighted, func(i, j int) bool { return weighted[i].weight > weighted[j].weight })
+
+	seen := map[string]bool{}
+	locales := []string{}
+	for _, w := range weighted {
+		for _, locale := range []string{w.locale, strings.Split(w.locale, "-")[0]} {
+			if !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	return locales
+}
+
+// negotiateLocales returns the locales a request's content should be translated into, from most to least preferred.
+// Nothing the client likes less than the base locale is included, since the base locale is always available.
+func negotiateLocales(req *http.Request) []string {
+	locales := parseAcceptLanguage(req.Header.Get("Accept-Language"))
+	for i, locale := range locales {
+		if locale == baseLocale {
+			return locales[:i]
+		}
+	}
+	return locales
+}
+
+// localeRanks maps each of the negotiated locales to its position in the list, so translations can be compared by preference
+func localeRanks(locales []string) map[string]int {
+	ranks := map[string]int{}
+	for i, locale := range locales {
+		ranks[locale] = i
+	}
+	return ranks
+}
+
+// ProductTranslation is a product's name, subtitle, and description in a locale other than the base locale
+type ProductTranslation struct {
+	DBRow
+	ProductID   uint64     `json:"product_id"`
+	Locale      string     `json:"locale"`
+	Name        string     `json:"name"`
+	Subtitle    NullString `json:"subtitle"`
+	Description NullString `json:"description"`
+}
+
+func (t *ProductTranslation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&t.ID,
+		&t.ProductID,
+		&t.Locale,
+		&t.Name,
+		&t.Subtitle,
+		&t.Description,
+		&t.CreatedOn,
+		&t.UpdatedOn,
+		&t.ArchivedOn,
+	}
+}
+
+// ProductTranslationInput is a struct to use for translating a product. The subtitle and description
+// can be left out, in which case the product's own are used.
+type ProductTranslationInput struct {
+	Name        string `json:"name" validate:"required"`
+	Subtitle    string `json:"subtitle"`
+	Description string `json:"description"`
+}
+
+// ProductOptionTranslation is a product option's name in a locale other than the base locale
+type ProductOptionTranslation struct {
+	DBRow
+	ProductOptionID uint64 `json:"product_option_id"`
+	Locale          string `json:"locale"`
+	Name            string `json:"name"`
+}
+
+func (t *ProductOptionTranslation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&t.ID,
+		&t.ProductOptionID,
+		&t.Locale,
+		&t.Name,
+		&t.CreatedOn,
+		&t.UpdatedOn,
+		&t.ArchivedOn,
+	}
+}
+
+// ProductOptionTranslationInput is a struct to use for translating a product option
+type ProductOptionTranslationInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// ProductOptionValueTranslation is a product option value in a locale other than the base locale
+type ProductOptionValueTranslation struct {
+	DBRow
+	ProductOptionValueID uint64 `json:"product_option_value_id"`
+	Locale               string `json:"locale"`
+	Value                string `json:"value"`
+}
+
+func (t *ProductOptionValueTranslation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&t.ID,
+		&t.ProductOptionValueID,
+		&t.Locale,
+		&t.Value,
+		&t.CreatedOn,
+		&t.UpdatedOn,
+		&t.ArchivedOn,
+	}
+}
+
+// ProductOptionValueTranslationInput is a struct to use for translating a product option value
+type ProductOptionValueTranslationInput struct {
+	Value string `json:"value" validate:"required"`
+}
+
+// translateProducts swaps each product's content for the most preferred translation it has in the given locales.
+// Products without a translation in any of them are left in the base locale.
+func translateProducts(db *sqlx.DB, products []Product, locales []string) error {
+	if len(products) == 0 || len(locales) == 0 {
+		return nil
+	}
+
+	productIDs := []uint64{}
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+	}
+	query, args := buildProductTranslationListQueryForProducts(productIDs, locales)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "Error encountered querying for product translations")
+	}
+	defer rows.Close()
+
+	ranks := localeRanks(locales)
+	best := map[uint64]ProductTranslation{}
+	for rows.Next() {
+		var t ProductTranslation
+		if err = rows.Scan(t.generateScanArgs()...); err != nil {
+			return errors.Wrap(err, "Error scanning product translation")
+		}
+		if current, ok := best[t.ProductID]; !ok || ranks[t.Locale] < ranks[current.Locale] {
+			best[t.ProductID] = t
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range products {
+		t, ok := best[products[i].ID]
+		if !ok {
+			continue
+		}
+		products[i].Locale = t.Locale
+		products[i].Name = t.Name
+		if t.Subtitle.Valid {
+			products[i].Subtitle = t.Subtitle
+		}
+		if t.Description.Valid {
+			products[i].Description = t.Description.String
+		}
+	}
+	return nil
+}
+
+// retrieveBestProductOptionValueTranslations retrieves the most preferred translation of each of the given values, keyed by value ID
+func retrieveBestProductOptionValueTranslations(db *sqlx.DB, valueIDs []uint64, locales []string) (map[uint64]ProductOptionValueTranslation, error) {
+	best := map[uint64]ProductOptionValueTranslation{}
+	if len(valueIDs) == 0 {
+		return best, nil
+	}
+	ranks := localeRanks(locales)
+
+	query, args := buildProductOptionValueTranslationListQueryForValues(valueIDs, locales)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error encountered querying for product option value translations")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t ProductOptionValueTranslation
+		if err = rows.Scan(t.generateScanArgs()...); err != nil {
+			return nil, errors.Wrap(err, "Error scanning product option value translation")
+		}
+		if current, ok := best[t.ProductOptionValueID]; !ok || ranks[t.Locale] < ranks[current.Locale] {
+			best[t.ProductOptionValueID] = t
+		}
+	}
+	return best, rows.Err()
+}
+
+// translateProductOptionValues is translateProductOptions for values that were retrieved on their own
+func translateProductOptionValues(db *sqlx.DB, values []ProductOptionValue, locales []string) error {
+	if len(values) == 0 || len(locales) == 0 {
+		return nil
+	}
+
+	valueIDs := []uint64{}
+	for _, v := range values {
+		valueIDs = append(valueIDs, v.ID)
+	}
+	best, err := retrieveBestProductOptionValueTranslations(db, valueIDs, locales)
+	if err != nil {
+		return err
+	}
+
+	for i := range values {
+		if t, ok := best[values[i].ID]; ok {
+			values[i].Value = t.Value
+		}
+	}
+	return nil
+}
+
+// translateProductOptions swaps the names of options and their values for the most preferred translation they have
+// in the given locales. Anything without a translation is left in the base locale.
+func translateProductOptions(db *sqlx.DB, options []ProductOption, locales []string) error {
+	if len(options) == 0 || len(locales) == 0 {
+		return nil
+	}
+	ranks := localeRanks(locales)
+
+	optionIDs := []uint64{}
+	valueIDs := []uint64{}
+	for _, o := range options {
+		optionIDs = append(optionIDs, o.ID)
+		for _, v := range o.Values {
+			valueIDs = append(valueIDs, v.ID)
+		}
+	}
+
+	query, args := buildProductOptionTranslationListQueryForOptions(optionIDs, locales)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "Error encountered querying for product option translations")
+	}
+	defer rows.Close()
+
+	bestOptions := map[uint64]ProductOptionTranslation{}
+	for rows.Next() {
+		var t ProductOptionTranslation
+		if err = rows.Scan(t.generateScanArgs()...); err != nil {
+			return errors.Wrap(err, "Error scanning product option translation")
+		}
+		if current, ok := bestOptions[t.ProductOptionID]; !ok || ranks[t.Locale] < ranks[current.Locale] {
+			bestOptions[t.ProductOptionID] = t
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	bestValues, err := retrieveBestProductOptionValueTranslations(db, valueIDs, locales)
+	if err != nil {
+		return err
+	}
+
+	for i := range options {
+		if t, ok := bestOptions[options[i].ID]; ok {
+			options[i].Name = t.Name
+		}
+		for j := range options[i].Values {
+			if t, ok := bestValues[options[i].Values[j].ID]; ok {
+				options[i].Values[j].Value = t.Value
+			}
+		}
+	}
+	return nil
+}
+
+// parseTranslationLocale reads the locale a translation route is for, which has to be something other than the base locale
+func parseTranslationLocale(req *http.Request) (string, error) {
+	locale, err := normalizeLocale(chi.URLParam(req, "locale"))
+	if err != nil {
+		return "", err
+	}
+	if locale == baseLocale {
+		return "", fmt.Errorf("content in the base locale (`%s`) can't be translated", baseLocale)
+	}
+	return locale, nil
+}
+
+func buildProductTranslationListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductTranslationListHandler is a request handler that returns every translation of a product
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		query, args := buildProductTranslationListQuery(productID)
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product translations from the database")
+			return
+		}
+		defer rows.Close()
+
+		translations := []ProductTranslation{}
+		for rows.Next() {
+			var t ProductTranslation
+			if err = rows.Scan(t.generateScanArgs()...); err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product translations from the database")
+				return
+			}
+			translations = append(translations, t)
+		}
+
+		json.NewEncoder(res).Encode(translations)
+	}
+}
+
+func buildProductTranslationUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductTranslationUpsertHandler is a request handler that translates a product into a locale, or replaces the
+	// translation it already has for that locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		translationInput := &ProductTranslationInput{}
+		err = validateRequestInput(req, translationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		translation := &ProductTranslation{
+			ProductID: productID,
+			Locale:    locale,
+			Name:      translationInput.Name,
+		}
+		translation.Subtitle.String, translation.Subtitle.Valid = translationInput.Subtitle, translationInput.Subtitle != ""
+		translation.Description.String, translation.Description.Valid = translationInput.Description, translationInput.Description != ""
+
+		query, args := buildProductTranslationUpsertQuery(translation)
+		err = db.QueryRow(query, args...).Scan(translation.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product translation in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(translation)
+	}
+}
+
+func buildProductTranslationDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductTranslationDeletionHandler is a request handler that removes a product's translation for a locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productID, err := retrieveProductIDBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+
+		result, err := db.Exec(productTranslationDeletionQuery, productID, locale)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product translation")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product translation", locale)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildProductOptionTranslationListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionTranslationListHandler is a request handler that returns every translation of a product option
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionID := chi.URLParam(req, "option_id")
+
+		exists, err := rowExistsInDB(db, productOptionExistenceQuery, optionID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option from the database")
+			return
+		} else if !exists {
+			respondThatRowDoesNotExist(req, res, "product option", optionID)
+			return
+		}
+
+		optionIDInt, _ := strconv.ParseUint(optionID, 10, 64)
+		query, args := buildProductOptionTranslationListQuery(optionIDInt)
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option translations from the database")
+			return
+		}
+		defer rows.Close()
+
+		translations := []ProductOptionTranslation{}
+		for rows.Next() {
+			var t ProductOptionTranslation
+			if err = rows.Scan(t.generateScanArgs()...); err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product option translations from the database")
+				return
+			}
+			translations = append(translations, t)
+		}
+
+		json.NewEncoder(res).Encode(translations)
+	}
+}
+
+func buildProductOptionTranslationUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionTranslationUpsertHandler is a request handler that translates a product option's name into a locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionID := chi.URLParam(req, "option_id")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		translationInput := &ProductOptionTranslationInput{}
+		err = validateRequestInput(req, translationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		exists, err := rowExistsInDB(db, productOptionExistenceQuery, optionID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option from the database")
+			return
+		} else if !exists {
+			respondThatRowDoesNotExist(req, res, "product option", optionID)
+			return
+		}
+
+		optionIDInt, _ := strconv.ParseUint(optionID, 10, 64)
+		translation := &ProductOptionTranslation{
+			ProductOptionID: optionIDInt,
+			Locale:          locale,
+			Name:            translationInput.Name,
+		}
+		query, args := buildProductOptionTranslationUpsertQuery(translation)
+		err = db.QueryRow(query, args...).Scan(translation.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product option translation in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(translation)
+	}
+}
+
+func buildProductOptionTranslationDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionTranslationDeletionHandler is a request handler that removes a product option's translation for a locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionID := chi.URLParam(req, "option_id")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		result, err := db.Exec(productOptionTranslationDeletionQuery, optionID, locale)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product option translation")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product option translation", locale)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
+
+func buildProductOptionValueTranslationListHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueTranslationListHandler is a request handler that returns every translation of a product option value
+	return func(res http.ResponseWriter, req *http.Request) {
+		valueID := chi.URLParam(req, "option_value_id")
+
+		exists, err := rowExistsInDB(db, productOptionValueExistenceQuery, valueID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value from the database")
+			return
+		} else if !exists {
+			respondThatRowDoesNotExist(req, res, "product option value", valueID)
+			return
+		}
+
+		valueIDInt, _ := strconv.ParseUint(valueID, 10, 64)
+		query, args := buildProductOptionValueTranslationListQuery(valueIDInt)
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value translations from the database")
+			return
+		}
+		defer rows.Close()
+
+		translations := []ProductOptionValueTranslation{}
+		for rows.Next() {
+			var t ProductOptionValueTranslation
+			if err = rows.Scan(t.generateScanArgs()...); err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product option value translations from the database")
+				return
+			}
+			translations = append(translations, t)
+		}
+
+		json.NewEncoder(res).Encode(translations)
+	}
+}
+
+func buildProductOptionValueTranslationUpsertHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueTranslationUpsertHandler is a request handler that translates a product option value into a locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		valueID := chi.URLParam(req, "option_value_id")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		translationInput := &ProductOptionValueTranslationInput{}
+		err = validateRequestInput(req, translationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		exists, err := rowExistsInDB(db, productOptionValueExistenceQuery, valueID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value from the database")
+			return
+		} else if !exists {
+			respondThatRowDoesNotExist(req, res, "product option value", valueID)
+			return
+		}
+
+		valueIDInt, _ := strconv.ParseUint(valueID, 10, 64)
+		translation := &ProductOptionValueTranslation{
+			ProductOptionValueID: valueIDInt,
+			Locale:               locale,
+			Value:                translationInput.Value,
+		}
+		query, args := buildProductOptionValueTranslationUpsertQuery(translation)
+		err = db.QueryRow(query, args...).Scan(translation.generateScanArgs()...)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "save product option value translation in database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(translation)
+	}
+}
+
+func buildProductOptionValueTranslationDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+	// ProductOptionValueTranslationDeletionHandler is a request handler that removes a product option value's translation for a locale
+	return func(res http.ResponseWriter, req *http.Request) {
+		valueID := chi.URLParam(req, "option_value_id")
+		locale, err := parseTranslationLocale(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		result, err := db.Exec(productOptionValueTranslationDeletionQuery, valueID, locale)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "delete product option value translation")
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			respondThatRowDoesNotExist(req, res, "product option value translation", locale)
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	productTranslationHeaders            = strings.Split(strings.TrimSpace(productTranslationsTableHeaders), ",\n\t\t")
+	productOptionTranslationHeaders      = strings.Split(strings.TrimSpace(productOptionTranslationsTableHeaders), ",\n\t\t")
+	productOptionValueTranslationHeaders = strings.Split(strings.TrimSpace(productOptionValueTranslationsTableHeaders), ",\n\t\t")
+)
+
+func productTranslationRows(translations []ProductTranslation) *sqlmock.Rows {
+	exampleRows := sqlmock.NewRows(productTranslationHeaders)
+	for _, t := range translations {
+		var subtitle, description interface{}
+		if t.Subtitle.Valid {
+			subtitle = t.Subtitle.String
+		}
+		if t.Description.Valid {
+			description = t.Description.String
+		}
+		exampleRows = exampleRows.AddRow(t.ID, t.ProductID, t.Locale, t.Name, subtitle, description, generateExampleTimeForTests(), nil, nil)
+	}
+	return exampleRows
+}
+
+func setExpectationsForProductTranslationsForProducts(mock sqlmock.Sqlmock, productIDs []uint64, locales []string, translations []ProductTranslation, err error) {
+	query, args := buildProductTranslationListQueryForProducts(productIDs, locales)
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productTranslationRows(translations)).
+		WillReturnError(err)
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	t.Parallel()
+	validLocales := map[string]string{
+		"de":         "de",
+		"DE":         "de",
+		"de-at":      "de-AT",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+	}
+	for in, expected := range validLocales {
+		actual, err := normalizeLocale(in)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "normalizeLocale(%s) should return %s", in, expected)
+	}
+
+	for _, in := range []string{"", "*", "d", "de_AT", "de-"} {
+		_, err := normalizeLocale(in)
+		assert.NotNil(t, err, "normalizeLocale(%s) should return an error", in)
+	}
+}
+
+func TestSetBaseLocale(t *testing.T) {
+	// not parallel, since it changes the base locale everything else relies on
+	defer func() { baseLocale = defaultBaseLocale }()
+
+	assert.Nil(t, setBaseLocale("DE"))
+	assert.Equal(t, "de", baseLocale)
+	assert.NotNil(t, setBaseLocale("not a locale"))
+	assert.Equal(t, "de", baseLocale, "an invalid locale shouldn't change the base locale")
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	t.Parallel()
+	testCases := map[string][]string{
+		"":                                   {},
+		"de":                                 {"de"},
+		"de-AT":                              {"de-AT", "de"},
+		"es;q=0.5, de-at, en;q=0.8":          {"de-AT", "de", "en", "es"},
+		"fr;q=0, *, es;q=nonsense, de;q=0.2": {"de"},
+		"en-GB, en;q=0.9, de-DE;q=0.8":       {"en-GB", "en", "de-DE", "de"},
+	}
+	for header, expected := range testCases {
+		assert.Equal(t, expected, parseAcceptLanguage(header), "unexpected locales parsed from `%s`", header)
+	}
+}
+
+func TestNegotiateLocales(t *testing.T) {
+	t.Parallel()
+	testCases := map[string][]string{
+		"":                       {},
+		"de, es;q=0.5":           {"de", "es"},
+		"de, en;q=0.8, es;q=0.5": {"de"},
+		"en-GB":                  {"en-GB"},
+		"en, de":                 {},
+	}
+	for header, expected := range testCases {
+		req, err := http.NewRequest(http.MethodGet, "/v1/products", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Accept-Language", header)
+		assert.Equal(t, expected, negotiateLocales(req), "unexpected locales negotiated from `%s`", header)
+	}
+}
+
+func TestTranslateProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	translations := []ProductTranslation{
+		{ProductID: 1, Locale: "es", Name: "Monopatín"},
+		{ProductID: 1, Locale: "de", Name: "Skateboard", Description: NullString{sql.NullString{String: "Das ist ein Skateboard.", Valid: true}}},
+		{ProductID: 2, Locale: "es", Name: "Casco", Subtitle: NullString{sql.NullString{String: "seguro", Valid: true}}},
+	}
+	locales := []string{"de", "es"}
+	setExpectationsForProductTranslationsForProducts(testUtil.Mock, []uint64{1, 2, 3}, locales, translations, nil)
+
+	products := []Product{
+		{DBRow: DBRow{ID: 1}, Name: "Skateboard", Description: "This is a skateboard."},
+		{DBRow: DBRow{ID: 2}, Name: "Helmet", Description: "Please wear one."},
+		{DBRow: DBRow{ID: 3}, Name: "Wax", Description: "For rails."},
+	}
+	err := translateProducts(testUtil.DB, products, locales)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "de", products[0].Locale, "the most preferred translation should be used")
+	assert.Equal(t, "Das ist ein Skateboard.", products[0].Description)
+	assert.Equal(t, "es", products[1].Locale)
+	assert.Equal(t, "Casco", products[1].Name)
+	assert.Equal(t, "seguro", products[1].Subtitle.String)
+	assert.Equal(t, "Please wear one.", products[1].Description, "untranslated fields should fall back to the base locale")
+	assert.Equal(t, "", products[2].Locale, "untranslated products should be left alone")
+	assert.Equal(t, "Wax", products[2].Name)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestTranslateProductsWithoutLocales(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	products := []Product{{DBRow: DBRow{ID: 1}, Name: "Skateboard"}}
+	err := translateProducts(testUtil.DB, products, []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, "Skateboard", products[0].Name)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithAcceptLanguage(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	translation := ProductTranslation{ProductID: exampleProduct.ID, Locale: "de", Name: "Rollbrett"}
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductTranslationsForProducts(testUtil.Mock, []uint64{exampleProduct.ID}, []string{"de-AT", "de"}, []ProductTranslation{translation}, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	req.Header.Set("Accept-Language", "de-AT, en;q=0.5")
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "Rollbrett", actual.Name)
+	assert.Equal(t, "de", actual.Locale)
+	assert.Equal(t, exampleProduct.Description, actual.Description)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithErrorRetrievingTranslations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductTranslationsForProducts(testUtil.Mock, []uint64{exampleProduct.ID}, []string{"de"}, nil, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	req.Header.Set("Accept-Language", "de")
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionListHandlerWithAcceptLanguage(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionListQueryWithCount(testUtil.Mock, exampleProductOption, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+	setExpectationsForProductOptionValueRetrievalByOptionID(testUtil.Mock, exampleProductOption, nil)
+
+	optionIDs := []uint64{exampleProductOption.ID, exampleProductOption.ID, exampleProductOption.ID}
+	query, args := buildProductOptionTranslationListQueryForOptions(optionIDs, []string{"es"})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionTranslationHeaders).
+			AddRow(1, exampleProductOption.ID, "es", "algo", generateExampleTimeForTests(), nil, nil))
+
+	valueIDs := []uint64{exampleProductOptionValue.ID, exampleProductOptionValue.ID, exampleProductOptionValue.ID}
+	query, args = buildProductOptionValueTranslationListQueryForValues(valueIDs, []string{"es"})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionValueTranslationHeaders).
+			AddRow(1, exampleProductOptionValue.ID, "es", "otra cosa", generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%d/options", exampleProduct.ID), nil)
+	assert.Nil(t, err)
+	req.Header.Set("Accept-Language", "es")
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductOptionsResponse{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	for _, option := range actual.Data {
+		assert.Equal(t, "algo", option.Name)
+		assert.Equal(t, "otra cosa", option.Values[0].Value)
+	}
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	query, args := buildProductTranslationListQuery(exampleProduct.ID)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productTranslationRows([]ProductTranslation{
+			{ProductID: exampleProduct.ID, Locale: "de", Name: "Rollbrett"},
+			{ProductID: exampleProduct.ID, Locale: "es", Name: "Monopatín"},
+		}))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/translations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := []ProductTranslation{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(&actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actual))
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationListHandlerForNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s/translations", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	translation := &ProductTranslation{
+		ProductID:   exampleProduct.ID,
+		Locale:      "de-AT",
+		Name:        "Rollbrett",
+		Description: NullString{sql.NullString{String: "Bitte einen Helm tragen.", Valid: true}},
+	}
+	query, args := buildProductTranslationUpsertQuery(translation)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(productTranslationRows([]ProductTranslation{*translation}))
+
+	body := `{"name": "Rollbrett", "description": "Bitte einen Helm tragen."}`
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/translations/de-at", exampleProduct.SKU), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductTranslation{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, "de-AT", actual.Locale)
+	assert.Equal(t, "", actual.Subtitle.String, "a missing subtitle should fall back to the product's own")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationUpsertHandlerWithInvalidLocale(t *testing.T) {
+	t.Parallel()
+
+	for _, locale := range []string{"en", "EN", "d"} {
+		testUtil := setupTestVariables(t)
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/translations/%s", exampleProduct.SKU, locale), strings.NewReader(`{"name": "Skateboard"}`))
+		assert.Nil(t, err)
+		attachSessionCookieToRequest(t, testUtil, req, 1, true)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400 for locale %s", locale)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	}
+}
+
+func TestProductTranslationUpsertHandlerWithInvalidInput(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/translations/de", exampleProduct.SKU), strings.NewReader(`{"subtitle": "nameless"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationUpsertHandlerWithDBError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	query, args := buildProductTranslationUpsertQuery(&ProductTranslation{ProductID: exampleProduct.ID, Locale: "de", Name: "Rollbrett"})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnError(arbitraryError)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/translations/de", exampleProduct.SKU), strings.NewReader(`{"name": "Rollbrett"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationUpsertHandlerWithoutAdminSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product/%s/translations/de", exampleProduct.SKU), strings.NewReader(`{"name": "Rollbrett"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productTranslationDeletionQuery)).
+		WithArgs(exampleProduct.ID, "de").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/translations/de", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductTranslationDeletionHandlerForNonexistentTranslation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productTranslationDeletionQuery)).
+		WithArgs(exampleProduct.ID, "de").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product/%s/translations/de", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionTranslationListHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	query, args := buildProductOptionTranslationListQuery(exampleProductOption.ID)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionTranslationHeaders).
+			AddRow(1, exampleProductOption.ID, "es", "algo", generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product_options/%d/translations", exampleProductOption.ID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionTranslationUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, true, nil)
+	translation := &ProductOptionTranslation{ProductOptionID: exampleProductOption.ID, Locale: "es", Name: "algo"}
+	query, args := buildProductOptionTranslationUpsertQuery(translation)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionTranslationHeaders).
+			AddRow(1, exampleProductOption.ID, "es", "algo", generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product_options/%d/translations/es", exampleProductOption.ID), strings.NewReader(`{"name": "algo"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionTranslationUpsertHandlerForNonexistentOption(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionExistenceByID(testUtil.Mock, exampleProductOption, false, nil)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product_options/%d/translations/es", exampleProductOption.ID), strings.NewReader(`{"name": "algo"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionTranslationDeletionHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionTranslationDeletionQuery)).
+		WithArgs("123", "es").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/product_options/123/translations/es", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueTranslationListHandlerForNonexistentValue(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, false, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product_option_values/%d/translations", exampleProductOptionValue.ID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueTranslationUpsertHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductOptionValueExistence(testUtil.Mock, exampleProductOptionValue, true, nil)
+	translation := &ProductOptionValueTranslation{ProductOptionValueID: exampleProductOptionValue.ID, Locale: "es", Value: "rojo"}
+	query, args := buildProductOptionValueTranslationUpsertQuery(translation)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WithArgs(argsToDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows(productOptionValueTranslationHeaders).
+			AddRow(1, exampleProductOptionValue.ID, "es", "rojo", generateExampleTimeForTests(), nil, nil))
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/v1/product_option_values/%d/translations/es", exampleProductOptionValue.ID), strings.NewReader(`{"value": "rojo"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductOptionValueTranslationDeletionHandlerForNonexistentTranslation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionValueTranslationDeletionQuery)).
+		WithArgs("256", "es").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/product_option_values/256/translations/es", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
 	"github.com/imdario/mergo"
 	"github.com/jmoiron/sqlx"
 )
@@ -38,6 +39,14 @@ const (
 		package_length,
 		quantity_per_package,
 		available_on,
+		status,
+		discontinued_on,
+		attributes,
+		digital,
+		reorder_threshold,
+		stock_policy,
+		max_oversell,
+		restock_on,
 		created_on,
 		updated_on,
 		archived_on
@@ -45,8 +54,19 @@ const (
 
 	skuExistenceQuery             = `SELECT EXISTS(SELECT 1 FROM products WHERE sku = $1 AND archived_on IS NULL)`
 	productExistenceQuery         = `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND archived_on IS NULL)`
-	productDeletionQuery          = `UPDATE products SET archived_on = NOW() WHERE sku = $1 AND archived_on IS NULL`
-	completeProductRetrievalQuery = `SELECT * FROM products WHERE sku = $1`
+	productDeletionQuery          = `UPDATE products SET archived_on = NOW() WHERE sku = $1 AND archived_on IS NULL RETURNING id`
+	completeProductRetrievalQuery = `SELECT * FROM products WHERE sku = $1 AND archived_on IS NULL`
+
+	// anyone who isn't an admin only gets to see products that have been released (or are up for pre-order until
+	// they are) and haven't been discontinued yet
+	publishedProductCondition        = `status IN ('scheduled', 'published') AND (available_on <= NOW() OR stock_policy = 'preorder') AND (discontinued_on IS NULL OR discontinued_on > NOW())`
+	publishedProductRetrievalQuery   = completeProductRetrievalQuery + ` AND ` + publishedProductCondition
+	publishedProductIDRetrievalQuery = productIDRetrievalQueryBySKU + ` AND ` + publishedProductCondition
+
+	// options and values are archived alongside their product, and share its archived_on so a restore can tell them
+	// apart from the ones that were deleted on their own beforehand. They have to go before the product itself does.
+	productOptionValuesDeletionQueryBySKU = `UPDATE product_option_values SET archived_on = NOW() WHERE product_option_id IN (SELECT o.id FROM product_options o JOIN products p ON p.id = o.product_id WHERE p.sku = $1 AND p.archived_on IS NULL) AND archived_on IS NULL`
+	productOptionsDeletionQueryBySKU      = `UPDATE product_options SET archived_on = NOW() WHERE product_id = (SELECT id FROM products WHERE sku = $1 AND archived_on IS NULL) AND archived_on IS NULL`
 )
 
 // Product describes something a user can buy
@@ -61,13 +81,35 @@ type Product struct {
 	Manufacturer NullString `json:"manufacturer"`
 	Brand        NullString `json:"brand"`
 	Quantity     int        `json:"quantity"`
+	// ReorderThreshold is the quantity at or below which purchasing is told to restock the product
+	ReorderThreshold *uint32 `json:"reorder_threshold,omitempty"`
+	// StockPolicy is one of deny, backorder, or preorder, and decides whether the product can still be ordered once
+	// it's out of stock. Backordered and pre-ordered products can be oversold by up to MaxOversell, if it's set.
+	StockPolicy string  `json:"stock_policy"`
+	MaxOversell *uint32 `json:"max_oversell,omitempty"`
+	// ClearMaxOversell is only ever sent in an update, to lift the limit on how far the product can be oversold,
+	// since leaving max_oversell out of an update leaves it as it was
+	ClearMaxOversell bool     `json:"clear_max_oversell,omitempty"`
+	RestockOn        NullTime `json:"restock_on"`
+	// Availability is derived from the product's stock and stock policy whenever it's rendered
+	Availability string `json:"availability"`
+	// Digital products are delivered as a file download rather than shipped
+	Digital bool `json:"digital,omitempty"`
+	// Locale is only set when the name, subtitle, and description have been translated out of the base locale
+	Locale string `json:"locale,omitempty"`
 
 	// Pricing Fields
-	Taxable   bool    `json:"taxable"`
-	Price     float32 `json:"price"`
-	OnSale    bool    `json:"on_sale"`
-	SalePrice float32 `json:"sale_price"`
-	Cost      float32 `json:"cost"`
+	Taxable   bool  `json:"taxable"`
+	Price     Money `json:"price"`
+	OnSale    bool  `json:"on_sale"`
+	SalePrice Money `json:"sale_price"`
+	Cost      Money `json:"cost"`
+	// Currency is only set when prices have been converted out of the base currency
+	Currency string `json:"currency,omitempty"`
+	// PriceTiers are the volume discounts for the product, which are stored in their own table
+	PriceTiers []ProductPriceTier `json:"price_tiers,omitempty"`
+	// CustomerGroupID is only set when Price comes from the price list of the requesting user's customer group
+	CustomerGroupID uint64 `json:"customer_group_id,omitempty"`
 
 	// Product Dimensions
 	ProductWeight float32 `json:"product_weight"`
@@ -84,10 +126,62 @@ type Product struct {
 	QuantityPerPackage int32 `json:"quantity_per_package"`
 
 	AvailableOn time.Time `json:"available_on"`
+	// Status is one of draft, scheduled, published, or discontinued. Scheduled and published products are only
+	// visible to non-admins once they're available (or up for pre-order), and only until they're discontinued.
+	Status         string   `json:"status"`
+	DiscontinuedOn NullTime `json:"discontinued_on"`
+
+	// Attributes are the values of the store's custom attributes, like a book's ISBN or a shirt's material
+	Attributes ProductAttributes `json:"attributes"`
+
+	// Bundle is only set for products made up of other products, whose quantity is derived from those products
+	Bundle *ProductBundle `json:"bundle,omitempty"`
+
+	// Relations are only included when a single product is requested with `include=relations`
+	Relations []ProductRelation `json:"relations,omitempty"`
+
+	// Review aggregates, which aren't stored alongside the product
+	AverageRating float64 `json:"average_rating,omitempty"`
+	ReviewCount   uint64  `json:"review_count,omitempty"`
+}
+
+const (
+	productStatusDraft        = "draft"
+	productStatusScheduled    = "scheduled"
+	productStatusPublished    = "published"
+	productStatusDiscontinued = "discontinued"
+)
+
+func productStatusIsValid(status string) bool {
+	switch status {
+	case productStatusDraft, productStatusScheduled, productStatusPublished, productStatusDiscontinued:
+		return true
+	}
+	return false
 }
 
 // newProductFromCreationInput creates a new product from a ProductCreationInput
 func newProductFromCreationInput(in *ProductCreationInput) *Product {
+	// products have always been visible as soon as they're created, so that stays the default
+	status := in.Status
+	if status == "" {
+		status = productStatusPublished
+		if in.AvailableOn.After(time.Now()) {
+			status = productStatusScheduled
+		}
+	}
+
+	stockPolicy := in.StockPolicy
+	if stockPolicy == "" {
+		stockPolicy = stockPolicyDeny
+	}
+
+	// products without attributes are stored with an empty set of them, so they're rendered that way from the start
+	attributes := in.Attributes.withoutNulls()
+	if attributes == nil {
+		attributes = ProductAttributes{}
+	}
+
 	np := &Product{
 		Name:               in.Name,
 		Subtitle:           NullString{sql.NullString{String: in.Subtitle, Valid: true}},
@@ -97,6 +191,11 @@ func newProductFromCreationInput(in *ProductCreationInput) *Product {
 		Manufacturer:       NullString{sql.NullString{String: in.Manufacturer, Valid: true}},
 		Brand:              NullString{sql.NullString{String: in.Brand, Valid: true}},
 		Quantity:           in.Quantity,
+		ReorderThreshold:   in.ReorderThreshold,
+		StockPolicy:        stockPolicy,
+		MaxOversell:        in.MaxOversell,
+		RestockOn:          in.RestockOn,
+		Digital:            in.Digital,
 		Taxable:            in.Taxable,
 		Price:              in.Price,
 		OnSale:             in.OnSale,
@@ -112,6 +211,9 @@ func newProductFromCreationInput(in *ProductCreationInput) *Product {
 		PackageLength:      in.PackageLength,
 		QuantityPerPackage: in.QuantityPerPackage,
 		AvailableOn:        in.AvailableOn,
+		Status:             status,
+		DiscontinuedOn:     in.DiscontinuedOn,
+		Attributes:         attributes,
 	}
 	return np
 }
@@ -133,13 +235,20 @@ type ProductCreationInput struct {
 	Manufacturer string `json:"manufacturer"`
 	Brand        string `json:"brand"`
 	Quantity     int    `json:"quantity"`
+	Digital      bool   `json:"digital"`
+
+	ReorderThreshold *uint32 `json:"reorder_threshold"`
+
+	StockPolicy string   `json:"stock_policy"`
+	MaxOversell *uint32  `json:"max_oversell"`
+	RestockOn   NullTime `json:"restock_on"`
 
 	// Pricing Fields
-	Taxable   bool    `json:"taxable"`
-	Price     float32 `json:"price"`
-	OnSale    bool    `json:"on_sale"`
-	SalePrice float32 `json:"sale_price"`
-	Cost      float32 `json:"cost"`
+	Taxable   bool  `json:"taxable"`
+	Price     Money `json:"price"`
+	OnSale    bool  `json:"on_sale"`
+	SalePrice Money `json:"sale_price"`
+	Cost      Money `json:"cost"`
 
 	// Product Dimensions
 	ProductWeight float32 `json:"product_weight"`
@@ -154,10 +263,19 @@ type ProductCreationInput struct {
 	PackageLength      float32 `json:"package_length"`
 	QuantityPerPackage int32   `json:"quantity_per_package"`
 
-	AvailableOn time.Time `json:"available_on"`
+	AvailableOn    time.Time `json:"available_on"`
+	Status         string    `json:"status"`
+	DiscontinuedOn NullTime  `json:"discontinued_on"`
+
+	Attributes ProductAttributes `json:"attributes"`
 
 	// Other things
 	Options []*ProductOptionCreationInput `json:"options"`
+
+	// Bundles
+	Components           []*ProductBundleComponentInput `json:"components"`
+	BundleDiscountType   string                         `json:"bundle_discount_type"`
+	BundleDiscountAmount Money                          `json:"bundle_discount_amount"`
 }
 
 func buildProductExistenceHandler(db *sqlx.DB) http.HandlerFunc {
@@ -186,12 +304,44 @@ func retrieveProductFromDB(db *sqlx.DB, sku string) (Product, error) {
 	return p, err
 }
 
-func buildSingleProductHandler(db *sqlx.DB) http.HandlerFunc {
+// retrievePublishedProductFromDB retrieves a product with a given SKU from the database, so long as it's published
+func retrievePublishedProductFromDB(db *sqlx.DB, sku string) (Product, error) {
+	var p Product
+	err := db.Get(&p, publishedProductRetrievalQuery, sku)
+	return p, err
+}
+
+// retrieveProductForSession retrieves a product with a given SKU from the database, treating products that
+// aren't published as nonexistent unless the session belongs to an admin, since admins can preview them
+func retrieveProductForSession(db *sqlx.DB, req *http.Request, store *sessions.CookieStore, sku string) (Product, error) {
+	if sessionBelongsToAdmin(req, store) {
+		return retrieveProductFromDB(db, sku)
+	}
+	return retrievePublishedProductFromDB(db, sku)
+}
+
+// retrieveProductIDForSession is retrieveProductForSession for handlers that only need the product's ID
+func retrieveProductIDForSession(db *sqlx.DB, req *http.Request, store *sessions.CookieStore, sku string) (uint64, error) {
+	if sessionBelongsToAdmin(req, store) {
+		return retrieveProductIDBySKU(db, sku)
+	}
+	var id uint64
+	err := db.Get(&id, publishedProductIDRetrievalQuery, sku)
+	return id, err
+}
+
+func buildSingleProductHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
 	// SingleProductHandler is a request handler that returns a single Product
 	return func(res http.ResponseWriter, req *http.Request) {
 		sku := chi.URLParam(req, "sku")
+		currency, err := parseCurrencyParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
 
-		product, err := retrieveProductFromDB(db, sku)
+		isAdmin := sessionBelongsToAdmin(req, store)
+		product, err := retrieveProductForSession(db, req, store, sku)
 		if err == sql.ErrNoRows {
 			respondThatRowDoesNotExist(req, res, "product", sku)
 			return
@@ -200,21 +350,112 @@ func buildSingleProductHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		json.NewEncoder(res).Encode(product)
+		// related products go through the same pricing steps as the product itself, so they're
+		// retrieved up front and ride along in the same batch
+		products := []Product{product}
+		includeRelations := includeRequested(req, "relations")
+		var relations []ProductRelation
+		if includeRelations {
+			relations, err = retrieveProductRelations(db, product.ID)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product relations from the database")
+				return
+			}
+			related, err := retrieveRelatedProducts(db, relations, !isAdmin)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve related products from the database")
+				return
+			}
+			products = append(products, related...)
+		}
+
+		err = translateProducts(db, products, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product translations from the database")
+			return
+		}
+
+		err = attachReviewSummariesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product reviews from the database")
+			return
+		}
+
+		err = attachPriceTiersToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product price tiers from the database")
+			return
+		}
+
+		err = attachBundlesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundles from the database")
+			return
+		}
+		deriveProductAvailabilities(products)
+
+		err = applyCustomerGroupPrices(db, store, req, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group prices from the database")
+			return
+		}
+
+		err = convertProductPrices(db, products, currency)
+		if err != nil {
+			notifyOfCurrencyConversionFailure(res, err)
+			return
+		}
+
+		if includeRelations {
+			attachRelationsToProduct(&products[0], relations, products[1:])
+		}
+		json.NewEncoder(res).Encode(products[0])
 	}
 }
 
-func buildProductListHandler(db *sqlx.DB) http.HandlerFunc {
+func buildProductListHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
 	// productListHandler is a request handler that returns a list of products
 	return func(res http.ResponseWriter, req *http.Request) {
 		rawFilterParams := req.URL.Query()
-		queryFilter := parseRawFilterParams(rawFilterParams)
-		count, err := getRowCount(db, "products", queryFilter)
+		queryFilter, err := parseRawFilterParams(rawFilterParams)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		currency, err := parseCurrencyParam(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		queryFilter.Archived, err = parseArchivedFilterParam(rawFilterParams)
 		if err != nil {
-			notifyOfInternalIssue(res, err, "retrieve count of products from the database")
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		isAdmin := sessionBelongsToAdmin(req, store)
+		if queryFilter.Archived != "" && !isAdmin {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+		queryFilter.PublishedOnly = !isAdmin
+		queryFilter.Attributes, err = parseAttributeFilterParams(db, rawFilterParams)
+		if _, ok := err.(*attributeFilterError); ok {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attributes from the database")
 			return
 		}
 
+		var count uint64
+		if !queryFilter.SkipCount {
+			count, err = getRowCount(db, "products", queryFilter)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve count of products from the database")
+				return
+			}
+		}
+
 		var products []Product
 		query, args := buildProductListQuery(queryFilter)
 		err = retrieveListOfRowsFromDB(db, query, args, &products)
@@ -223,24 +464,81 @@ func buildProductListHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		err = translateProducts(db, products, negotiateLocales(req))
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product translations from the database")
+			return
+		}
+
+		err = attachReviewSummariesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product reviews from the database")
+			return
+		}
+
+		err = attachPriceTiersToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product price tiers from the database")
+			return
+		}
+
+		err = attachBundlesToProducts(db, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundles from the database")
+			return
+		}
+		deriveProductAvailabilities(products)
+
+		err = applyCustomerGroupPrices(db, store, req, products)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve customer group prices from the database")
+			return
+		}
+
+		err = convertProductPrices(db, products, currency)
+		if err != nil {
+			notifyOfCurrencyConversionFailure(res, err)
+			return
+		}
+
 		productsResponse := &ProductsResponse{
-			ListResponse: ListResponse{
-				Page:  queryFilter.Page,
-				Limit: queryFilter.Limit,
-				Count: count,
-			},
-			Data: products,
+			ListResponse: newListResponse(queryFilter, count),
+			Data:         products,
+		}
+		if len(products) > 0 {
+			productsResponse.NextCursor = buildNextCursor(queryFilter, len(products), products[len(products)-1].DBRow)
 		}
 		json.NewEncoder(res).Encode(productsResponse)
 	}
 }
 
-func deleteProductBySKU(db *sqlx.DB, sku string) error {
-	_, err := db.Exec(productDeletionQuery, sku)
-	return err
+// deleteProductBySKU archives a product along with its options and their values
+func deleteProductBySKU(db *sqlx.DB, sku string, userID uint64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{productOptionValuesDeletionQueryBySKU, productOptionsDeletionQueryBySKU} {
+		if _, err = tx.Exec(query, sku); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	var productID uint64
+	if err = tx.QueryRow(productDeletionQuery, sku).Scan(&productID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = recordProductRevision(tx, productID, "archive", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func buildProductDeletionHandler(db *sqlx.DB) http.HandlerFunc {
+func buildProductDeletionHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
 	// ProductDeletionHandler is a request handler that deletes a single product
 	return func(res http.ResponseWriter, req *http.Request) {
 		sku := chi.URLParam(req, "sku")
@@ -252,18 +550,56 @@ func buildProductDeletionHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		err = deleteProductBySKU(db, sku)
+		userID, _ := retrieveUserIDFromSession(req, store)
+		err = deleteProductBySKU(db, sku, userID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive product in database")
+			return
+		}
 		io.WriteString(res, fmt.Sprintf("Successfully deleted product `%s`", sku))
 	}
 }
 
-func updateProductInDatabase(db *sqlx.DB, up *Product) error {
+// updateProductInDatabase updates a product and records the result as its next revision. It returns
+// errInventoryAdjustmentRefused if the update would lower the product's quantity past what's reserved.
+func updateProductInDatabase(db *sqlx.DB, up *Product, userID uint64) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	// products stocked at locations keep their derived quantity, so they're left to the stock checks at their locations
+	var quantity, held int
+	var stockedAtLocations bool
+	if err = tx.QueryRow(productStockAndHoldsQuery, up.ID).Scan(&quantity, &held, &stockedAtLocations); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !stockedAtLocations && up.Quantity < quantity && !up.canBeTakenTo(up.Quantity-held, time.Now()) {
+		tx.Rollback()
+		return errInventoryAdjustmentRefused
+	}
+
 	productUpdateQuery, queryArgs := buildProductUpdateQuery(up)
-	err := db.QueryRowx(productUpdateQuery, queryArgs...).StructScan(up)
-	return err
+	err = tx.QueryRowx(productUpdateQuery, queryArgs...).StructScan(up)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !stockedAtLocations {
+		if err = recordQuantityCorrection(tx.Tx, up.ID, quantity, up.Quantity, userID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = recordProductRevision(tx.Tx, up.ID, "update", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func buildProductUpdateHandler(db *sqlx.DB) http.HandlerFunc {
+func buildProductUpdateHandler(db *sqlx.DB, store *sessions.CookieStore, notifier LowStockNotifier) http.HandlerFunc {
 	// ProductUpdateHandler is a request handler that can update products
 	return func(res http.ResponseWriter, req *http.Request) {
 		sku := chi.URLParam(req, "sku")
@@ -275,6 +611,15 @@ func buildProductUpdateHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		if err = normalizeProductUPC(&newerProduct.UPC.String); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if newerProduct.ClearMaxOversell && newerProduct.MaxOversell != nil {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("max_oversell can't be set and cleared at the same time"))
+			return
+		}
+
 		existingProduct, err := retrieveProductFromDB(db, sku)
 		if err == sql.ErrNoRows {
 			respondThatRowDoesNotExist(req, res, "product", sku)
@@ -284,6 +629,9 @@ func buildProductUpdateHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		// a request without attributes leaves them be, whatever merging makes of the missing map
+		attributesProvided := newerProduct.Attributes != nil
+
 		// eating the error here because we've already validated input
 		mergo.Merge(newerProduct, &existingProduct)
 
@@ -291,12 +639,46 @@ func buildProductUpdateHandler(db *sqlx.DB) http.HandlerFunc {
 			notifyOfInvalidRequestBody(res, fmt.Errorf("The sku received (%s) is invalid", newerProduct.SKU))
 			return
 		}
-		err = updateProductInDatabase(db, newerProduct)
-		if err != nil {
+		if !productStatusIsValid(newerProduct.Status) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The status received (%s) is invalid", newerProduct.Status))
+			return
+		}
+		if !stockPolicyIsValid(newerProduct.StockPolicy) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The stock policy received (%s) is invalid", newerProduct.StockPolicy))
+			return
+		}
+		if newerProduct.ClearMaxOversell {
+			newerProduct.MaxOversell = nil
+		}
+
+		if attributesProvided {
+			newerProduct.Attributes = newerProduct.Attributes.withoutNulls()
+			definitions, err := retrieveProductAttributeDefinitions(db, nil)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve product attributes from the database")
+				return
+			}
+			if err = validateProductAttributes(definitions, newerProduct.Attributes); err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+		} else {
+			newerProduct.Attributes = nil
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		err = updateProductInDatabase(db, newerProduct, userID)
+		if err == errInventoryAdjustmentRefused {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("the quantity can't be lowered to %d while reservations are holding more stock than that", newerProduct.Quantity))
+			return
+		} else if err != nil {
 			notifyOfInternalIssue(res, err, "update product in database")
 			return
 		}
+		checkProductForLowStock(db, notifier, newerProduct.ID)
 
+		newerProduct.ClearMaxOversell = false
+		newerProduct.Availability = newerProduct.availability(time.Now())
 		json.NewEncoder(res).Encode(newerProduct)
 	}
 }
@@ -309,7 +691,7 @@ func createProductInDB(tx *sql.Tx, np *Product) (uint64, error) {
 	return newProductID, err
 }
 
-func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
+func buildProductCreationHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		productInput := &ProductCreationInput{}
 		err := validateRequestInput(req, productInput)
@@ -321,6 +703,24 @@ func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
 			notifyOfInvalidRequestBody(res, fmt.Errorf("The sku received (%s) is invalid", productInput.SKU))
 			return
 		}
+		if productInput.Status != "" && !productStatusIsValid(productInput.Status) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The status received (%s) is invalid", productInput.Status))
+			return
+		}
+		if productInput.StockPolicy != "" && !stockPolicyIsValid(productInput.StockPolicy) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The stock policy received (%s) is invalid", productInput.StockPolicy))
+			return
+		}
+		if err = normalizeProductUPC(&productInput.UPC); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		for _, option := range productInput.Options {
+			if err = validateProductOptionCreationInput(option); err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+		}
 
 		// can't create a product with a sku that already exists!
 		exists, err := rowExistsInDB(db, skuExistenceQuery, productInput.SKU)
@@ -329,6 +729,30 @@ func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		definitions, err := retrieveProductAttributeDefinitions(db, nil)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product attributes from the database")
+			return
+		}
+		if err = validateProductAttributes(definitions, productInput.Attributes.withoutNulls()); err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		var bundle *ProductBundle
+		if len(productInput.Components) > 0 {
+			components, err := validateBundleInput(db, productInput)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+			bundle = &ProductBundle{
+				DiscountType:   productInput.BundleDiscountType,
+				DiscountAmount: productInput.BundleDiscountAmount,
+				Components:     components,
+			}
+		}
+
 		tx, err := db.Begin()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "create new database transaction")
@@ -336,6 +760,9 @@ func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
 		}
 
 		newProduct := newProductFromCreationInput(productInput)
+		if bundle != nil {
+			bundle.apply(newProduct)
+		}
 		newProductID, err := createProductInDB(tx, newProduct)
 		if err != nil {
 			tx.Rollback()
@@ -344,6 +771,16 @@ func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
 		}
 		newProduct.ID = newProductID
 
+		userID, _ := retrieveUserIDFromSession(req, store)
+		// bundles don't have stock of their own, just what their components add up to
+		if bundle == nil {
+			if err = recordInitialStock(tx, newProduct.ID, newProduct.Quantity, userID); err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "record initial stock in database")
+				return
+			}
+		}
+
 		for _, optionAndValues := range productInput.Options {
 			_, err = createProductOptionAndValuesInDBFromInput(tx, optionAndValues, newProduct.ID)
 			if err != nil {
@@ -353,12 +790,29 @@ func buildProductCreationHandler(db *sqlx.DB) http.HandlerFunc {
 			}
 		}
 
+		if bundle != nil {
+			err = createProductBundleInDB(tx, newProduct.ID, bundle)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "insert product bundle in database")
+				return
+			}
+		}
+
+		err = recordProductRevision(tx, newProduct.ID, "create", userID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "record product revision in database")
+			return
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "closing out transaction")
 			return
 		}
 
+		newProduct.Availability = newProduct.availability(time.Now())
 		res.WriteHeader(http.StatusCreated)
 		json.NewEncoder(res).Encode(newProduct)
 	}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -17,14 +18,13 @@ import (
 
 const (
 	badSKUUpdateJSON = `{"sku": "pooƃ ou sᴉ nʞs sᴉɥʇ"}`
-	lolFloats        = 12.34000015258789
 
 	exampleProductUpdateInput = `
 		{
 			"sku": "example",
 			"name": "Test",
 			"quantity": 666,
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"price": 12.34
 		}
 	`
@@ -47,12 +47,12 @@ func init() {
 		SKU:  "skateboard",
 		Name: "Skateboard",
 		// Subtitle:      NullString{sql.NullString{String: "", Valid: true}},
-		UPC: NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC: NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		// Manufacturer:  NullString{sql.NullString{String: "", Valid: true}},
 		// Brand:         NullString{sql.NullString{String: "", Valid: true}},
 		Quantity:      123,
-		Price:         99.99,
-		Cost:          50.00,
+		Price:         9999,
+		Cost:          5000,
 		Description:   "This is a skateboard. Please wear a helmet.",
 		ProductWeight: 8,
 		ProductHeight: 7,
@@ -63,6 +63,9 @@ func init() {
 		PackageWidth:  2,
 		PackageLength: 1,
 		AvailableOn:   generateExampleTimeForTests(),
+		Status:        productStatusPublished,
+		StockPolicy:   stockPolicyDeny,
+		Attributes:    ProductAttributes{},
 	}
 	exampleProduct.Subtitle.Valid = true
 	exampleProduct.Manufacturer.Valid = true
@@ -80,10 +83,10 @@ func init() {
 		exampleProduct.Brand.String,
 		exampleProduct.Quantity,
 		exampleProduct.Taxable,
-		exampleProduct.Price,
+		exampleProduct.Price.String(),
 		exampleProduct.OnSale,
-		exampleProduct.SalePrice,
-		exampleProduct.Cost,
+		exampleProduct.SalePrice.String(),
+		exampleProduct.Cost.String(),
 		exampleProduct.ProductWeight,
 		exampleProduct.ProductHeight,
 		exampleProduct.ProductWidth,
@@ -94,6 +97,14 @@ func init() {
 		exampleProduct.PackageLength,
 		exampleProduct.QuantityPerPackage,
 		exampleProduct.AvailableOn,
+		exampleProduct.Status,
+		nil,
+		[]byte("{}"),
+		exampleProduct.Digital,
+		nil,
+		exampleProduct.StockPolicy,
+		nil,
+		nil,
 		exampleProduct.CreatedOn,
 		nil,
 		nil,
@@ -106,10 +117,10 @@ func init() {
 		},
 		SKU:      "example",
 		Name:     "Test",
-		UPC:      NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC:      NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		Quantity: 666,
-		Cost:     50.00,
-		Price:    lolFloats,
+		Cost:     5000,
+		Price:    1234,
 	}
 
 }
@@ -136,7 +147,7 @@ func setExpectationsForProductListQuery(mock sqlmock.Sqlmock, err error) {
 		AddRow(exampleProductData...).
 		AddRow(exampleProductData...)
 
-	allProductsRetrievalQuery, _ := buildProductListQuery(defaultQueryFilter)
+	allProductsRetrievalQuery, _ := buildProductListQuery(publishedQueryFilter)
 	mock.ExpectQuery(formatQueryForSQLMock(allProductsRetrievalQuery)).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
@@ -151,14 +162,55 @@ func setExpectationsForProductRetrieval(mock sqlmock.Sqlmock, sku string, err er
 		WillReturnError(err)
 }
 
+func setExpectationsForPublishedProductRetrieval(mock sqlmock.Sqlmock, sku string, err error) {
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	mock.ExpectQuery(formatQueryForSQLMock(publishedProductRetrievalQuery)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+// examplePreorderProductData is exampleProductData for a product that's up for pre-order and hasn't come out yet
+func examplePreorderProductData() []driver.Value {
+	data := append([]driver.Value{}, exampleProductData...)
+	for i, header := range productHeaders {
+		switch header {
+		case "available_on":
+			data[i] = time.Now().Add(7 * 24 * time.Hour)
+		case "stock_policy":
+			data[i] = stockPolicyPreorder
+		}
+	}
+	return data
+}
+
+func setExpectationsForPublishedProductIDRetrievalBySKU(mock sqlmock.Sqlmock, sku string, id uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id"})
+	if id != 0 {
+		exampleRows = exampleRows.AddRow(id)
+	}
+	mock.ExpectQuery(formatQueryForSQLMock(publishedProductIDRetrievalQuery)).
+		WithArgs(sku).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
 func setExpectationsForProductUpdate(mock sqlmock.Sqlmock, p *Product, err error) {
 	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
 	productUpdateQuery, queryArgs := buildProductUpdateQuery(p)
 	args := argsToDriverValues(queryArgs)
+	mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(mock, p.ID, p.Quantity, 0, false)
 	mock.ExpectQuery(formatQueryForSQLMock(productUpdateQuery)).
 		WithArgs(args...).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
+	if err != nil {
+		mock.ExpectRollback()
+		return
+	}
+	setExpectationsForProductRevisionCreation(mock, exampleProduct.ID, "update", 0, nil)
+	mock.ExpectCommit()
 }
 
 func setExpectationsForProductCreation(mock sqlmock.Sqlmock, p *Product, err error) {
@@ -173,7 +225,10 @@ func setExpectationsForProductCreation(mock sqlmock.Sqlmock, p *Product, err err
 
 func setExpectationsForProductUpdateHandler(mock sqlmock.Sqlmock, p *Product, err error) {
 	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
-	productUpdateQuery, _ := buildProductUpdateQuery(exampleProduct)
+	// the status and stock policy come from the existing product, but availability is only updated when it's given
+	productUpdateQuery, _ := buildProductUpdateQuery(&Product{Status: exampleProduct.Status, StockPolicy: exampleProduct.StockPolicy})
+	mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(mock, exampleProduct.ID, exampleProduct.Quantity, 0, false)
 	mock.ExpectQuery(formatQueryForSQLMock(productUpdateQuery)).
 		WithArgs(
 			p.Cost,
@@ -181,16 +236,33 @@ func setExpectationsForProductUpdateHandler(mock sqlmock.Sqlmock, p *Product, er
 			p.Price,
 			p.Quantity,
 			p.SKU,
+			exampleProduct.Status,
+			exampleProduct.StockPolicy,
 			p.UPC.String,
 			p.ID,
 		).WillReturnRows(exampleRows).
 		WillReturnError(err)
+	if err != nil {
+		mock.ExpectRollback()
+		return
+	}
+	setExpectationsForProductRevisionCreation(mock, exampleProduct.ID, "update", 0, nil)
+	mock.ExpectCommit()
 }
 
 func setExpectationsForProductDeletion(mock sqlmock.Sqlmock, sku string) {
-	mock.ExpectExec(formatQueryForSQLMock(productDeletionQuery)).
+	mock.ExpectBegin()
+	mock.ExpectExec(formatQueryForSQLMock(productOptionValuesDeletionQueryBySKU)).
+		WithArgs(sku).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(formatQueryForSQLMock(productOptionsDeletionQueryBySKU)).
 		WithArgs(sku).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(formatQueryForSQLMock(productDeletionQuery)).
+		WithArgs(sku).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(exampleProduct.ID))
+	setExpectationsForProductRevisionCreation(mock, exampleProduct.ID, "archive", 0, nil)
+	mock.ExpectCommit()
 }
 
 func TestRetrieveProductFromDB(t *testing.T) {
@@ -222,7 +294,7 @@ func TestDeleteProductBySKU(t *testing.T) {
 
 	setExpectationsForProductDeletion(testUtil.Mock, exampleSKU)
 
-	err := deleteProductBySKU(testUtil.DB, exampleSKU)
+	err := deleteProductBySKU(testUtil.DB, exampleSKU, 0)
 	assert.Nil(t, err)
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
@@ -233,7 +305,7 @@ func TestUpdateProductInDatabase(t *testing.T) {
 
 	setExpectationsForProductUpdate(testUtil.Mock, exampleProduct, nil)
 
-	err := updateProductInDatabase(testUtil.DB, exampleProduct)
+	err := updateProductInDatabase(testUtil.DB, exampleProduct, 0)
 	assert.Nil(t, err)
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
@@ -311,12 +383,37 @@ func TestProductRetrievalHandlerWithExistingProduct(t *testing.T) {
 	testUtil := setupTestVariables(t)
 
 	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
 
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
 	assert.Nil(t, err)
 
 	testUtil.Router.ServeHTTP(testUtil.Response, req)
 	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, 4.5, actual.AverageRating, "product should include its average rating")
+	assert.Equal(t, uint64(2), actual.ReviewCount, "product should include its review count")
+	assert.Equal(t, productAvailabilityInStock, actual.Availability, "product should include its availability")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerWithErrorRetrievingReviews(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, arbitraryError)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
@@ -334,6 +431,80 @@ func TestProductRetrievalHandlerWithDBError(t *testing.T) {
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
+func TestProductRetrievalHandlerForPreorderProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// pre-orders count as published before they come out, so non-admins can see them
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(examplePreorderProductData()...)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(publishedProductRetrievalQuery)).
+		WithArgs(exampleProduct.SKU).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, productAvailabilityPreorder, actual.Availability)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerForUnpublishedProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductRetrieval(testUtil.Mock, exampleProduct.SKU, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductRetrievalHandlerLetsAdminsPreviewDrafts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	draftData := make([]driver.Value, len(exampleProductData))
+	copy(draftData, exampleProductData)
+	for i, header := range productHeaders {
+		if header == "status" {
+			draftData[i] = productStatusDraft
+		}
+	}
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(completeProductRetrievalQuery) + "$").
+		WithArgs(exampleProduct.SKU).
+		WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(draftData...))
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, productStatusDraft, actual.Status, "admins should be able to see draft products")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestProductRetrievalHandlerWithNonexistentProduct(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -352,8 +523,11 @@ func TestProductListHandler(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
 
-	setExpectationsForRowCount(testUtil.Mock, "products", defaultQueryFilter, 3, nil)
+	setExpectationsForRowCount(testUtil.Mock, "products", publishedQueryFilter, 3, nil)
 	setExpectationsForProductListQuery(testUtil.Mock, nil)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil, nil)
 
 	req, err := http.NewRequest(http.MethodGet, "/v1/products", nil)
 	assert.Nil(t, err)
@@ -365,7 +539,6 @@ func TestProductListHandler(t *testing.T) {
 		ListResponse: ListResponse{
 			Page:  1,
 			Limit: 25,
-			Count: 3,
 		},
 	}
 
@@ -375,8 +548,128 @@ func TestProductListHandler(t *testing.T) {
 
 	assert.Equal(t, expected.Page, actual.Page, "expected and actual product pages should be equal")
 	assert.Equal(t, expected.Limit, actual.Limit, "expected and actual product limits should be equal")
-	assert.Equal(t, expected.Count, actual.Count, "expected and actual product counts should be equal")
-	assert.Equal(t, uint64(len(actual.Data)), actual.Count, "actual product counts and product response count field should be equal")
+	assert.Equal(t, uint64(3), *actual.Count, "expected and actual product counts should be equal")
+	assert.Equal(t, uint64(len(actual.Data)), *actual.Count, "actual product counts and product response count field should be equal")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithArchivedProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	queryFilter := &QueryFilter{Page: 1, Limit: 25, Archived: archivedRowsOnly}
+
+	setExpectationsForRowCount(testUtil.Mock, "products", queryFilter, 1, nil)
+	exampleRows := sqlmock.NewRows(productHeaders).AddRow(exampleProductData...)
+	query, _ := buildProductListQuery(queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?archived=only", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductsResponse{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), *actual.Count, "archived products should be counted")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerForAdminIncludesUnpublishedProducts(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForRowCount(testUtil.Mock, "products", defaultQueryFilter, 1, nil)
+	query, _ := buildProductListQuery(defaultQueryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query) + "$").
+		WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(exampleProductData...))
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID}, nil, nil)
+	setExpectationsForCustomerGroupIDRetrievalForUser(testUtil.Mock, 1, 0, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithArchivedProductsForNonAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?archived=include", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithInvalidArchivedFilter(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?archived=yes", nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithCursor(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	queryFilter := &QueryFilter{Page: 1, Limit: 3, UseCursor: true, SkipCount: true, PublishedOnly: true}
+	exampleRows := sqlmock.NewRows(productHeaders).
+		AddRow(exampleProductData...).
+		AddRow(exampleProductData...).
+		AddRow(exampleProductData...)
+	query, _ := buildProductListQuery(queryFilter)
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows)
+	setExpectationsForProductReviewSummaries(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil)
+	setExpectationsForProductPriceTierList(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil, nil)
+	setExpectationsForProductBundleList(testUtil.Mock, []uint64{exampleProduct.ID, exampleProduct.ID, exampleProduct.ID}, nil, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?cursor=&limit=3", nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &ProductsResponse{}
+	err = json.NewDecoder(strings.NewReader(testUtil.Response.Body.String())).Decode(actual)
+	assert.Nil(t, err)
+
+	expectedCursor := encodeListCursor(&ListCursor{CreatedOn: exampleProduct.CreatedOn, ID: exampleProduct.ID})
+	assert.Equal(t, expectedCursor, actual.NextCursor, "a full page of products should come with a cursor for the next one")
+	assert.Nil(t, actual.Count, "cursor pagination shouldn't count products unless asked to")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductListHandlerWithInvalidCursor(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/products?cursor=not_a_real_cursor", nil)
+	assert.Nil(t, err)
+
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
@@ -384,7 +677,7 @@ func TestProductListHandlerWithErrorRetrievingCount(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
 
-	setExpectationsForRowCount(testUtil.Mock, "products", defaultQueryFilter, 3, arbitraryError)
+	setExpectationsForRowCount(testUtil.Mock, "products", publishedQueryFilter, 3, arbitraryError)
 
 	req, err := http.NewRequest(http.MethodGet, "/v1/products", nil)
 	assert.Nil(t, err)
@@ -399,7 +692,7 @@ func TestProductListHandlerWithDBError(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
 
-	setExpectationsForRowCount(testUtil.Mock, "products", defaultQueryFilter, 3, nil)
+	setExpectationsForRowCount(testUtil.Mock, "products", publishedQueryFilter, 3, nil)
 	setExpectationsForProductListQuery(testUtil.Mock, arbitraryError)
 
 	req, err := http.NewRequest(http.MethodGet, "/v1/products", nil)
@@ -416,6 +709,7 @@ func TestProductUpdateHandler(t *testing.T) {
 
 	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
 	setExpectationsForProductUpdateHandler(testUtil.Mock, exampleUpdatedProduct, nil)
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
 
 	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(exampleProductUpdateInput))
 	assert.Nil(t, err)
@@ -465,6 +759,206 @@ func TestProductUpdateHandlerWithSKUValidationError(t *testing.T) {
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
+func TestProductUpdateHandlerWithStatusValidationError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"status": "hidden"}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerWithStockPolicyValidationError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"stock_policy": "whenever"}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerChangingQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	updatedData := append([]driver.Value{}, exampleProductData...)
+	for i, header := range productHeaders {
+		if header == "quantity" {
+			updatedData[i] = 150
+		}
+	}
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(testUtil.Mock, exampleProduct.ID, exampleProduct.Quantity, 0, false)
+	productUpdateQuery, _ := buildProductUpdateQuery(&Product{Status: exampleProduct.Status, StockPolicy: exampleProduct.StockPolicy})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productUpdateQuery)).
+		WithArgs(
+			exampleProduct.Cost,
+			exampleProduct.Name,
+			exampleProduct.Price,
+			150,
+			exampleProduct.SKU,
+			exampleProduct.Status,
+			exampleProduct.StockPolicy,
+			exampleProduct.UPC.String,
+			exampleProduct.ID,
+		).WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(updatedData...))
+	// setting the quantity directly is recorded in the product's ledger as a correction
+	setExpectationsForStockRecord(testUtil.Mock, &InventoryMovement{
+		ProductID:     exampleProduct.ID,
+		Delta:         27,
+		QuantityAfter: 150,
+		Reason:        inventoryMovementReasonCorrection,
+		Reference:     "product update",
+	})
+	setExpectationsForProductRevisionCreation(testUtil.Mock, exampleProduct.ID, "update", 0, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"quantity": 150}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerWithAttributes(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+	attributes := ProductAttributes{"material": "oak"}
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, []ProductAttributeDefinition{exampleProductAttributeDefinition}, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(testUtil.Mock, exampleProduct.ID, exampleProduct.Quantity, 0, false)
+	productUpdateQuery, _ := buildProductUpdateQuery(&Product{Status: exampleProduct.Status, StockPolicy: exampleProduct.StockPolicy, Attributes: attributes})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productUpdateQuery)).
+		WithArgs(
+			`{"material":"oak"}`,
+			exampleProduct.Cost,
+			exampleProduct.Name,
+			exampleProduct.Price,
+			exampleProduct.Quantity,
+			exampleProduct.SKU,
+			exampleProduct.Status,
+			exampleProduct.StockPolicy,
+			exampleProduct.UPC.String,
+			exampleProduct.ID,
+		).WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(exampleProductData...))
+	setExpectationsForProductRevisionCreation(testUtil.Mock, exampleProduct.ID, "update", 0, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"attributes": {"material": "oak"}}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerWithAttributeValidationError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, []ProductAttributeDefinition{exampleProductAttributeDefinition}, nil)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"attributes": {"material": "pine"}}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerClearingMaxOversell(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	existingData := append([]driver.Value{}, exampleProductData...)
+	for i, header := range productHeaders {
+		if header == "max_oversell" {
+			existingData[i] = 20
+		}
+	}
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(completeProductRetrievalQuery)).
+		WithArgs(exampleProduct.SKU).
+		WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(existingData...))
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(testUtil.Mock, exampleProduct.ID, exampleProduct.Quantity, 0, false)
+	productUpdateQuery, _ := buildProductUpdateQuery(&Product{Status: exampleProduct.Status, StockPolicy: exampleProduct.StockPolicy, ClearMaxOversell: true})
+	testUtil.Mock.ExpectQuery(formatQueryForSQLMock(productUpdateQuery)).
+		WithArgs(
+			exampleProduct.Cost,
+			nil,
+			exampleProduct.Name,
+			exampleProduct.Price,
+			exampleProduct.Quantity,
+			exampleProduct.SKU,
+			exampleProduct.Status,
+			exampleProduct.StockPolicy,
+			exampleProduct.UPC.String,
+			exampleProduct.ID,
+		).WillReturnRows(sqlmock.NewRows(productHeaders).AddRow(exampleProductData...))
+	setExpectationsForProductRevisionCreation(testUtil.Mock, exampleProduct.ID, "update", 0, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleProduct.ID)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"clear_max_oversell": true}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Product{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Nil(t, actual.MaxOversell, "max_oversell should have been cleared")
+	assert.False(t, actual.ClearMaxOversell, "clear_max_oversell should only ever be sent, not rendered")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerSettingAndClearingMaxOversell(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"max_oversell": 5, "clear_max_oversell": true}`
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductUpdateHandlerLoweringQuantityPastReservations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// 123 in stock with 100 held leaves nothing to spare for lowering it to 50
+	setExpectationsForProductRetrieval(testUtil.Mock, exampleProduct.SKU, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductStockAndHolds(testUtil.Mock, exampleProduct.ID, exampleProduct.Quantity, 100, false)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), strings.NewReader(`{"quantity": 50}`))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestProductUpdateHandlerWithDBErrorRetrievingProduct(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -509,6 +1003,25 @@ func TestProductDeletionHandlerWithExistentProduct(t *testing.T) {
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
+func TestProductDeletionHandlerWithErrorArchivingOptions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductExistence(testUtil.Mock, exampleSKU, true, nil)
+	testUtil.Mock.ExpectBegin()
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(productOptionValuesDeletionQueryBySKU)).
+		WithArgs(exampleSKU).
+		WillReturnError(arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestProductDeletionHandlerWithNonexistentProduct(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -531,7 +1044,7 @@ func TestProductCreationHandler(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -556,14 +1069,16 @@ func TestProductCreationHandler(t *testing.T) {
 		}
 	`
 	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
 		DBRow: DBRow{
 			ID:        2,
 			CreatedOn: generateExampleTimeForTests(),
 		},
 		Name:          "Skateboard",
 		SKU:           "skateboard",
-		Price:         lolFloats,
-		Cost:          5,
+		Price:         1234,
+		Cost:          500,
 		Taxable:       true,
 		Description:   "This is a skateboard. Please wear a helmet.",
 		ProductWeight: 8,
@@ -574,17 +1089,20 @@ func TestProductCreationHandler(t *testing.T) {
 		PackageHeight: 3,
 		PackageWidth:  2,
 		PackageLength: 1,
-		UPC:           NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC:           NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		Quantity:      123,
 	}
 
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 	testUtil.Mock.ExpectBegin()
 	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, nil)
+	setExpectationsForInitialStock(testUtil.Mock, expectedProduct.ID, expectedProduct.Quantity)
 	setExpectationsForProductOptionCreation(testUtil.Mock, expectedCreatedProductOption, exampleProduct.ID, nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[0], nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[1], nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[2], nil)
+	setExpectationsForProductRevisionCreation(testUtil.Mock, expectedProduct.ID, "create", 0, nil)
 	testUtil.Mock.ExpectCommit()
 
 	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
@@ -615,7 +1133,7 @@ func TestProductCreationHandlerWhereCommitReturnsAnError(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -640,14 +1158,16 @@ func TestProductCreationHandlerWhereCommitReturnsAnError(t *testing.T) {
 		}
 	`
 	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
 		DBRow: DBRow{
 			ID:        2,
 			CreatedOn: generateExampleTimeForTests(),
 		},
 		Name:          "Skateboard",
 		SKU:           "skateboard",
-		Price:         lolFloats,
-		Cost:          5,
+		Price:         1234,
+		Cost:          500,
 		Taxable:       true,
 		Description:   "This is a skateboard. Please wear a helmet.",
 		ProductWeight: 8,
@@ -658,17 +1178,20 @@ func TestProductCreationHandlerWhereCommitReturnsAnError(t *testing.T) {
 		PackageHeight: 3,
 		PackageWidth:  2,
 		PackageLength: 1,
-		UPC:           NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC:           NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		Quantity:      123,
 	}
 
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 	testUtil.Mock.ExpectBegin()
 	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, nil)
+	setExpectationsForInitialStock(testUtil.Mock, expectedProduct.ID, expectedProduct.Quantity)
 	setExpectationsForProductOptionCreation(testUtil.Mock, expectedCreatedProductOption, exampleProduct.ID, nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[0], nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[1], nil)
 	setExpectationsForProductOptionValueCreation(testUtil.Mock, &expectedCreatedProductOption.Values[2], nil)
+	setExpectationsForProductRevisionCreation(testUtil.Mock, expectedProduct.ID, "create", 0, nil)
 	testUtil.Mock.ExpectCommit().WillReturnError(arbitraryError)
 
 	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
@@ -687,7 +1210,7 @@ func TestProductCreationHandlerWhereTransactionFailsToBegin(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -712,6 +1235,7 @@ func TestProductCreationHandlerWhereTransactionFailsToBegin(t *testing.T) {
 		}
 	`
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 	testUtil.Mock.ExpectBegin().WillReturnError(arbitraryError)
 
 	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
@@ -730,7 +1254,7 @@ func TestProductCreationHandlerWithoutOptions(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -747,14 +1271,16 @@ func TestProductCreationHandlerWithoutOptions(t *testing.T) {
 		}
 	`
 	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
 		DBRow: DBRow{
 			ID:        2,
 			CreatedOn: generateExampleTimeForTests(),
 		},
 		Name:          "Skateboard",
 		SKU:           "skateboard",
-		Price:         lolFloats,
-		Cost:          5,
+		Price:         1234,
+		Cost:          500,
 		Taxable:       true,
 		Description:   "This is a skateboard. Please wear a helmet.",
 		ProductWeight: 8,
@@ -765,14 +1291,17 @@ func TestProductCreationHandlerWithoutOptions(t *testing.T) {
 		PackageHeight: 3,
 		PackageWidth:  2,
 		PackageLength: 1,
-		UPC:           NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC:           NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		Quantity:      123,
 	}
 
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 
 	testUtil.Mock.ExpectBegin()
 	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, nil)
+	setExpectationsForInitialStock(testUtil.Mock, expectedProduct.ID, expectedProduct.Quantity)
+	setExpectationsForProductRevisionCreation(testUtil.Mock, expectedProduct.ID, "create", 0, nil)
 	testUtil.Mock.ExpectCommit()
 
 	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInput))
@@ -795,6 +1324,64 @@ func TestProductCreationHandlerWithInvalidProductInput(t *testing.T) {
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
 
+func TestProductCreationHandlerWithInvalidStatus(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"sku": "skateboard", "name": "Skateboard", "price": 12.34, "status": "hidden"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerWithInvalidStockPolicy(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"sku": "skateboard", "name": "Skateboard", "price": 12.34, "stock_policy": "whenever"}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerWithMissingRequiredAttribute(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, []ProductAttributeDefinition{exampleNumericProductAttributeDefinition}, nil)
+
+	body := `{"sku": "skateboard", "name": "Skateboard", "price": 12.34, "attributes": {"ply": null}}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestProductCreationHandlerWithErrorRetrievingAttributeDefinitions(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, arbitraryError)
+
+	body := `{"sku": "skateboard", "name": "Skateboard", "price": 12.34}`
+	req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
 func TestProductCreationHandlerForAlreadyExistentProduct(t *testing.T) {
 	t.Parallel()
 	testUtil := setupTestVariables(t)
@@ -803,7 +1390,7 @@ func TestProductCreationHandlerForAlreadyExistentProduct(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -837,7 +1424,7 @@ func TestProductCreationHandlerWithErrorCreatingOptions(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 99.99,
 			"cost": 50,
@@ -863,8 +1450,10 @@ func TestProductCreationHandlerWithErrorCreatingOptions(t *testing.T) {
 	`, exampleTimeAvailableString)
 
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 	testUtil.Mock.ExpectBegin()
 	setExpectationsForProductCreation(testUtil.Mock, exampleProduct, nil)
+	setExpectationsForInitialStock(testUtil.Mock, exampleProduct.ID, exampleProduct.Quantity)
 	setExpectationsForProductOptionCreation(testUtil.Mock, expectedCreatedProductOption, exampleProduct.ID, arbitraryError)
 	testUtil.Mock.ExpectRollback()
 
@@ -884,7 +1473,7 @@ func TestProductCreationHandlerWhereProductCreationFails(t *testing.T) {
 		{
 			"sku": "skateboard",
 			"name": "Skateboard",
-			"upc": "1234567890",
+			"upc": "036000291452",
 			"quantity": 123,
 			"price": 12.34,
 			"cost": 5,
@@ -901,14 +1490,16 @@ func TestProductCreationHandlerWhereProductCreationFails(t *testing.T) {
 		}
 	`
 	expectedProduct := &Product{
+		Status:      productStatusPublished,
+		StockPolicy: stockPolicyDeny,
 		DBRow: DBRow{
 			ID:        2,
 			CreatedOn: generateExampleTimeForTests(),
 		},
 		Name:          "Skateboard",
 		SKU:           "skateboard",
-		Price:         lolFloats,
-		Cost:          5,
+		Price:         1234,
+		Cost:          500,
 		Description:   "This is a skateboard. Please wear a helmet.",
 		Taxable:       true,
 		ProductWeight: 8,
@@ -919,11 +1510,12 @@ func TestProductCreationHandlerWhereProductCreationFails(t *testing.T) {
 		PackageHeight: 3,
 		PackageWidth:  2,
 		PackageLength: 1,
-		UPC:           NullString{sql.NullString{String: "1234567890", Valid: true}},
+		UPC:           NullString{sql.NullString{String: "00036000291452", Valid: true}},
 		Quantity:      123,
 	}
 
 	setExpectationsForProductExistence(testUtil.Mock, "skateboard", false, nil)
+	setExpectationsForProductAttributeDefinitionList(testUtil.Mock, nil, nil, nil)
 	testUtil.Mock.ExpectBegin()
 	setExpectationsForProductCreation(testUtil.Mock, expectedProduct, arbitraryError)
 	testUtil.Mock.ExpectRollback()
@@ -935,3 +1527,25 @@ func TestProductCreationHandlerWhereProductCreationFails(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
 	ensureExpectationsWereMet(t, testUtil.Mock)
 }
+
+func TestProductStatusIsValid(t *testing.T) {
+	t.Parallel()
+	for _, status := range []string{"draft", "scheduled", "published", "discontinued"} {
+		assert.True(t, productStatusIsValid(status), "`%s` should be a valid status", status)
+	}
+	for _, status := range []string{"", "hidden", "Published"} {
+		assert.False(t, productStatusIsValid(status), "`%s` shouldn't be a valid status", status)
+	}
+}
+
+func TestNewProductFromCreationInputDefaultsStatus(t *testing.T) {
+	t.Parallel()
+	p := newProductFromCreationInput(&ProductCreationInput{SKU: "skateboard"})
+	assert.Equal(t, productStatusPublished, p.Status, "products should be published by default")
+
+	p = newProductFromCreationInput(&ProductCreationInput{SKU: "skateboard", AvailableOn: time.Now().Add(24 * time.Hour)})
+	assert.Equal(t, productStatusScheduled, p.Status, "products that aren't available yet should be scheduled by default")
+
+	p = newProductFromCreationInput(&ProductCreationInput{SKU: "skateboard", Status: productStatusDraft})
+	assert.Equal(t, productStatusDraft, p.Status, "a given status should be kept")
+}
@@ -13,7 +13,13 @@ func applyQueryFilterToQueryBuilder(queryBuilder squirrel.SelectBuilder, queryFi
 		queryBuilder = queryBuilder.Limit(25)
 	}
 
-	if queryFilter.Page > 1 && includeOffset {
+	if queryFilter.UseCursor && includeOffset {
+		// seek past the last row the client saw instead of making the database skip over every preceding row
+		if queryFilter.Cursor != nil {
+			queryBuilder = queryBuilder.Where("(created_on, id) > (?, ?)", queryFilter.Cursor.CreatedOn, queryFilter.Cursor.ID)
+		}
+		queryBuilder = queryBuilder.OrderBy("created_on", "id")
+	} else if queryFilter.Page > 1 && includeOffset {
 		offset := (queryFilter.Page - 1) * uint64(queryFilter.Limit)
 		queryBuilder = queryBuilder.Offset(offset)
 	}
@@ -36,18 +42,41 @@ func applyQueryFilterToQueryBuilder(queryBuilder squirrel.SelectBuilder, queryFi
 	return queryBuilder
 }
 
-func buildCountQuery(table string, queryFilter *QueryFilter) string {
+// applyArchivedFilterToQueryBuilder leaves archived rows out of a query unless the filter asks for them
+func applyArchivedFilterToQueryBuilder(queryBuilder squirrel.SelectBuilder, queryFilter *QueryFilter) squirrel.SelectBuilder {
+	switch queryFilter.Archived {
+	case archivedRowsIncluded:
+		return queryBuilder
+	case archivedRowsOnly:
+		return queryBuilder.Where(squirrel.NotEq{"archived_on": nil})
+	}
+	return queryBuilder.Where(squirrel.Eq{"archived_on": nil})
+}
+
+// applyProductFilterToQueryBuilder applies the parts of a query filter that only product lists use
+func applyProductFilterToQueryBuilder(queryBuilder squirrel.SelectBuilder, queryFilter *QueryFilter) squirrel.SelectBuilder {
+	if queryFilter.PublishedOnly {
+		queryBuilder = queryBuilder.Where(publishedProductCondition)
+	}
+	if len(queryFilter.Attributes) > 0 {
+		queryBuilder = queryBuilder.Where("attributes @> ?", queryFilter.Attributes)
+	}
+	return queryBuilder
+}
+
+func buildCountQuery(table string, queryFilter *QueryFilter) (string, []interface{}) {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	queryBuilder := sqlBuilder.
 		Select("count(id)").
-		From(table).
-		Where(squirrel.Eq{"archived_on": nil})
+		From(table)
+	queryBuilder = applyArchivedFilterToQueryBuilder(queryBuilder, queryFilter)
+	queryBuilder = applyProductFilterToQueryBuilder(queryBuilder, queryFilter)
 
 	// setting this to false so we always get a count
 	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, false)
 
-	query, _, _ := queryBuilder.ToSql()
-	return query
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
 }
 
 ////////////////////////////////////////////////////////
@@ -61,26 +90,78 @@ func buildProductListQuery(queryFilter *QueryFilter) (string, []interface{}) {
 	queryBuilder := sqlBuilder.
 		Select(productTableHeaders).
 		From("products").
-		Where(squirrel.Eq{"archived_on": nil}).
 		Limit(uint64(queryFilter.Limit))
 
+	queryBuilder = applyArchivedFilterToQueryBuilder(queryBuilder, queryFilter)
+	queryBuilder = applyProductFilterToQueryBuilder(queryBuilder, queryFilter)
 	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, true)
 
 	query, args, _ := queryBuilder.ToSql()
 	return query, args
 }
 
+func buildProductListQueryByIDs(productIDs []uint64, publishedOnly bool) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productTableHeaders).
+		From("products").
+		Where(squirrel.Eq{"id": productIDs}).
+		Where(squirrel.Eq{"archived_on": nil})
+	if publishedOnly {
+		queryBuilder = queryBuilder.Where(publishedProductCondition)
+	}
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductPurgeQuery(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Delete("products").
+		Where(squirrel.Eq{"id": productIDs}).
+		Where(squirrel.NotEq{"archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
 func buildProductUpdateQuery(p *Product) (string, []interface{}) {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	productUpdateSetMap := map[string]interface{}{
 		"sku":        p.SKU,
 		"name":       p.Name,
 		"upc":        p.UPC,
-		"quantity":   p.Quantity,
+		"quantity":   squirrel.Expr(productQuantityUpdateExpression, p.Quantity),
 		"price":      p.Price,
 		"cost":       p.Cost,
 		"updated_on": squirrel.Expr("NOW()"),
 	}
+	// imports don't always say anything about when or whether a product is released, in which case it stays as it was
+	if !p.AvailableOn.IsZero() {
+		productUpdateSetMap["available_on"] = p.AvailableOn
+	}
+	if p.Status != "" {
+		productUpdateSetMap["status"] = p.Status
+	}
+	if p.DiscontinuedOn.Valid {
+		productUpdateSetMap["discontinued_on"] = p.DiscontinuedOn
+	}
+	if p.Attributes != nil {
+		productUpdateSetMap["attributes"] = p.Attributes
+	}
+	if p.ReorderThreshold != nil {
+		productUpdateSetMap["reorder_threshold"] = p.ReorderThreshold
+	}
+	if p.StockPolicy != "" {
+		productUpdateSetMap["stock_policy"] = p.StockPolicy
+	}
+	if p.ClearMaxOversell {
+		productUpdateSetMap["max_oversell"] = nil
+	} else if p.MaxOversell != nil {
+		productUpdateSetMap["max_oversell"] = p.MaxOversell
+	}
+	if p.RestockOn.Valid {
+		productUpdateSetMap["restock_on"] = p.RestockOn
+	}
 	queryBuilder := sqlBuilder.
 		Update("products").
 		SetMap(productUpdateSetMap).
@@ -90,6 +171,61 @@ func buildProductUpdateQuery(p *Product) (string, []interface{}) {
 	return query, args
 }
 
+// buildProductImportUpdateQuery updates an existing product with the fields an import row provided, named as they are
+// in a ProductCreationInput. Fields the row left out, or that aren't stored on the product (like its options), are left be.
+func buildProductImportUpdateQuery(p *Product, fields map[string]bool) (string, []interface{}) {
+	columnValues := map[string]interface{}{
+		"name":                 p.Name,
+		"subtitle":             p.Subtitle.String,
+		"description":          p.Description,
+		"sku":                  p.SKU,
+		"upc":                  p.UPC.String,
+		"manufacturer":         p.Manufacturer.String,
+		"brand":                p.Brand.String,
+		"quantity":             squirrel.Expr(productQuantityUpdateExpression, p.Quantity),
+		"taxable":              p.Taxable,
+		"price":                p.Price,
+		"on_sale":              p.OnSale,
+		"sale_price":           p.SalePrice,
+		"cost":                 p.Cost,
+		"product_weight":       p.ProductWeight,
+		"product_height":       p.ProductHeight,
+		"product_width":        p.ProductWidth,
+		"product_length":       p.ProductLength,
+		"package_weight":       p.PackageWeight,
+		"package_height":       p.PackageHeight,
+		"package_width":        p.PackageWidth,
+		"package_length":       p.PackageLength,
+		"quantity_per_package": p.QuantityPerPackage,
+		"available_on":         p.AvailableOn,
+		"status":               p.Status,
+		"discontinued_on":      p.DiscontinuedOn,
+		"attributes":           p.Attributes,
+		"digital":              p.Digital,
+		"reorder_threshold":    p.ReorderThreshold,
+		"stock_policy":         p.StockPolicy,
+		"max_oversell":         p.MaxOversell,
+		"restock_on":           p.RestockOn,
+	}
+
+	productUpdateSetMap := map[string]interface{}{
+		"updated_on": squirrel.Expr("NOW()"),
+	}
+	for field := range fields {
+		if value, ok := columnValues[field]; ok {
+			productUpdateSetMap[field] = value
+		}
+	}
+
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Update("products").
+		SetMap(productUpdateSetMap).
+		Where(squirrel.Eq{"id": p.ID})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
 func buildProductCreationQuery(p *Product) (string, []interface{}) {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	queryBuilder := sqlBuilder.
@@ -118,6 +254,14 @@ func buildProductCreationQuery(p *Product) (string, []interface{}) {
 			"package_length",
 			"quantity_per_package",
 			"available_on",
+			"status",
+			"discontinued_on",
+			"attributes",
+			"digital",
+			"reorder_threshold",
+			"stock_policy",
+			"max_oversell",
+			"restock_on",
 			"updated_on",
 		).
 		Values(
@@ -144,6 +288,14 @@ func buildProductCreationQuery(p *Product) (string, []interface{}) {
 			p.PackageLength,
 			p.QuantityPerPackage,
 			p.AvailableOn,
+			p.Status,
+			p.DiscontinuedOn,
+			p.Attributes,
+			p.Digital,
+			p.ReorderThreshold,
+			p.StockPolicy,
+			p.MaxOversell,
+			p.RestockOn,
 			squirrel.Expr("NOW()"),
 		).
 		Suffix(`RETURNING "id"`)
@@ -160,11 +312,41 @@ func buildProductCreationQuery(p *Product) (string, []interface{}) {
 func buildProductOptionListQuery(productID uint64, queryFilter *QueryFilter) (string, []interface{}) {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	queryBuilder := sqlBuilder.
-		Select(fmt.Sprintf("count(id) over (), %s", productOptionsHeaders)).
+		Select(productOptionsHeaders).
 		From("product_options").
-		Where(squirrel.Eq{"product_id": productID}).
-		Where(squirrel.Eq{"archived_on": nil})
+		Where(squirrel.Eq{"product_id": productID})
+	queryBuilder = applyArchivedFilterToQueryBuilder(queryBuilder, queryFilter)
 	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, true)
+	// cursors only work when rows are in the order they were created in
+	if !queryFilter.UseCursor {
+		queryBuilder = queryBuilder.OrderBy("position", "id")
+	}
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+// buildProductOptionCountQuery counts every option a list of them could page through, so like buildCountQuery
+// it leaves off the offset and cursor
+func buildProductOptionCountQuery(productID uint64, queryFilter *QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("count(id)").
+		From("product_options").
+		Where(squirrel.Eq{"product_id": productID})
+	queryBuilder = applyArchivedFilterToQueryBuilder(queryBuilder, queryFilter)
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, false)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionListQueryForProducts(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionsHeaders).
+		From("product_options").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("position", "id")
 	query, args, _ := queryBuilder.ToSql()
 	return query, args
 }
@@ -188,8 +370,8 @@ func buildProductOptionCreationQuery(a *ProductOption, productID uint64) (string
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	queryBuilder := sqlBuilder.
 		Insert("product_options").
-		Columns("name", "product_id").
-		Values(a.Name, productID).
+		Columns("name", "product_id", "position").
+		Values(a.Name, productID, squirrel.Expr("(SELECT COALESCE(MAX(position) + 1, 0) FROM product_options WHERE product_id = ? AND archived_on IS NULL)", productID)).
 		Suffix(`RETURNING "id"`)
 	query, args, _ := queryBuilder.ToSql()
 	return query, args
@@ -201,11 +383,41 @@ func buildProductOptionCreationQuery(a *ProductOption, productID uint64) (string
 //                                                    //
 ////////////////////////////////////////////////////////
 
+func buildProductOptionValueListQueryForOptions(optionIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionValuesHeaders).
+		From("product_option_values").
+		Where(squirrel.Eq{"product_option_id": optionIDs}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("position", "id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+// buildProductOptionValueListQueryForProduct only returns values that belong to one of the product's options,
+// so that a client can't price a product with some other product's option values
+func buildProductOptionValueListQueryForProduct(productID uint64, valueIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionValuesHeaders).
+		From("product_option_values").
+		Where(squirrel.Eq{"id": valueIDs}).
+		Where(squirrel.Expr("product_option_id IN (SELECT id FROM product_options WHERE product_id = ? AND archived_on IS NULL)", productID)).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("product_option_id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
 func buildProductOptionValueUpdateQuery(v *ProductOptionValue) (string, []interface{}) {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	productOptionUpdateSetMap := map[string]interface{}{
-		"value":      v.Value,
-		"updated_on": squirrel.Expr("NOW()"),
+		"value":        v.Value,
+		"price_delta":  v.PriceDelta,
+		"weight_delta": v.WeightDelta,
+		"sku_suffix":   v.SKUSuffix,
+		"updated_on":   squirrel.Expr("NOW()"),
 	}
 	queryBuilder := sqlBuilder.
 		Update("product_option_values").
@@ -220,13 +432,653 @@ func buildProductOptionValueCreationQuery(v *ProductOptionValue) (string, []inte
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	queryBuilder := sqlBuilder.
 		Insert("product_option_values").
-		Columns("product_option_id", "value").
-		Values(v.ProductOptionID, v.Value).
+		Columns("product_option_id", "value", "price_delta", "weight_delta", "sku_suffix", "position").
+		Values(v.ProductOptionID, v.Value, v.PriceDelta, v.WeightDelta, v.SKUSuffix, squirrel.Expr("(SELECT COALESCE(MAX(position) + 1, 0) FROM product_option_values WHERE product_option_id = ? AND archived_on IS NULL)", v.ProductOptionID)).
 		Suffix(`RETURNING "id"`)
 	query, args, _ := queryBuilder.ToSql()
 	return query, args
 }
 
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Product Reviews                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductReviewListQuery(productID uint64, status string, queryFilter *QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productReviewsTableHeaders).
+		From("product_reviews").
+		Where(squirrel.Eq{"status": status}).
+		Where(squirrel.Eq{"archived_on": nil})
+	// the moderation queue spans every product, so a zero productID means we don't filter by product
+	if productID != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"product_id": productID})
+	}
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, true)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+// buildProductReviewCountQuery counts every review a list of them could page through, so like
+// buildProductOptionCountQuery it leaves off the offset and cursor
+func buildProductReviewCountQuery(productID uint64, status string, queryFilter *QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("count(id)").
+		From("product_reviews").
+		Where(squirrel.Eq{"status": status}).
+		Where(squirrel.Eq{"archived_on": nil})
+	if productID != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"product_id": productID})
+	}
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, false)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductReviewCreationQuery(r *ProductReview) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_reviews").
+		Columns("product_id", "user_id", "rating", "title", "body", "status").
+		Values(r.ProductID, r.UserID, r.Rating, r.Title, r.Body, r.Status).
+		Suffix(fmt.Sprintf("RETURNING %s", productReviewsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductReviewUpdateQuery(r *ProductReview) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	updateSetMap := map[string]interface{}{
+		"status":            r.Status,
+		"verified_purchase": r.VerifiedPurchase,
+		"updated_on":        squirrel.Expr("NOW()"),
+	}
+	queryBuilder := sqlBuilder.
+		Update("product_reviews").
+		SetMap(updateSetMap).
+		Where(squirrel.Eq{"id": r.ID}).
+		Suffix(fmt.Sprintf("RETURNING %s", productReviewsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductReviewSummaryQuery(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("product_id", "ROUND(AVG(rating), 2)", "count(id)").
+		From("product_reviews").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"status": reviewStatusApproved}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		GroupBy("product_id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                    Currencies                      //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductPriceListQuery(productIDs []uint64, currency string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productPricesTableHeaders).
+		From("product_prices").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"archived_on": nil})
+	// an empty currency means we want every price the products have
+	if currency != "" {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"currency": currency})
+	}
+	queryBuilder = queryBuilder.OrderBy("currency")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductPriceUpsertQuery(pp *ProductPrice) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_prices").
+		Columns("product_id", "currency", "price", "sale_price").
+		Values(pp.ProductID, pp.Currency, pp.Price, pp.SalePrice).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_id", "currency") DO UPDATE SET price = EXCLUDED.price, sale_price = EXCLUDED.sale_price, updated_on = NOW(), archived_on = NULL RETURNING %s`, productPricesTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildExchangeRateListQuery() (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(exchangeRatesTableHeaders).
+		From("exchange_rates").
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("currency")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildExchangeRateUpsertQuery(r *ExchangeRate) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("exchange_rates").
+		Columns("currency", "rate").
+		Values(r.Currency, r.Rate).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("currency") DO UPDATE SET rate = EXCLUDED.rate, updated_on = NOW(), archived_on = NULL RETURNING %s`, exchangeRatesTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                Product Price Tiers                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductPriceTierListQuery(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productPriceTiersTableHeaders).
+		From("product_price_tiers").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("product_id", "min_quantity")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductPriceTierUpsertQuery(t *ProductPriceTier) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_price_tiers").
+		Columns("product_id", "min_quantity", "unit_price").
+		Values(t.ProductID, t.MinQuantity, t.UnitPrice).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_id", "min_quantity") DO UPDATE SET unit_price = EXCLUDED.unit_price, updated_on = NOW(), archived_on = NULL RETURNING %s`, productPriceTiersTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Product Bundles                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildBundleComponentProductQuery(skus []string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("p.id", "p.sku", "p.quantity", "p.price", "p.on_sale", "p.sale_price", "b.id IS NOT NULL").
+		From("products p").
+		LeftJoin("product_bundles b ON b.product_id = p.id AND b.archived_on IS NULL").
+		Where(squirrel.Eq{"p.sku": skus}).
+		Where(squirrel.Eq{"p.archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductBundleCreationQuery(bundleProductID uint64, b *ProductBundle) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_bundles").
+		Columns("product_id", "discount_type", "discount_amount").
+		Values(bundleProductID, b.DiscountType, b.DiscountAmount)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductBundleComponentCreationQuery(bundleProductID uint64, components []ProductBundleComponent) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_bundle_components").
+		Columns("bundle_product_id", "component_product_id", "quantity")
+	for _, c := range components {
+		queryBuilder = queryBuilder.Values(bundleProductID, c.ProductID, c.Quantity)
+	}
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductBundleListQuery(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("product_id", "discount_type", "discount_amount").
+		From("product_bundles").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductBundleComponentListQuery(bundleProductIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(
+			"c.bundle_product_id",
+			"c.component_product_id",
+			"p.sku",
+			"c.quantity",
+			// an archived component can't be sold, so it can't be bundled either
+			"CASE WHEN p.archived_on IS NULL THEN p.quantity ELSE 0 END",
+			"p.price",
+			"p.on_sale",
+			"p.sale_price",
+		).
+		From("product_bundle_components c").
+		Join("products p ON p.id = c.component_product_id").
+		Where(squirrel.Eq{"c.bundle_product_id": bundleProductIDs}).
+		Where(squirrel.Eq{"c.archived_on": nil}).
+		OrderBy("c.bundle_product_id", "c.id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 Product Relations                  //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductRelationListQuery(productID uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(
+			"r.id",
+			"r.product_id",
+			"r.related_product_id",
+			"p.sku",
+			"r.kind",
+			"r.position",
+			"r.created_on",
+			"r.updated_on",
+			"r.archived_on",
+		).
+		From("product_relations r").
+		Join("products p ON p.id = r.related_product_id").
+		Where(squirrel.Eq{"r.product_id": productID}).
+		Where(squirrel.Eq{"r.archived_on": nil}).
+		Where(squirrel.Eq{"p.archived_on": nil}).
+		OrderBy("r.kind", "r.position", "r.id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductRelationUpsertQuery(r *ProductRelation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_relations").
+		Columns("product_id", "related_product_id", "kind", "position").
+		Values(r.ProductID, r.RelatedProductID, r.Kind, r.Position).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_id", "related_product_id", "kind") DO UPDATE SET position = EXCLUDED.position, updated_on = NOW(), archived_on = NULL RETURNING %s`, productRelationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 Digital Products                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductFileKeyListQuery(productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select("blob_key").
+		From("product_files").
+		Where(squirrel.Eq{"product_id": productIDs})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductFileUpsertQuery(f *ProductFile) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_files").
+		Columns("product_id", "blob_key", "filename", "content_type", "size").
+		Values(f.ProductID, f.BlobKey, f.Filename, f.ContentType, f.Size).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_id") DO UPDATE SET blob_key = EXCLUDED.blob_key, filename = EXCLUDED.filename, content_type = EXCLUDED.content_type, size = EXCLUDED.size, updated_on = NOW(), archived_on = NULL RETURNING %s`, productFilesTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildDownloadGrantCreationQuery(g *DownloadGrant) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("download_grants").
+		Columns("token", "product_id", "user_id", "expires_on", "max_downloads").
+		Values(g.Token, g.ProductID, g.UserID, g.ExpiresOn, g.MaxDownloads).
+		Suffix(fmt.Sprintf(`RETURNING %s`, downloadGrantsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                    Gift Cards                      //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildGiftCardCreationQuery(g *GiftCard) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("gift_cards").
+		Columns("code", "product_id", "initial_balance", "remaining_balance", "currency", "expires_on").
+		Values(g.Code, g.ProductID, g.InitialBalance, g.RemainingBalance, g.Currency, g.ExpiresOn).
+		Suffix(fmt.Sprintf(`RETURNING %s`, giftCardsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildGiftCardTransactionCreationQuery(t *GiftCardTransaction) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("gift_card_transactions").
+		Columns("gift_card_id", "kind", "amount", "balance_after", "user_id", "reference").
+		Values(t.GiftCardID, t.Kind, t.Amount, t.BalanceAfter, t.UserID, t.Reference).
+		Suffix(fmt.Sprintf(`RETURNING %s`, giftCardTransactionsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildGiftCardTransactionListQuery(giftCardID uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(giftCardTransactionsTableHeaders).
+		From("gift_card_transactions").
+		Where(squirrel.Eq{"gift_card_id": giftCardID}).
+		OrderBy("id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                Product Attributes                  //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductAttributeDefinitionListQuery(queryFilter *QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productAttributeDefinitionsTableHeaders).
+		From("product_attribute_definitions").
+		Where(squirrel.Eq{"archived_on": nil})
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, true)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductAttributeDefinitionsQuery(names []string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productAttributeDefinitionsTableHeaders).
+		From("product_attribute_definitions").
+		Where(squirrel.Eq{"archived_on": nil})
+	// a nil names means we want every definition
+	if names != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"name": names})
+	}
+	queryBuilder = queryBuilder.OrderBy("name")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductAttributeDefinitionCreationQuery(d *ProductAttributeDefinition) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_attribute_definitions").
+		Columns("name", "type", "required", "choices").
+		Values(d.Name, d.Type, d.Required, d.Choices).
+		Suffix(fmt.Sprintf("RETURNING %s", productAttributeDefinitionsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductAttributeDefinitionUpdateQuery(d *ProductAttributeDefinition) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	updateSetMap := map[string]interface{}{
+		"required":   d.Required,
+		"choices":    d.Choices,
+		"updated_on": squirrel.Expr("NOW()"),
+	}
+	queryBuilder := sqlBuilder.
+		Update("product_attribute_definitions").
+		SetMap(updateSetMap).
+		Where(squirrel.Eq{"id": d.ID}).
+		Suffix(fmt.Sprintf("RETURNING %s", productAttributeDefinitionsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                Product Translations                //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildProductTranslationListQuery(productID uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productTranslationsTableHeaders).
+		From("product_translations").
+		Where(squirrel.Eq{"product_id": productID}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("locale")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductTranslationListQueryForProducts(productIDs []uint64, locales []string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productTranslationsTableHeaders).
+		From("product_translations").
+		Where(squirrel.Eq{"product_id": productIDs}).
+		Where(squirrel.Eq{"locale": locales}).
+		Where(squirrel.Eq{"archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductTranslationUpsertQuery(t *ProductTranslation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_translations").
+		Columns("product_id", "locale", "name", "subtitle", "description").
+		Values(t.ProductID, t.Locale, t.Name, t.Subtitle, t.Description).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_id", "locale") DO UPDATE SET name = EXCLUDED.name, subtitle = EXCLUDED.subtitle, description = EXCLUDED.description, updated_on = NOW(), archived_on = NULL RETURNING %s`, productTranslationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionTranslationListQuery(optionID uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionTranslationsTableHeaders).
+		From("product_option_translations").
+		Where(squirrel.Eq{"product_option_id": optionID}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("locale")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionTranslationListQueryForOptions(optionIDs []uint64, locales []string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionTranslationsTableHeaders).
+		From("product_option_translations").
+		Where(squirrel.Eq{"product_option_id": optionIDs}).
+		Where(squirrel.Eq{"locale": locales}).
+		Where(squirrel.Eq{"archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionTranslationUpsertQuery(t *ProductOptionTranslation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_option_translations").
+		Columns("product_option_id", "locale", "name").
+		Values(t.ProductOptionID, t.Locale, t.Name).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_option_id", "locale") DO UPDATE SET name = EXCLUDED.name, updated_on = NOW(), archived_on = NULL RETURNING %s`, productOptionTranslationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionValueTranslationListQuery(valueID uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionValueTranslationsTableHeaders).
+		From("product_option_value_translations").
+		Where(squirrel.Eq{"product_option_value_id": valueID}).
+		Where(squirrel.Eq{"archived_on": nil}).
+		OrderBy("locale")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionValueTranslationListQueryForValues(valueIDs []uint64, locales []string) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(productOptionValueTranslationsTableHeaders).
+		From("product_option_value_translations").
+		Where(squirrel.Eq{"product_option_value_id": valueIDs}).
+		Where(squirrel.Eq{"locale": locales}).
+		Where(squirrel.Eq{"archived_on": nil})
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildProductOptionValueTranslationUpsertQuery(t *ProductOptionValueTranslation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("product_option_value_translations").
+		Columns("product_option_value_id", "locale", "value").
+		Values(t.ProductOptionValueID, t.Locale, t.Value).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("product_option_value_id", "locale") DO UPDATE SET value = EXCLUDED.value, updated_on = NOW(), archived_on = NULL RETURNING %s`, productOptionValueTranslationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                     Inventory                      //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildInventoryLocationCreationQuery(l *InventoryLocation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("inventory_locations").
+		Columns("name", "sellable").
+		Values(l.Name, l.Sellable).
+		Suffix(fmt.Sprintf("RETURNING %s", inventoryLocationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildInventoryLocationUpdateQuery(l *InventoryLocation) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	updateSetMap := map[string]interface{}{
+		"name":       l.Name,
+		"sellable":   l.Sellable,
+		"updated_on": squirrel.Expr("NOW()"),
+	}
+	queryBuilder := sqlBuilder.
+		Update("inventory_locations").
+		SetMap(updateSetMap).
+		Where(squirrel.Eq{"id": l.ID}).
+		Suffix(fmt.Sprintf("RETURNING %s", inventoryLocationsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildInventoryMovementCreationQuery(m *InventoryMovement) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("inventory_movements").
+		Columns("product_id", "location_id", "option_value_ids", "delta", "quantity_after", "reason", "reference", "user_id").
+		Values(m.ProductID, m.LocationID, m.OptionValueIDs, m.Delta, m.QuantityAfter, m.Reason, m.Reference, m.UserID).
+		Suffix(fmt.Sprintf("RETURNING %s", inventoryMovementsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                  Customer Groups                   //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildCustomerGroupListQuery(queryFilter *QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(customerGroupsTableHeaders).
+		From("customer_groups").
+		Where(squirrel.Eq{"archived_on": nil})
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, queryFilter, true)
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildCustomerGroupCreationQuery(g *CustomerGroup) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("customer_groups").
+		Columns("name", "description").
+		Values(g.Name, g.Description).
+		Suffix(fmt.Sprintf("RETURNING %s", customerGroupsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildCustomerGroupUpdateQuery(g *CustomerGroup) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	updateSetMap := map[string]interface{}{
+		"name":        g.Name,
+		"description": g.Description,
+		"updated_on":  squirrel.Expr("NOW()"),
+	}
+	queryBuilder := sqlBuilder.
+		Update("customer_groups").
+		SetMap(updateSetMap).
+		Where(squirrel.Eq{"id": g.ID}).
+		Suffix(fmt.Sprintf("RETURNING %s", customerGroupsTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildCustomerGroupPriceListQuery(groupID uint64, productIDs []uint64) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(customerGroupPricesTableHeaders).
+		From("customer_group_prices").
+		Where(squirrel.Eq{"customer_group_id": groupID}).
+		Where(squirrel.Eq{"archived_on": nil})
+	// a nil productIDs means we want the group's entire price list
+	if productIDs != nil {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{"product_id": productIDs})
+	}
+	queryBuilder = queryBuilder.OrderBy("product_id")
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
+func buildCustomerGroupPriceUpsertQuery(gp *CustomerGroupPrice) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Insert("customer_group_prices").
+		Columns("customer_group_id", "product_id", "price").
+		Values(gp.CustomerGroupID, gp.ProductID, gp.Price).
+		Suffix(fmt.Sprintf(`ON CONFLICT ("customer_group_id", "product_id") DO UPDATE SET price = EXCLUDED.price, updated_on = NOW(), archived_on = NULL RETURNING %s`, customerGroupPricesTableHeaders))
+	query, args, _ := queryBuilder.ToSql()
+	return query, args
+}
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //                     Discounts                      //
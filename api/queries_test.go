@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,6 +46,14 @@ func TestBuildProductListQuery(t *testing.T) {
 		package_length,
 		quantity_per_package,
 		available_on,
+		status,
+		discontinued_on,
+		attributes,
+		digital,
+		reorder_threshold,
+		stock_policy,
+		max_oversell,
+		restock_on,
 		created_on,
 		updated_on,
 		archived_on
@@ -87,6 +96,14 @@ func TestBuildProductListQueryAndPartiallyCustomQueryFilter(t *testing.T) {
 		package_length,
 		quantity_per_package,
 		available_on,
+		status,
+		discontinued_on,
+		attributes,
+		digital,
+		reorder_threshold,
+		stock_policy,
+		max_oversell,
+		restock_on,
 		created_on,
 		updated_on,
 		archived_on
@@ -132,6 +149,14 @@ func TestBuildProductListQueryAndCompletelyCustomQueryFilter(t *testing.T) {
 		package_length,
 		quantity_per_package,
 		available_on,
+		status,
+		discontinued_on,
+		attributes,
+		digital,
+		reorder_threshold,
+		stock_policy,
+		max_oversell,
+		restock_on,
 		created_on,
 		updated_on,
 		archived_on
@@ -144,36 +169,205 @@ func TestBuildProductListQueryAndCompletelyCustomQueryFilter(t *testing.T) {
 
 func TestBuildProductUpdateQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `UPDATE products SET cost = $1, name = $2, price = $3, quantity = $4, sku = $5, upc = $6, updated_on = NOW() WHERE id = $7 RETURNING *`
+	expectedQuery := `UPDATE products SET attributes = $1, available_on = $2, cost = $3, name = $4, price = $5, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $6 END, sku = $7, status = $8, stock_policy = $9, upc = $10, updated_on = NOW() WHERE id = $11 RETURNING *`
 	actualQuery, actualArgs := buildProductUpdateQuery(exampleProduct)
 
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 11, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductUpdateQueryWithoutStatusOrDates(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `UPDATE products SET cost = $1, name = $2, price = $3, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $4 END, sku = $5, upc = $6, updated_on = NOW() WHERE id = $7 RETURNING *`
+	actualQuery, actualArgs := buildProductUpdateQuery(&Product{DBRow: DBRow{ID: exampleProduct.ID}, SKU: exampleProduct.SKU})
+
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
 	assert.Equal(t, 7, len(actualArgs), argsEqualityErrorMessage)
 }
 
+func TestBuildProductUpdateQueryWithReorderThreshold(t *testing.T) {
+	t.Parallel()
+	threshold := uint32(10)
+	p := &Product{DBRow: DBRow{ID: exampleProduct.ID}, ReorderThreshold: &threshold}
+	expectedQuery := `UPDATE products SET cost = $1, name = $2, price = $3, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $4 END, reorder_threshold = $5, sku = $6, upc = $7, updated_on = NOW() WHERE id = $8 RETURNING *`
+	actualQuery, actualArgs := buildProductUpdateQuery(p)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 8, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductUpdateQueryWithStockPolicy(t *testing.T) {
+	t.Parallel()
+	maxOversell := uint32(20)
+	p := &Product{DBRow: DBRow{ID: exampleProduct.ID}, StockPolicy: stockPolicyBackorder, MaxOversell: &maxOversell}
+	p.RestockOn = NullTime{pq.NullTime{Time: generateExampleTimeForTests(), Valid: true}}
+	expectedQuery := `UPDATE products SET cost = $1, max_oversell = $2, name = $3, price = $4, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $5 END, restock_on = $6, sku = $7, stock_policy = $8, upc = $9, updated_on = NOW() WHERE id = $10 RETURNING *`
+	actualQuery, actualArgs := buildProductUpdateQuery(p)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 10, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductUpdateQueryClearingMaxOversell(t *testing.T) {
+	t.Parallel()
+	maxOversell := uint32(20)
+	p := &Product{DBRow: DBRow{ID: exampleProduct.ID}, MaxOversell: &maxOversell, ClearMaxOversell: true}
+	expectedQuery := `UPDATE products SET cost = $1, max_oversell = $2, name = $3, price = $4, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $5 END, sku = $6, upc = $7, updated_on = NOW() WHERE id = $8 RETURNING *`
+	actualQuery, actualArgs := buildProductUpdateQuery(p)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 8, len(actualArgs), argsEqualityErrorMessage)
+	assert.Nil(t, actualArgs[1], "max_oversell should be set back to null")
+}
+
+func TestBuildProductUpdateQueryWithDiscontinuedOn(t *testing.T) {
+	t.Parallel()
+	p := &Product{DBRow: DBRow{ID: exampleProduct.ID}, Status: productStatusDiscontinued}
+	p.DiscontinuedOn = NullTime{pq.NullTime{Time: generateExampleTimeForTests(), Valid: true}}
+	expectedQuery := `UPDATE products SET cost = $1, discontinued_on = $2, name = $3, price = $4, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $5 END, sku = $6, status = $7, upc = $8, updated_on = NOW() WHERE id = $9 RETURNING *`
+	actualQuery, actualArgs := buildProductUpdateQuery(p)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 9, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductImportUpdateQuery(t *testing.T) {
+	t.Parallel()
+	fields := map[string]bool{"sku": true, "description": true, "quantity": true, "options": true}
+	expectedQuery := `UPDATE products SET description = $1, quantity = CASE WHEN EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) THEN products.quantity ELSE $2 END, sku = $3, updated_on = NOW() WHERE id = $4`
+	actualQuery, actualArgs := buildProductImportUpdateQuery(exampleProduct, fields)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 4, len(actualArgs), argsEqualityErrorMessage)
+}
+
 func TestBuildProductCreationQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `INSERT INTO products (name,subtitle,description,sku,upc,manufacturer,brand,quantity,taxable,price,on_sale,sale_price,cost,product_weight,product_height,product_width,product_length,package_weight,package_height,package_width,package_length,quantity_per_package,available_on,updated_on) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,NOW()) RETURNING "id"`
+	expectedQuery := `INSERT INTO products (name,subtitle,description,sku,upc,manufacturer,brand,quantity,taxable,price,on_sale,sale_price,cost,product_weight,product_height,product_width,product_length,package_weight,package_height,package_width,package_length,quantity_per_package,available_on,status,discontinued_on,attributes,digital,reorder_threshold,stock_policy,max_oversell,restock_on,updated_on) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,NOW()) RETURNING "id"`
 	actualQuery, actualArgs := buildProductCreationQuery(exampleProduct)
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
-	assert.Equal(t, 23, len(actualArgs), argsEqualityErrorMessage)
+	assert.Equal(t, 31, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductListQueryForArchivedProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTableHeaders + ` FROM products WHERE archived_on IS NOT NULL LIMIT 25`
+	actualQuery, actualArgs := buildProductListQuery(&QueryFilter{Limit: 25, Archived: archivedRowsOnly})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+
+	expectedQuery = `SELECT ` + productTableHeaders + ` FROM products LIMIT 25`
+	actualQuery, actualArgs = buildProductListQuery(&QueryFilter{Limit: 25, Archived: archivedRowsIncluded})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildCountQueryForArchivedRows(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT count(id) FROM products WHERE archived_on IS NOT NULL LIMIT 25`
+	actualQuery, actualArgs := buildCountQuery("products", &QueryFilter{Archived: archivedRowsOnly})
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductListQueryForPublishedProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTableHeaders + ` FROM products WHERE archived_on IS NULL AND ` + publishedProductCondition + ` LIMIT 25`
+	actualQuery, actualArgs := buildProductListQuery(&QueryFilter{Limit: 25, PublishedOnly: true})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildCountQueryForPublishedProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT count(id) FROM products WHERE archived_on IS NULL AND ` + publishedProductCondition + ` LIMIT 25`
+	actualQuery, actualArgs := buildCountQuery("products", &QueryFilter{PublishedOnly: true})
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductPurgeQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `DELETE FROM products WHERE id IN ($1,$2) AND archived_on IS NOT NULL`
+	actualQuery, actualArgs := buildProductPurgeQuery([]uint64{existingID, 2})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
 }
 
 func TestBuildProductOptionListQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `SELECT count(id) over (), id,
+	expectedQuery := `SELECT id,
 		name,
 		product_id,
 		created_on,
 		updated_on,
-		archived_on
-	 FROM product_options WHERE product_id = $1 AND archived_on IS NULL LIMIT 25`
+		archived_on,
+		position
+	 FROM product_options WHERE product_id = $1 AND archived_on IS NULL ORDER BY position, id LIMIT 25`
 	actualQuery, actualArgs := buildProductOptionListQuery(existingID, &QueryFilter{})
 
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
 	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
 }
 
+func TestBuildProductOptionListQueryWithCursor(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		name,
+		product_id,
+		created_on,
+		updated_on,
+		archived_on,
+		position
+	 FROM product_options WHERE product_id = $1 AND archived_on IS NULL AND (created_on, id) > ($2, $3) ORDER BY created_on, id LIMIT 25`
+	queryFilter := &QueryFilter{
+		Page:      1,
+		Limit:     25,
+		UseCursor: true,
+		Cursor:    exampleCursor,
+		SkipCount: true,
+	}
+	actualQuery, actualArgs := buildProductOptionListQuery(existingID, queryFilter)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionCountQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT count(id) FROM product_options WHERE product_id = $1 AND archived_on IS NULL LIMIT 25`
+	queryFilter := &QueryFilter{
+		Page:      1,
+		Limit:     25,
+		UseCursor: true,
+		Cursor:    exampleCursor,
+	}
+	actualQuery, actualArgs := buildProductOptionCountQuery(existingID, queryFilter)
+
+	assert.Equal(t, expectedQuery, actualQuery, "the count shouldn't be limited to what's after the cursor")
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionListQueryForProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		name,
+		product_id,
+		created_on,
+		updated_on,
+		archived_on,
+		position
+	 FROM product_options WHERE product_id IN ($1,$2) AND archived_on IS NULL ORDER BY position, id`
+	actualQuery, actualArgs := buildProductOptionListQueryForProducts([]uint64{1, 2})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
 func TestBuildProductOptionUpdateQuery(t *testing.T) {
 	t.Parallel()
 	expectedQuery := `UPDATE product_options SET name = $1, updated_on = NOW() WHERE id = $2 RETURNING *`
@@ -184,28 +378,539 @@ func TestBuildProductOptionUpdateQuery(t *testing.T) {
 
 func TestBuildProductOptionCreationQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `INSERT INTO product_options (name,product_id) VALUES ($1,$2) RETURNING "id"`
+	expectedQuery := `INSERT INTO product_options (name,product_id,position) VALUES ($1,$2,(SELECT COALESCE(MAX(position) + 1, 0) FROM product_options WHERE product_id = $3 AND archived_on IS NULL)) RETURNING "id"`
 	actualQuery, actualArgs := buildProductOptionCreationQuery(&ProductOption{}, exampleProduct.ID)
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
-	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionValueListQueryForOptions(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		product_option_id,
+		value,
+		created_on,
+		updated_on,
+		archived_on,
+		price_delta,
+		weight_delta,
+		sku_suffix,
+		position
+	 FROM product_option_values WHERE product_option_id IN ($1) AND archived_on IS NULL ORDER BY position, id`
+	actualQuery, actualArgs := buildProductOptionValueListQueryForOptions([]uint64{1})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
 }
 
 func TestBuildProductOptionValueUpdateQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `UPDATE product_option_values SET updated_on = NOW(), value = $1 WHERE id = $2 RETURNING *`
+	expectedQuery := `UPDATE product_option_values SET price_delta = $1, sku_suffix = $2, updated_on = NOW(), value = $3, weight_delta = $4 WHERE id = $5 RETURNING *`
 	actualQuery, actualArgs := buildProductOptionValueUpdateQuery(&ProductOptionValue{})
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
-	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+	assert.Equal(t, 5, len(actualArgs), argsEqualityErrorMessage)
 }
 
 func TestBuildProductOptionValueCreationQuery(t *testing.T) {
 	t.Parallel()
-	expectedQuery := `INSERT INTO product_option_values (product_option_id,value) VALUES ($1,$2) RETURNING "id"`
+	expectedQuery := `INSERT INTO product_option_values (product_option_id,value,price_delta,weight_delta,sku_suffix,position) VALUES ($1,$2,$3,$4,$5,(SELECT COALESCE(MAX(position) + 1, 0) FROM product_option_values WHERE product_option_id = $6 AND archived_on IS NULL)) RETURNING "id"`
 	actualQuery, actualArgs := buildProductOptionValueCreationQuery(&ProductOptionValue{})
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 6, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionValueListQueryForProduct(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productOptionValuesHeaders + ` FROM product_option_values WHERE id IN ($1,$2) AND product_option_id IN (SELECT id FROM product_options WHERE product_id = $3 AND archived_on IS NULL) AND archived_on IS NULL ORDER BY product_option_id`
+	actualQuery, actualArgs := buildProductOptionValueListQueryForProduct(exampleProduct.ID, []uint64{1, 2})
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductReviewListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		product_id,
+		user_id,
+		rating,
+		title,
+		body,
+		verified_purchase,
+		status,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM product_reviews WHERE status = $1 AND archived_on IS NULL AND product_id = $2 LIMIT 25`
+	actualQuery, actualArgs := buildProductReviewListQuery(existingID, reviewStatusApproved, &QueryFilter{})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductReviewListQueryForAllProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		product_id,
+		user_id,
+		rating,
+		title,
+		body,
+		verified_purchase,
+		status,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM product_reviews WHERE status = $1 AND archived_on IS NULL LIMIT 25`
+	actualQuery, actualArgs := buildProductReviewListQuery(0, reviewStatusPending, &QueryFilter{})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductReviewCountQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT count(id) FROM product_reviews WHERE status = $1 AND archived_on IS NULL AND product_id = $2 LIMIT 25`
+	queryFilter := &QueryFilter{
+		Page:      1,
+		Limit:     25,
+		UseCursor: true,
+		Cursor:    exampleCursor,
+	}
+	actualQuery, actualArgs := buildProductReviewCountQuery(existingID, reviewStatusApproved, queryFilter)
+
+	assert.Equal(t, expectedQuery, actualQuery, "the count shouldn't be limited to what's after the cursor")
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductReviewSummaryQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT product_id, ROUND(AVG(rating), 2), count(id) FROM product_reviews WHERE product_id IN ($1,$2) AND status = $3 AND archived_on IS NULL GROUP BY product_id`
+	actualQuery, actualArgs := buildProductReviewSummaryQuery([]uint64{1, 2})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductPriceListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		product_id,
+		currency,
+		price,
+		sale_price,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM product_prices WHERE product_id IN ($1) AND archived_on IS NULL AND currency = $2 ORDER BY currency`
+	actualQuery, actualArgs := buildProductPriceListQuery([]uint64{existingID}, "EUR")
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductPriceUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_prices (product_id,currency,price,sale_price) VALUES ($1,$2,$3,$4) ON CONFLICT ("product_id", "currency") DO UPDATE SET price = EXCLUDED.price, sale_price = EXCLUDED.sale_price, updated_on = NOW(), archived_on = NULL RETURNING id,
+		product_id,
+		currency,
+		price,
+		sale_price,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildProductPriceUpsertQuery(&ProductPrice{ProductID: existingID, Currency: "EUR", Price: 8000})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 4, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildExchangeRateUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO exchange_rates (currency,rate) VALUES ($1,$2) ON CONFLICT ("currency") DO UPDATE SET rate = EXCLUDED.rate, updated_on = NOW(), archived_on = NULL RETURNING id,
+		currency,
+		rate,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildExchangeRateUpsertQuery(&ExchangeRate{Currency: "EUR", Rate: "0.9123"})
+
 	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
 	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
 }
 
+func TestBuildProductPriceTierListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		product_id,
+		min_quantity,
+		unit_price,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM product_price_tiers WHERE product_id IN ($1,$2) AND archived_on IS NULL ORDER BY product_id, min_quantity`
+	actualQuery, actualArgs := buildProductPriceTierListQuery([]uint64{existingID, existingID + 1})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductPriceTierUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_price_tiers (product_id,min_quantity,unit_price) VALUES ($1,$2,$3) ON CONFLICT ("product_id", "min_quantity") DO UPDATE SET unit_price = EXCLUDED.unit_price, updated_on = NOW(), archived_on = NULL RETURNING id,
+		product_id,
+		min_quantity,
+		unit_price,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildProductPriceTierUpsertQuery(&ProductPriceTier{ProductID: existingID, MinQuantity: 10, UnitPrice: 8999})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductBundleComponentListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT c.bundle_product_id, c.component_product_id, p.sku, c.quantity, CASE WHEN p.archived_on IS NULL THEN p.quantity ELSE 0 END, p.price, p.on_sale, p.sale_price FROM product_bundle_components c JOIN products p ON p.id = c.component_product_id WHERE c.bundle_product_id IN ($1) AND c.archived_on IS NULL ORDER BY c.bundle_product_id, c.id`
+	actualQuery, actualArgs := buildProductBundleComponentListQuery([]uint64{existingID})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductBundleComponentCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_bundle_components (bundle_product_id,component_product_id,quantity) VALUES ($1,$2,$3),($4,$5,$6)`
+	components := []ProductBundleComponent{{ProductID: 10, Quantity: 1}, {ProductID: 11, Quantity: 4}}
+	actualQuery, actualArgs := buildProductBundleComponentCreationQuery(existingID, components)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 6, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildBundleComponentProductQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT p.id, p.sku, p.quantity, p.price, p.on_sale, p.sale_price, b.id IS NOT NULL FROM products p LEFT JOIN product_bundles b ON b.product_id = p.id AND b.archived_on IS NULL WHERE p.sku IN ($1,$2) AND p.archived_on IS NULL`
+	actualQuery, actualArgs := buildBundleComponentProductQuery([]string{"deck", "wheel"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductListQueryByIDs(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTableHeaders + ` FROM products WHERE id IN ($1,$2) AND archived_on IS NULL`
+	actualQuery, actualArgs := buildProductListQueryByIDs([]uint64{existingID, 2}, false)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductRelationListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT r.id, r.product_id, r.related_product_id, p.sku, r.kind, r.position, r.created_on, r.updated_on, r.archived_on FROM product_relations r JOIN products p ON p.id = r.related_product_id WHERE r.product_id = $1 AND r.archived_on IS NULL AND p.archived_on IS NULL ORDER BY r.kind, r.position, r.id`
+	actualQuery, actualArgs := buildProductRelationListQuery(existingID)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductRelationUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_relations (product_id,related_product_id,kind,position) VALUES ($1,$2,$3,$4) ON CONFLICT ("product_id", "related_product_id", "kind") DO UPDATE SET position = EXCLUDED.position, updated_on = NOW(), archived_on = NULL RETURNING id,
+		product_id,
+		related_product_id,
+		kind,
+		position,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildProductRelationUpsertQuery(&ProductRelation{ProductID: existingID, RelatedProductID: 2, Kind: "upsell", Position: 1})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 4, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductFileKeyListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT blob_key FROM product_files WHERE product_id IN ($1,$2)`
+	actualQuery, actualArgs := buildProductFileKeyListQuery([]uint64{existingID, 2})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductFileUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_files (product_id,blob_key,filename,content_type,size) VALUES ($1,$2,$3,$4,$5) ON CONFLICT ("product_id") DO UPDATE SET blob_key = EXCLUDED.blob_key, filename = EXCLUDED.filename, content_type = EXCLUDED.content_type, size = EXCLUDED.size, updated_on = NOW(), archived_on = NULL RETURNING id,
+		product_id,
+		blob_key,
+		filename,
+		content_type,
+		size,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildProductFileUpsertQuery(&ProductFile{ProductID: existingID, BlobKey: "key", Filename: "album.zip", ContentType: "application/zip", Size: 1024})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 5, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildDownloadGrantCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO download_grants (token,product_id,user_id,expires_on,max_downloads) VALUES ($1,$2,$3,$4,$5) RETURNING id,
+		token,
+		product_id,
+		user_id,
+		expires_on,
+		max_downloads,
+		download_count,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildDownloadGrantCreationQuery(&DownloadGrant{Token: "token", ProductID: existingID, UserID: 5, ExpiresOn: generateExampleTimeForTests(), MaxDownloads: 5})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 5, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildGiftCardCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO gift_cards (code,product_id,initial_balance,remaining_balance,currency,expires_on) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id,
+		code,
+		product_id,
+		initial_balance,
+		remaining_balance,
+		currency,
+		expires_on,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildGiftCardCreationQuery(&GiftCard{Code: "CODE", InitialBalance: 5000, RemainingBalance: 5000, Currency: "USD"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 6, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildGiftCardTransactionCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO gift_card_transactions (gift_card_id,kind,amount,balance_after,user_id,reference) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id,
+		gift_card_id,
+		kind,
+		amount,
+		balance_after,
+		user_id,
+		reference,
+		created_on
+	`
+	actualQuery, actualArgs := buildGiftCardTransactionCreationQuery(&GiftCardTransaction{GiftCardID: 7, Kind: "debit", Amount: 1000, BalanceAfter: 4000})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 6, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildGiftCardTransactionListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		gift_card_id,
+		kind,
+		amount,
+		balance_after,
+		user_id,
+		reference,
+		created_on
+	 FROM gift_card_transactions WHERE gift_card_id = $1 ORDER BY id`
+	actualQuery, actualArgs := buildGiftCardTransactionListQuery(7)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductListQueryWithAttributeFilter(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTableHeaders + ` FROM products WHERE archived_on IS NULL AND attributes @> $1 LIMIT 25`
+	actualQuery, actualArgs := buildProductListQuery(&QueryFilter{Limit: 25, Attributes: ProductAttributes{"material": "oak"}})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductAttributeDefinitionListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productAttributeDefinitionsTableHeaders + ` FROM product_attribute_definitions WHERE archived_on IS NULL LIMIT 25`
+	actualQuery, actualArgs := buildProductAttributeDefinitionListQuery(defaultQueryFilter)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductAttributeDefinitionsQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productAttributeDefinitionsTableHeaders + ` FROM product_attribute_definitions WHERE archived_on IS NULL ORDER BY name`
+	actualQuery, actualArgs := buildProductAttributeDefinitionsQuery(nil)
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 0, len(actualArgs), argsEqualityErrorMessage)
+
+	expectedQuery = `SELECT ` + productAttributeDefinitionsTableHeaders + ` FROM product_attribute_definitions WHERE archived_on IS NULL AND name IN ($1,$2) ORDER BY name`
+	actualQuery, actualArgs = buildProductAttributeDefinitionsQuery([]string{"material", "ply"})
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductAttributeDefinitionCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_attribute_definitions (name,type,required,choices) VALUES ($1,$2,$3,$4) RETURNING ` + productAttributeDefinitionsTableHeaders
+	actualQuery, actualArgs := buildProductAttributeDefinitionCreationQuery(&exampleProductAttributeDefinition)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 4, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductAttributeDefinitionUpdateQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `UPDATE product_attribute_definitions SET choices = $1, required = $2, updated_on = NOW() WHERE id = $3 RETURNING ` + productAttributeDefinitionsTableHeaders
+	actualQuery, actualArgs := buildProductAttributeDefinitionUpdateQuery(&exampleProductAttributeDefinition)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductTranslationListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTranslationsTableHeaders + ` FROM product_translations WHERE product_id = $1 AND archived_on IS NULL ORDER BY locale`
+	actualQuery, actualArgs := buildProductTranslationListQuery(exampleProduct.ID)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductTranslationListQueryForProducts(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productTranslationsTableHeaders + ` FROM product_translations WHERE product_id IN ($1,$2) AND locale IN ($3,$4) AND archived_on IS NULL`
+	actualQuery, actualArgs := buildProductTranslationListQueryForProducts([]uint64{1, 2}, []string{"de", "es"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 4, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductTranslationUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_translations (product_id,locale,name,subtitle,description) VALUES ($1,$2,$3,$4,$5) ON CONFLICT ("product_id", "locale") DO UPDATE SET name = EXCLUDED.name, subtitle = EXCLUDED.subtitle, description = EXCLUDED.description, updated_on = NOW(), archived_on = NULL RETURNING ` + productTranslationsTableHeaders
+	actualQuery, actualArgs := buildProductTranslationUpsertQuery(&ProductTranslation{ProductID: exampleProduct.ID, Locale: "de", Name: "Rollbrett"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 5, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionTranslationListQueryForOptions(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productOptionTranslationsTableHeaders + ` FROM product_option_translations WHERE product_option_id IN ($1,$2) AND locale IN ($3) AND archived_on IS NULL`
+	actualQuery, actualArgs := buildProductOptionTranslationListQueryForOptions([]uint64{1, 2}, []string{"de"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionTranslationUpsertQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO product_option_translations (product_option_id,locale,name) VALUES ($1,$2,$3) ON CONFLICT ("product_option_id", "locale") DO UPDATE SET name = EXCLUDED.name, updated_on = NOW(), archived_on = NULL RETURNING ` + productOptionTranslationsTableHeaders
+	actualQuery, actualArgs := buildProductOptionTranslationUpsertQuery(&ProductOptionTranslation{ProductOptionID: 1, Locale: "de", Name: "Farbe"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildProductOptionValueTranslationListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT ` + productOptionValueTranslationsTableHeaders + ` FROM product_option_value_translations WHERE product_option_value_id = $1 AND archived_on IS NULL ORDER BY locale`
+	actualQuery, actualArgs := buildProductOptionValueTranslationListQuery(1)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildInventoryLocationCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO inventory_locations (name,sellable) VALUES ($1,$2) RETURNING ` + inventoryLocationsTableHeaders
+	actualQuery, actualArgs := buildInventoryLocationCreationQuery(&InventoryLocation{Name: "shop", Sellable: true})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildInventoryLocationUpdateQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `UPDATE inventory_locations SET name = $1, sellable = $2, updated_on = NOW() WHERE id = $3 RETURNING ` + inventoryLocationsTableHeaders
+	actualQuery, actualArgs := buildInventoryLocationUpdateQuery(&InventoryLocation{DBRow: DBRow{ID: existingID}, Name: "shop"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildInventoryMovementCreationQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `INSERT INTO inventory_movements (product_id,location_id,option_value_ids,delta,quantity_after,reason,reference,user_id) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING ` + inventoryMovementsTableHeaders
+	actualQuery, actualArgs := buildInventoryMovementCreationQuery(&InventoryMovement{ProductID: existingID, Delta: 5, Reason: inventoryMovementReasonReceived})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 8, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildCustomerGroupPriceListQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		customer_group_id,
+		product_id,
+		price,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM customer_group_prices WHERE customer_group_id = $1 AND archived_on IS NULL AND product_id IN ($2) ORDER BY product_id`
+	actualQuery, actualArgs := buildCustomerGroupPriceListQuery(existingID, []uint64{existingID})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 2, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildCustomerGroupPriceListQueryForEntirePriceList(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `SELECT id,
+		customer_group_id,
+		product_id,
+		price,
+		created_on,
+		updated_on,
+		archived_on
+	 FROM customer_group_prices WHERE customer_group_id = $1 AND archived_on IS NULL ORDER BY product_id`
+	actualQuery, actualArgs := buildCustomerGroupPriceListQuery(existingID, nil)
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 1, len(actualArgs), argsEqualityErrorMessage)
+}
+
+func TestBuildCustomerGroupUpdateQuery(t *testing.T) {
+	t.Parallel()
+	expectedQuery := `UPDATE customer_groups SET description = $1, name = $2, updated_on = NOW() WHERE id = $3 RETURNING id,
+		name,
+		description,
+		created_on,
+		updated_on,
+		archived_on
+	`
+	actualQuery, actualArgs := buildCustomerGroupUpdateQuery(&CustomerGroup{DBRow: DBRow{ID: existingID}, Name: "staff"})
+
+	assert.Equal(t, expectedQuery, actualQuery, queryEqualityErrorMessage)
+	assert.Equal(t, 3, len(actualArgs), argsEqualityErrorMessage)
+}
+
 func TestBuildDiscountListQuery(t *testing.T) {
 	t.Parallel()
 	expectedQuery := "SELECT \n\t\tid,\n\t\tname,\n\t\ttype,\n\t\tamount,\n\t\tstarts_on,\n\t\texpires_on,\n\t\trequires_code,\n\t\tcode,\n\t\tlimited_use,\n\t\tnumber_of_uses,\n\t\tlogin_required,\n\t\tcreated_on,\n\t\tupdated_on,\n\t\tarchived_on\n\t FROM discounts WHERE (expires_on IS NULL OR expires_on > $1) AND archived_on IS NULL LIMIT 25"
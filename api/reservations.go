@@ -0,0 +1,375 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	reservationsTableHeaders = `id,
+		product_id,
+		quantity,
+		status,
+		user_id,
+		expires_on,
+		created_on,
+		updated_on
+	`
+
+	reservationStatusActive    = "active"
+	reservationStatusConfirmed = "confirmed"
+	reservationStatusReleased  = "released"
+	reservationStatusExpired   = "expired"
+
+	defaultReservationTTL    = 15 * time.Minute
+	maxReservationTTL        = 24 * time.Hour
+	reservationSweepInterval = time.Minute
+
+	// productActiveHoldsExpression is how much of a product's stock is held by reservations that haven't run out yet
+	productActiveHoldsExpression = `(SELECT COALESCE(SUM(quantity), 0) FROM reservations WHERE product_id = products.id AND status = 'active' AND expires_on > NOW())`
+	// locking the product while its quantity is compared with its holds keeps reservations from being placed in the meantime
+	productStockAndHoldsQuery = `SELECT quantity, ` + productActiveHoldsExpression + `, EXISTS(SELECT 1 FROM inventory_levels WHERE product_id = products.id) FROM products WHERE id = $1 FOR UPDATE`
+
+	// locking the product serializes reservations for it, so that the insert below (which, being a separate
+	// statement, sees every hold committed while we waited for the lock) can't be raced into overselling
+	productLockQuery = `SELECT id FROM products WHERE id = $1 FOR UPDATE`
+	// a hold is only placed if what's in stock, less what's already held, covers it, or the product's stock policy
+	// lets it be oversold by the difference
+	reservationCreationQuery = `
+		INSERT INTO reservations (product_id, quantity, user_id, expires_on)
+		SELECT id, $2, $3, $4 FROM products
+		WHERE id = $1 AND (quantity - $2 - ` + productActiveHoldsExpression + ` >= ` + productStockFloorExpression + `) IS NOT FALSE
+		RETURNING ` + reservationsTableHeaders
+	reservationRetrievalQuery = `SELECT ` + reservationsTableHeaders + ` FROM reservations WHERE id = $1`
+	// holds can only be released or confirmed while they're still active
+	reservationStatusUpdateQuery = `
+		UPDATE reservations SET status = $2, updated_on = NOW()
+		WHERE id = $1 AND status = 'active' AND expires_on > NOW()
+		RETURNING ` + reservationsTableHeaders
+	reservationExpiryQuery = `UPDATE reservations SET status = 'expired', updated_on = NOW() WHERE status = 'active' AND expires_on <= NOW()`
+)
+
+// errReservationRefused means a hold wasn't placed because there isn't enough unreserved stock to cover it
+var errReservationRefused = errors.New("reservation refused")
+
+// Reservation is a time-limited hold on some of a product's stock, placed while a customer pays for it
+type Reservation struct {
+	ID        uint64    `json:"id"`
+	ProductID uint64    `json:"product_id"`
+	Quantity  uint32    `json:"quantity"`
+	Status    string    `json:"status"`
+	UserID    *uint64   `json:"user_id,omitempty"`
+	ExpiresOn time.Time `json:"expires_on"`
+	CreatedOn time.Time `json:"created_on"`
+	UpdatedOn NullTime  `json:"updated_on,omitempty"`
+}
+
+func (r *Reservation) generateScanArgs() []interface{} {
+	return []interface{}{
+		&r.ID,
+		&r.ProductID,
+		&r.Quantity,
+		&r.Status,
+		&r.UserID,
+		&r.ExpiresOn,
+		&r.CreatedOn,
+		&r.UpdatedOn,
+	}
+}
+
+// active reports whether a reservation is still holding stock, which expired holds stop doing before they're swept
+func (r *Reservation) active(now time.Time) bool {
+	return r.Status == reservationStatusActive && r.ExpiresOn.After(now)
+}
+
+// ReservationCreationInput is a struct to use for holding some of a product's stock
+type ReservationCreationInput struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity uint32 `json:"quantity" validate:"required"`
+	// TTL is how many seconds the hold lasts, and defaults to fifteen minutes
+	TTL uint32 `json:"ttl"`
+}
+
+func retrieveReservationFromDB(db *sqlx.DB, reservationID string) (*Reservation, error) {
+	r := &Reservation{}
+	err := db.QueryRow(reservationRetrievalQuery, reservationID).Scan(r.generateScanArgs()...)
+	return r, err
+}
+
+// createReservationInDB places a hold, returning errReservationRefused if there isn't enough unreserved stock for it
+func createReservationInDB(db *sqlx.DB, r *Reservation) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Error creating database transaction")
+	}
+
+	var productID uint64
+	if err = tx.QueryRow(productLockQuery, r.ProductID).Scan(&productID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.QueryRow(reservationCreationQuery, r.ProductID, r.Quantity, r.UserID, r.ExpiresOn).Scan(r.generateScanArgs()...)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return errReservationRefused
+	} else if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// releaseReservation gives a hold's stock back, returning sql.ErrNoRows if the hold isn't active anymore
+func releaseReservation(db *sqlx.DB, r *Reservation) error {
+	return db.QueryRow(reservationStatusUpdateQuery, r.ID, reservationStatusReleased).Scan(r.generateScanArgs()...)
+}
+
+// confirmReservation turns a hold into a sale, taking its stock away and recording the sale in the product's ledger,
+// all in one transaction. It returns sql.ErrNoRows if the hold isn't active anymore.
+func confirmReservation(db *sqlx.DB, r *Reservation, m *InventoryMovement) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Error creating database transaction")
+	}
+
+	if err = tx.QueryRow(reservationStatusUpdateQuery, r.ID, reservationStatusConfirmed).Scan(r.generateScanArgs()...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = applyInventoryMovement(tx, m); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// expireReservations marks every hold that has run out as expired, and returns how many there were
+func expireReservations(db *sqlx.DB) (int64, error) {
+	result, err := db.Exec(reservationExpiryQuery)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// sweepExpiredReservations expires stale holds every interval, forever. Stock checks already ignore holds
+// that have run out, so this is what lets everyone else see them as expired.
+func sweepExpiredReservations(db *sqlx.DB, interval time.Duration) {
+	for range time.Tick(interval) {
+		expired, err := expireReservations(db)
+		if err != nil {
+			log.Printf("error encountered expiring reservations: %v", err)
+		} else if expired > 0 {
+			log.Printf("expired %d reservations", expired)
+		}
+	}
+}
+
+// retrieveReservationForSession retrieves a reservation, treating ones that belong to other users as
+// nonexistent unless the session belongs to an admin
+func retrieveReservationForSession(db *sqlx.DB, req *http.Request, store *sessions.CookieStore, reservationID string) (*Reservation, error) {
+	r, err := retrieveReservationFromDB(db, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := retrieveUserIDFromSession(req, store)
+	if !sessionBelongsToAdmin(req, store) && (r.UserID == nil || *r.UserID != userID) {
+		return nil, sql.ErrNoRows
+	}
+	return r, nil
+}
+
+func buildReservationCreationHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ReservationCreationHandler is a request handler that holds some of a product's stock for a while
+	return func(res http.ResponseWriter, req *http.Request) {
+		reservationInput := &ReservationCreationInput{}
+		err := validateRequestInput(req, reservationInput)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		ttl := defaultReservationTTL
+		if reservationInput.TTL != 0 {
+			ttl = time.Duration(reservationInput.TTL) * time.Second
+		}
+		if ttl > maxReservationTTL {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("reservations can't be held for more than %d seconds", int(maxReservationTTL.Seconds())))
+			return
+		}
+
+		productID, err := retrieveProductIDForSession(db, req, store, reservationInput.SKU)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", reservationInput.SKU)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from the database")
+			return
+		}
+		if isBundle, err := productIsBundle(db, productID); err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundle from the database")
+			return
+		} else if isBundle {
+			notifyOfInvalidRequestBody(res, errStockOperationOnBundle)
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		reservation := &Reservation{
+			ProductID: productID,
+			Quantity:  reservationInput.Quantity,
+			UserID:    movementUserID(userID),
+			ExpiresOn: time.Now().UTC().Add(ttl).Truncate(time.Second),
+		}
+		err = createReservationInDB(db, reservation)
+		if err == errReservationRefused {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("there isn't enough stock of %s available to hold %d", reservationInput.SKU, reservationInput.Quantity))
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "create reservation in database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(reservation)
+	}
+}
+
+func buildReservationRetrievalHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ReservationRetrievalHandler is a request handler that returns a single reservation
+	return func(res http.ResponseWriter, req *http.Request) {
+		reservationID := chi.URLParam(req, "reservation_id")
+
+		reservation, err := retrieveReservationForSession(db, req, store, reservationID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "reservation", reservationID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve reservation from the database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(reservation)
+	}
+}
+
+func buildReservationReleaseHandler(db *sqlx.DB, store *sessions.CookieStore) http.HandlerFunc {
+	// ReservationReleaseHandler is a request handler that gives a hold's stock back before it expires
+	return func(res http.ResponseWriter, req *http.Request) {
+		reservationID := chi.URLParam(req, "reservation_id")
+
+		reservation, err := retrieveReservationForSession(db, req, store, reservationID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "reservation", reservationID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve reservation from the database")
+			return
+		}
+
+		err = releaseReservation(db, reservation)
+		if err == sql.ErrNoRows {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("reservation %s is no longer active", reservationID))
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "release reservation")
+			return
+		}
+
+		json.NewEncoder(res).Encode(reservation)
+	}
+}
+
+func buildReservationConfirmationHandler(db *sqlx.DB, store *sessions.CookieStore, notifier LowStockNotifier) http.HandlerFunc {
+	// ReservationConfirmationHandler is a request handler that turns a hold into a sale. Products that are stocked
+	// at inventory locations have to say which location the held stock is taken from, with a location_id parameter.
+	return func(res http.ResponseWriter, req *http.Request) {
+		reservationID := chi.URLParam(req, "reservation_id")
+
+		var locationID uint64
+		if rawLocationID := req.URL.Query().Get("location_id"); rawLocationID != "" {
+			parsedLocationID, err := strconv.ParseUint(rawLocationID, 10, 64)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, fmt.Errorf("invalid location_id: %s", rawLocationID))
+				return
+			}
+			locationID = parsedLocationID
+		}
+
+		reservation, err := retrieveReservationForSession(db, req, store, reservationID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "reservation", reservationID)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve reservation from the database")
+			return
+		}
+		if !reservation.active(time.Now()) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("reservation %s is no longer active", reservationID))
+			return
+		}
+		// bundles can't be reserved anymore, but holds placed on them before that still can't take stock from their row
+		if isBundle, err := productIsBundle(db, reservation.ProductID); err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product bundle from the database")
+			return
+		} else if isBundle {
+			notifyOfInvalidRequestBody(res, errStockOperationOnBundle)
+			return
+		}
+
+		userID, _ := retrieveUserIDFromSession(req, store)
+		movement := &InventoryMovement{
+			ProductID: reservation.ProductID,
+			Delta:     -int(reservation.Quantity),
+			Reason:    inventoryMovementReasonSold,
+			Reference: fmt.Sprintf("reservation %d", reservation.ID),
+			UserID:    movementUserID(userID),
+		}
+
+		if locationID != 0 {
+			exists, err := rowExistsInDB(db, inventoryLocationExistenceQuery, fmt.Sprintf("%d", locationID))
+			if err != nil || !exists {
+				respondThatRowDoesNotExist(req, res, "inventory location", fmt.Sprintf("%d", locationID))
+				return
+			}
+			movement.LocationID = &locationID
+		} else {
+			stockedAtLocations, err := rowExistsInDB(db, productInventoryLevelExistenceQuery, fmt.Sprintf("%d", reservation.ProductID))
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve inventory levels from the database")
+				return
+			} else if stockedAtLocations {
+				notifyOfInvalidRequestBody(res, errors.New("the reserved product is stocked at inventory locations, so a location_id is required"))
+				return
+			}
+		}
+
+		err = confirmReservation(db, reservation, movement)
+		if err == sql.ErrNoRows {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("reservation %s is no longer active", reservationID))
+			return
+		} else if err == errInventoryAdjustmentRefused {
+			notifyOfInvalidRequestBody(res, errors.New("there isn't enough stock at this location to confirm the reservation"))
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "confirm reservation")
+			return
+		}
+		checkProductForLowStock(db, notifier, reservation.ProductID)
+
+		json.NewEncoder(res).Encode(reservation)
+	}
+}
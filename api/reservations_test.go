@@ -0,0 +1,494 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	reservationHeaders = strings.Split(strings.TrimSpace(reservationsTableHeaders), ",\n\t\t")
+	exampleReservation = &Reservation{
+		ID:        5,
+		ProductID: 2,
+		Quantity:  3,
+		Status:    reservationStatusActive,
+		ExpiresOn: time.Now().Add(time.Hour),
+		CreatedOn: generateExampleTimeForTests(),
+	}
+)
+
+func setExpectationsForReservationRetrieval(mock sqlmock.Sqlmock, id string, r *Reservation, userID uint64, err error) {
+	exampleRows := sqlmock.NewRows(reservationHeaders).
+		AddRow(r.ID, r.ProductID, r.Quantity, r.Status, userID, r.ExpiresOn, r.CreatedOn, nil)
+	mock.ExpectQuery(formatQueryForSQLMock(reservationRetrievalQuery)).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductLock(mock sqlmock.Sqlmock, productID uint64, err error) {
+	exampleRows := sqlmock.NewRows([]string{"id"}).AddRow(productID)
+	mock.ExpectQuery(formatQueryForSQLMock(productLockQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForProductStockAndHolds(mock sqlmock.Sqlmock, productID uint64, quantity int, held int, stockedAtLocations bool) {
+	exampleRows := sqlmock.NewRows([]string{"quantity", "held", "stocked_at_locations"}).AddRow(quantity, held, stockedAtLocations)
+	mock.ExpectQuery(formatQueryForSQLMock(productStockAndHoldsQuery)).
+		WithArgs(productID).
+		WillReturnRows(exampleRows)
+}
+
+func setExpectationsForReservationCreation(mock sqlmock.Sqlmock, productID uint64, quantity uint32, userID uint64, err error) {
+	exampleRows := sqlmock.NewRows(reservationHeaders).
+		AddRow(exampleReservation.ID, productID, quantity, reservationStatusActive, userID, exampleReservation.ExpiresOn, generateExampleTimeForTests(), nil)
+	mock.ExpectQuery(formatQueryForSQLMock(reservationCreationQuery)).
+		WithArgs(productID, quantity, userID, sqlmock.AnyArg()).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func setExpectationsForReservationStatusUpdate(mock sqlmock.Sqlmock, r *Reservation, status string, err error) {
+	exampleRows := sqlmock.NewRows(reservationHeaders).
+		AddRow(r.ID, r.ProductID, r.Quantity, status, 1, r.ExpiresOn, r.CreatedOn, generateExampleTimeForTests())
+	mock.ExpectQuery(formatQueryForSQLMock(reservationStatusUpdateQuery)).
+		WithArgs(r.ID, status).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestReservationActive(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	assert.True(t, (&Reservation{Status: reservationStatusActive, ExpiresOn: now.Add(time.Minute)}).active(now))
+	assert.False(t, (&Reservation{Status: reservationStatusActive, ExpiresOn: now}).active(now))
+	assert.False(t, (&Reservation{Status: reservationStatusReleased, ExpiresOn: now.Add(time.Minute)}).active(now))
+}
+
+func TestExpireReservations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(reservationExpiryQuery)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	expired, err := expireReservations(testUtil.DB)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), expired)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestExpireReservationsWithError(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	testUtil.Mock.ExpectExec(formatQueryForSQLMock(reservationExpiryQuery)).
+		WillReturnError(arbitraryError)
+
+	_, err := expireReservations(testUtil.DB)
+	assert.NotNil(t, err)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductLock(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForReservationCreation(testUtil.Mock, exampleProduct.ID, 3, 1, nil)
+	testUtil.Mock.ExpectCommit()
+
+	body := `{"sku": "skateboard", "quantity": 3, "ttl": 600}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+
+	actual := &Reservation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), actual.Quantity)
+	assert.Equal(t, reservationStatusActive, actual.Status)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerForPreorderProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// a pre-order that hasn't come out yet is found among the published products, and has no stock to hold
+	// yet, so the hold is placed against how far it may be oversold
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductLock(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForReservationCreation(testUtil.Mock, exampleProduct.ID, 2, 1, nil)
+	testUtil.Mock.ExpectCommit()
+
+	body := `{"sku": "skateboard", "quantity": 2}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusCreated, testUtil.Response.Code, "status code should be 201")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerForBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, true, nil)
+
+	body := `{"sku": "skateboard", "quantity": 3}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithInsufficientStock(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductLock(testUtil.Mock, exampleProduct.ID, nil)
+	setExpectationsForReservationCreation(testUtil.Mock, exampleProduct.ID, 500, 1, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+
+	body := `{"sku": "skateboard", "quantity": 500}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithErrorLockingProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, exampleProduct.SKU, exampleProduct.ID, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleProduct.ID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForProductLock(testUtil.Mock, exampleProduct.ID, arbitraryError)
+	testUtil.Mock.ExpectRollback()
+
+	body := `{"sku": "skateboard", "quantity": 3}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusInternalServerError, testUtil.Response.Code, "status code should be 500")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithExcessiveTTL(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"sku": "skateboard", "quantity": 3, "ttl": 604800}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithoutQuantity(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(`{"sku": "skateboard"}`))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithNonexistentProduct(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForPublishedProductIDRetrievalBySKU(testUtil.Mock, "nonexistent", 0, sql.ErrNoRows)
+
+	body := `{"sku": "nonexistent", "quantity": 3}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationCreationHandlerWithoutSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	body := `{"sku": "skateboard", "quantity": 3}`
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations"), strings.NewReader(body))
+	assert.Nil(t, err)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusUnauthorized, testUtil.Response.Code, "status code should be 401")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationRetrievalHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "reservations", reservationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationRetrievalHandlerForAnotherUsersReservation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 2, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "reservations", reservationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationRetrievalHandlerForAdmin(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 2, nil)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "reservations", reservationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationRetrievalHandlerWithNonexistentReservation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	setExpectationsForReservationRetrieval(testUtil.Mock, "9", exampleReservation, 1, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodGet, buildRoute("v1", "reservations", "9"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusNotFound, testUtil.Response.Code, "status code should be 404")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationReleaseHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForReservationStatusUpdate(testUtil.Mock, exampleReservation, reservationStatusReleased, nil)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "release"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Reservation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, reservationStatusReleased, actual.Status)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationReleaseHandlerForInactiveReservation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForReservationStatusUpdate(testUtil.Mock, exampleReservation, reservationStatusReleased, sql.ErrNoRows)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "release"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandler(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleReservation.ProductID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleReservation.ProductID, false, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForReservationStatusUpdate(testUtil.Mock, exampleReservation, reservationStatusConfirmed, nil)
+	setExpectationsForProductQuantityAdjustment(testUtil.Mock, exampleReservation.ProductID, -3, 120, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleReservation.ProductID, Delta: -3, QuantityAfter: 120, Reason: inventoryMovementReasonSold, Reference: "reservation 5"}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleReservation.ProductID)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+
+	actual := &Reservation{}
+	err = json.NewDecoder(testUtil.Response.Body).Decode(actual)
+	assert.Nil(t, err)
+	assert.Equal(t, reservationStatusConfirmed, actual.Status)
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerAtLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleReservation.ProductID}
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleReservation.ProductID, false, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForReservationStatusUpdate(testUtil.Mock, exampleReservation, reservationStatusConfirmed, nil)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelDecreaseQuery, level, -3, 4, nil)
+	setExpectationsForProductQuantitySync(testUtil.Mock, exampleReservation.ProductID, nil)
+	setExpectationsForInventoryMovementCreation(testUtil.Mock, &InventoryMovement{ProductID: exampleReservation.ProductID, LocationID: &exampleInventoryLocation.ID, Delta: -3, QuantityAfter: 4, Reason: inventoryMovementReasonSold, Reference: "reservation 5"}, nil)
+	testUtil.Mock.ExpectCommit()
+	setExpectationsForProductLowStockCheck(testUtil.Mock, exampleReservation.ProductID)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm?location_id="+locationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusOK, testUtil.Response.Code, "status code should be 200")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerForProductStockedAtLocations(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleReservation.ProductID, false, nil)
+	setExpectationsForProductInventoryLevelExistence(testUtil.Mock, exampleReservation.ProductID, true, nil)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerForBundle(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	// no stock should be taken from the bundle's own row, and the hold should be left active
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleReservation.ProductID, true, nil)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerForExpiredReservation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	expired := *exampleReservation
+	expired.ExpiresOn = time.Now().Add(-time.Minute)
+	reservationID := strconv.Itoa(int(expired.ID))
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, &expired, 1, nil)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerWithInvalidLocationID(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", "5", "confirm?location_id=warehouse"), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
+
+func TestReservationConfirmationHandlerWithInsufficientStockAtLocation(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	reservationID := strconv.Itoa(int(exampleReservation.ID))
+	locationID := strconv.Itoa(int(exampleInventoryLocation.ID))
+	level := &InventoryLevel{LocationID: exampleInventoryLocation.ID, ProductID: exampleReservation.ProductID}
+	setExpectationsForReservationRetrieval(testUtil.Mock, reservationID, exampleReservation, 1, nil)
+	setExpectationsForProductBundleExistence(testUtil.Mock, exampleReservation.ProductID, false, nil)
+	setExpectationsForInventoryLocationExistence(testUtil.Mock, locationID, true, nil)
+	testUtil.Mock.ExpectBegin()
+	setExpectationsForReservationStatusUpdate(testUtil.Mock, exampleReservation, reservationStatusConfirmed, nil)
+	setExpectationsForInventoryLevelSave(testUtil.Mock, inventoryLevelDecreaseQuery, level, -3, 0, sql.ErrNoRows)
+	testUtil.Mock.ExpectRollback()
+
+	req, err := http.NewRequest(http.MethodPost, buildRoute("v1", "reservations", reservationID, "confirm?location_id="+locationID), nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+	testUtil.Router.ServeHTTP(testUtil.Response, req)
+	assert.Equal(t, http.StatusBadRequest, testUtil.Response.Code, "status code should be 400")
+	ensureExpectationsWereMet(t, testUtil.Mock)
+}
@@ -14,6 +14,12 @@ const (
 	ValidURLCharactersPattern = `[a-zA-Z\-_]+`
 	// NumericPattern repesents numeric values
 	NumericPattern = `[0-9]+`
+	// CurrencyCodePattern represents ISO 4217 currency codes
+	CurrencyCodePattern = `[a-zA-Z]+`
+	// AlphanumericPattern represents randomly generated tokens
+	AlphanumericPattern = `[a-zA-Z0-9]+`
+	// LocalePattern represents language tags like `de` or `de-AT`
+	LocalePattern = `[a-zA-Z0-9\-]+`
 )
 
 func buildRoute(routeVersion string, routeParts ...string) string {
@@ -21,7 +27,7 @@ func buildRoute(routeVersion string, routeParts ...string) string {
 }
 
 // SetupAPIRoutes takes a mux router and a database connection and creates all the API routes for the API
-func SetupAPIRoutes(router *chi.Mux, db *sqlx.DB, store *sessions.CookieStore) {
+func SetupAPIRoutes(router *chi.Mux, db *sqlx.DB, store *sessions.CookieStore, blobs BlobStore, notifier LowStockNotifier) {
 	// Auth
 	router.Post("/login", buildUserLoginHandler(db, store))
 	router.Post("/logout", buildUserLogoutHandler(store))
@@ -31,31 +37,160 @@ func SetupAPIRoutes(router *chi.Mux, db *sqlx.DB, store *sessions.CookieStore) {
 	router.Head("/password_reset/{reset_token}", buildUserPasswordResetTokenValidationHandler(db))
 	//router.Head("/password_reset/{reset_token:[a-zA-Z0-9]{}}", buildUserPasswordResetTokenValidationHandler(db))
 
+	// Downloads
+	router.Get(fmt.Sprintf("/download/{token:%s}", AlphanumericPattern), buildDownloadHandler(db, blobs))
+
 	router.Route("/v1", func(r chi.Router) {
 		// Users
 		r.Delete(fmt.Sprintf("/user/{user_id:%s}", NumericPattern), buildUserDeletionHandler(db))
 
 		// Products
 		productEndpoint := fmt.Sprintf("/product/{sku:%s}", ValidURLCharactersPattern)
-		r.Post("/product", buildProductCreationHandler(db))
-		r.Get("/products", buildProductListHandler(db))
-		r.Get(productEndpoint, buildSingleProductHandler(db))
-		r.Patch(productEndpoint, buildProductUpdateHandler(db))
+		r.Post("/product", buildProductCreationHandler(db, store))
+		r.Get("/products", buildProductListHandler(db, store))
+		r.Post("/products/import", buildProductImportHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get("/products/export", buildProductExportHandler(db))
+		r.Get(fmt.Sprintf("/products/barcode/{code:%s}", NumericPattern), buildProductRetrievalByBarcodeHandler(db, store))
+		r.Get(productEndpoint, buildSingleProductHandler(db, store))
+		r.Patch(productEndpoint, buildProductUpdateHandler(db, store, notifier))
 		r.Head(productEndpoint, buildProductExistenceHandler(db))
-		r.Delete(productEndpoint, buildProductDeletionHandler(db))
+		r.Delete(productEndpoint, buildProductDeletionHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(fmt.Sprintf("%s/restore", productEndpoint), buildProductRestorationHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post("/products/purge", buildArchivePurgeHandler(db, blobs))
+
+		// Product Revisions
+		productRevisionsEndpoint := fmt.Sprintf("%s/revisions", productEndpoint)
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(productRevisionsEndpoint, buildProductRevisionListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(fmt.Sprintf("%s/{revision:%s}/revert", productRevisionsEndpoint, NumericPattern), buildProductRevertHandler(db, store))
+
+		// Product Reviews
+		productReviewEndpoint := fmt.Sprintf("%s/reviews", productEndpoint)
+		specificReviewEndpoint := fmt.Sprintf("/product_reviews/{review_id:%s}", NumericPattern)
+		r.Get(productReviewEndpoint, buildProductReviewListHandler(db, store))
+		r.With(buildAuthenticationMiddleware(store)).Post(productReviewEndpoint, buildProductReviewCreationHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get("/product_reviews", buildProductReviewModerationQueueHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Patch(specificReviewEndpoint, buildProductReviewModerationHandler(db))
+
+		// Product Prices
+		productPricesEndpoint := fmt.Sprintf("%s/prices", productEndpoint)
+		specificProductPriceEndpoint := fmt.Sprintf("%s/{currency:%s}", productPricesEndpoint, CurrencyCodePattern)
+		r.Get(productPricesEndpoint, buildProductPriceListHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificProductPriceEndpoint, buildProductPriceUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificProductPriceEndpoint, buildProductPriceDeletionHandler(db))
+
+		// Product Price Tiers
+		productPriceTiersEndpoint := fmt.Sprintf("%s/price_tiers", productEndpoint)
+		specificProductPriceTierEndpoint := fmt.Sprintf("%s/{min_quantity:%s}", productPriceTiersEndpoint, NumericPattern)
+		r.Get(fmt.Sprintf("%s/price", productEndpoint), buildProductPriceResolutionHandler(db, store))
+		r.Get(productPriceTiersEndpoint, buildProductPriceTierListHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificProductPriceTierEndpoint, buildProductPriceTierUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificProductPriceTierEndpoint, buildProductPriceTierDeletionHandler(db))
+
+		// Product Relations
+		productRelationsEndpoint := fmt.Sprintf("%s/relations", productEndpoint)
+		specificProductRelationEndpoint := fmt.Sprintf("%s/{kind:%s}/{related_sku:%s}", productRelationsEndpoint, ValidURLCharactersPattern, ValidURLCharactersPattern)
+		r.Get(productRelationsEndpoint, buildProductRelationListHandler(db, store))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(productRelationsEndpoint, buildProductRelationUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificProductRelationEndpoint, buildProductRelationDeletionHandler(db))
+
+		// Product Attributes
+		specificProductAttributeEndpoint := fmt.Sprintf("/product_attributes/{attribute_id:%s}", NumericPattern)
+		r.Get("/product_attributes", buildProductAttributeDefinitionListHandler(db))
+		r.Get(specificProductAttributeEndpoint, buildProductAttributeDefinitionRetrievalHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post("/product_attributes", buildProductAttributeDefinitionCreationHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Patch(specificProductAttributeEndpoint, buildProductAttributeDefinitionUpdateHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificProductAttributeEndpoint, buildProductAttributeDefinitionDeletionHandler(db))
+
+		// Product Translations
+		productTranslationsEndpoint := fmt.Sprintf("%s/translations", productEndpoint)
+		specificProductTranslationEndpoint := fmt.Sprintf("%s/{locale:%s}", productTranslationsEndpoint, LocalePattern)
+		optionTranslationsEndpoint := fmt.Sprintf("/product_options/{option_id:%s}/translations", NumericPattern)
+		specificOptionTranslationEndpoint := fmt.Sprintf("%s/{locale:%s}", optionTranslationsEndpoint, LocalePattern)
+		optionValueTranslationsEndpoint := fmt.Sprintf("/product_option_values/{option_value_id:%s}/translations", NumericPattern)
+		specificOptionValueTranslationEndpoint := fmt.Sprintf("%s/{locale:%s}", optionValueTranslationsEndpoint, LocalePattern)
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(productTranslationsEndpoint, buildProductTranslationListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificProductTranslationEndpoint, buildProductTranslationUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificProductTranslationEndpoint, buildProductTranslationDeletionHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(optionTranslationsEndpoint, buildProductOptionTranslationListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificOptionTranslationEndpoint, buildProductOptionTranslationUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificOptionTranslationEndpoint, buildProductOptionTranslationDeletionHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(optionValueTranslationsEndpoint, buildProductOptionValueTranslationListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificOptionValueTranslationEndpoint, buildProductOptionValueTranslationUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificOptionValueTranslationEndpoint, buildProductOptionValueTranslationDeletionHandler(db))
+
+		// Digital Products
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(fmt.Sprintf("%s/file", productEndpoint), buildProductFileUploadHandler(db, blobs))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(fmt.Sprintf("%s/download_grants", productEndpoint), buildDownloadGrantCreationHandler(db))
+
+		// Gift Cards
+		specificGiftCardEndpoint := fmt.Sprintf("/gift_cards/{code:%s}", AlphanumericPattern)
+		r.With(buildAdminAuthorizationMiddleware(store)).Post("/gift_cards", buildGiftCardCreationHandler(db, store))
+		r.Get(specificGiftCardEndpoint, buildGiftCardRetrievalHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificGiftCardEndpoint, buildGiftCardDeletionHandler(db))
+		r.With(buildAuthenticationMiddleware(store)).Post(fmt.Sprintf("%s/redeem", specificGiftCardEndpoint), buildGiftCardBalanceAdjustmentHandler(db, store, giftCardTransactionKindDebit))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(fmt.Sprintf("%s/credit", specificGiftCardEndpoint), buildGiftCardBalanceAdjustmentHandler(db, store, giftCardTransactionKindCredit))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(fmt.Sprintf("%s/transactions", specificGiftCardEndpoint), buildGiftCardTransactionListHandler(db))
+
+		// Exchange Rates
+		specificExchangeRateEndpoint := fmt.Sprintf("/exchange_rates/{currency:%s}", CurrencyCodePattern)
+		r.Get("/exchange_rates", buildExchangeRateListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificExchangeRateEndpoint, buildExchangeRateUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificExchangeRateEndpoint, buildExchangeRateDeletionHandler(db))
+
+		// Customer Groups
+		specificCustomerGroupEndpoint := fmt.Sprintf("/customer_groups/{customer_group_id:%s}", NumericPattern)
+		customerGroupMemberEndpoint := fmt.Sprintf("%s/members/{user_id:%s}", specificCustomerGroupEndpoint, NumericPattern)
+		customerGroupPricesEndpoint := fmt.Sprintf("%s/prices", specificCustomerGroupEndpoint)
+		specificCustomerGroupPriceEndpoint := fmt.Sprintf("%s/{sku:%s}", customerGroupPricesEndpoint, ValidURLCharactersPattern)
+		r.With(buildAdminAuthorizationMiddleware(store)).Get("/customer_groups", buildCustomerGroupListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post("/customer_groups", buildCustomerGroupCreationHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(specificCustomerGroupEndpoint, buildCustomerGroupRetrievalHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Patch(specificCustomerGroupEndpoint, buildCustomerGroupUpdateHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificCustomerGroupEndpoint, buildCustomerGroupDeletionHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(customerGroupMemberEndpoint, buildCustomerGroupMembershipHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(customerGroupMemberEndpoint, buildCustomerGroupMembershipDeletionHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(customerGroupPricesEndpoint, buildCustomerGroupPriceListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificCustomerGroupPriceEndpoint, buildCustomerGroupPriceUpsertHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificCustomerGroupPriceEndpoint, buildCustomerGroupPriceDeletionHandler(db))
+
+		// Inventory
+		specificInventoryLocationEndpoint := fmt.Sprintf("/inventory_locations/{location_id:%s}", NumericPattern)
+		productInventoryEndpoint := fmt.Sprintf("/product/{sku:%s}/inventory", ValidURLCharactersPattern)
+		specificProductInventoryEndpoint := fmt.Sprintf("%s/{location_id:%s}", productInventoryEndpoint, NumericPattern)
+		r.With(buildAdminAuthorizationMiddleware(store)).Get("/inventory_locations", buildInventoryLocationListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post("/inventory_locations", buildInventoryLocationCreationHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Patch(specificInventoryLocationEndpoint, buildInventoryLocationUpdateHandler(db, notifier))
+		r.With(buildAdminAuthorizationMiddleware(store)).Delete(specificInventoryLocationEndpoint, buildInventoryLocationDeletionHandler(db, notifier))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(productInventoryEndpoint, buildProductInventoryListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(specificProductInventoryEndpoint, buildInventoryLevelUpdateHandler(db, store, notifier))
+		r.With(buildAdminAuthorizationMiddleware(store)).Post(fmt.Sprintf("%s/adjust", productInventoryEndpoint), buildProductInventoryAdjustmentHandler(db, store, notifier))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get(fmt.Sprintf("%s/movements", productInventoryEndpoint), buildProductInventoryMovementListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Get("/inventory/low_stock", buildLowStockReportHandler(db))
+
+		// Reservations
+		specificReservationEndpoint := fmt.Sprintf("/reservations/{reservation_id:%s}", NumericPattern)
+		r.With(buildAuthenticationMiddleware(store)).Post("/reservations", buildReservationCreationHandler(db, store))
+		r.With(buildAuthenticationMiddleware(store)).Get(specificReservationEndpoint, buildReservationRetrievalHandler(db, store))
+		r.With(buildAuthenticationMiddleware(store)).Post(fmt.Sprintf("%s/release", specificReservationEndpoint), buildReservationReleaseHandler(db, store))
+		r.With(buildAuthenticationMiddleware(store)).Post(fmt.Sprintf("%s/confirm", specificReservationEndpoint), buildReservationConfirmationHandler(db, store, notifier))
 
 		// Product Options
 		productOptionEndpoint := fmt.Sprintf("/product/{product_id:%s}/options", NumericPattern)
 		specificOptionEndpoint := fmt.Sprintf("/product_options/{option_id:%s}", NumericPattern)
-		r.Get(productOptionEndpoint, buildProductOptionListHandler(db))
+		r.Get(productOptionEndpoint, buildProductOptionListHandler(db, store))
 		r.Post(productOptionEndpoint, buildProductOptionCreationHandler(db))
 		r.Patch(specificOptionEndpoint, buildProductOptionUpdateHandler(db))
 		r.Delete(specificOptionEndpoint, buildProductOptionDeletionHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(fmt.Sprintf("%s/order", productOptionEndpoint), buildProductOptionReorderHandler(db))
 
 		// Product Option Values
 		optionValueEndpoint := fmt.Sprintf("/product_options/{option_id:%s}/value", NumericPattern)
 		specificOptionValueEndpoint := fmt.Sprintf("/product_option_values/{option_value_id:%s}", NumericPattern)
+		optionValuesEndpoint := fmt.Sprintf("/product_options/{option_id:%s}/values", NumericPattern)
+		r.Get(optionValuesEndpoint, buildProductOptionValueListHandler(db))
+		r.With(buildAdminAuthorizationMiddleware(store)).Put(fmt.Sprintf("%s/order", optionValuesEndpoint), buildProductOptionValueReorderHandler(db))
 		r.Post(optionValueEndpoint, buildProductOptionValueCreationHandler(db))
+		r.Get(specificOptionValueEndpoint, buildProductOptionValueRetrievalHandler(db))
 		r.Patch(specificOptionValueEndpoint, buildProductOptionValueUpdateHandler(db))
 		r.Delete(specificOptionValueEndpoint, buildProductOptionValueDeletionHandler(db))
 
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	// a product's stock policy decides whether it can still be sold once its quantity runs out
+	stockPolicyDeny      = "deny"
+	stockPolicyBackorder = "backorder"
+	stockPolicyPreorder  = "preorder"
+
+	// availabilities are named the way product feeds name them
+	productAvailabilityInStock    = "in_stock"
+	productAvailabilityOutOfStock = "out_of_stock"
+	productAvailabilityBackorder  = "backorder"
+	productAvailabilityPreorder   = "preorder"
+
+	// a product can be sold past zero while it's on backorder, or while it's up for pre-order and hasn't come out yet
+	productOversellAllowedCondition = `(products.stock_policy = 'backorder' OR (products.stock_policy = 'preorder' AND products.available_on > NOW()))`
+	// productStockFloorExpression is the lowest a product's quantity may be taken to, which is NULL when there's no
+	// limit to how far it can be oversold. Stock checks compare against it with IS NOT FALSE so that NULL lets them through.
+	productStockFloorExpression = `(CASE WHEN ` + productOversellAllowedCondition + ` THEN -products.max_oversell ELSE 0 END)`
+)
+
+func stockPolicyIsValid(policy string) bool {
+	switch policy {
+	case stockPolicyDeny, stockPolicyBackorder, stockPolicyPreorder:
+		return true
+	}
+	return false
+}
+
+// availability describes whether a product can be ordered right now, and if so whether it'll ship straight away.
+// It follows the same rules as productStockFloorExpression, so a product is only orderable when stock checks agree.
+func (p *Product) availability(now time.Time) string {
+	withinOversellLimit := p.MaxOversell == nil || p.Quantity > -int(*p.MaxOversell)
+	switch {
+	case p.StockPolicy == stockPolicyPreorder && p.AvailableOn.After(now):
+		if withinOversellLimit {
+			return productAvailabilityPreorder
+		}
+	case p.Quantity > 0:
+		return productAvailabilityInStock
+	case p.StockPolicy == stockPolicyBackorder && withinOversellLimit:
+		return productAvailabilityBackorder
+	}
+	return productAvailabilityOutOfStock
+}
+
+// canBeTakenTo reports whether the product's stock may be taken down to the given quantity, following the same rules
+// as productStockFloorExpression
+func (p *Product) canBeTakenTo(quantity int, now time.Time) bool {
+	if quantity >= 0 {
+		return true
+	}
+	oversellAllowed := p.StockPolicy == stockPolicyBackorder || (p.StockPolicy == stockPolicyPreorder && p.AvailableOn.After(now))
+	return oversellAllowed && (p.MaxOversell == nil || quantity >= -int(*p.MaxOversell))
+}
+
+// deriveProductAvailabilities sets the availability of each of the given products, once anything that changes their
+// stock (like their bundle) has been attached
+func deriveProductAvailabilities(products []Product) {
+	now := time.Now()
+	for i := range products {
+		products[i].Availability = products[i].availability(now)
+	}
+}
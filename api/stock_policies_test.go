@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockPolicyIsValid(t *testing.T) {
+	t.Parallel()
+	for _, policy := range []string{stockPolicyDeny, stockPolicyBackorder, stockPolicyPreorder} {
+		assert.True(t, stockPolicyIsValid(policy), "%s should be a valid stock policy", policy)
+	}
+	assert.False(t, stockPolicyIsValid(""))
+	assert.False(t, stockPolicyIsValid("whenever"))
+}
+
+func TestProductAvailability(t *testing.T) {
+	t.Parallel()
+	now := generateExampleTimeForTests()
+	released := now.Add(-24 * time.Hour)
+	unreleased := now.Add(24 * time.Hour)
+	limit := uint32(5)
+
+	testCases := []struct {
+		name     string
+		product  Product
+		expected string
+	}{
+		{"in stock", Product{StockPolicy: stockPolicyDeny, Quantity: 3, AvailableOn: released}, productAvailabilityInStock},
+		{"sold out", Product{StockPolicy: stockPolicyDeny, AvailableOn: released}, productAvailabilityOutOfStock},
+		{"backordered without a limit", Product{StockPolicy: stockPolicyBackorder, Quantity: -100, AvailableOn: released}, productAvailabilityBackorder},
+		{"backordered within its limit", Product{StockPolicy: stockPolicyBackorder, Quantity: -4, MaxOversell: &limit, AvailableOn: released}, productAvailabilityBackorder},
+		{"backordered up to its limit", Product{StockPolicy: stockPolicyBackorder, Quantity: -5, MaxOversell: &limit, AvailableOn: released}, productAvailabilityOutOfStock},
+		{"backorderable but in stock", Product{StockPolicy: stockPolicyBackorder, Quantity: 1, AvailableOn: released}, productAvailabilityInStock},
+		{"up for pre-order", Product{StockPolicy: stockPolicyPreorder, Quantity: 10, AvailableOn: unreleased}, productAvailabilityPreorder},
+		{"pre-ordered up to its limit", Product{StockPolicy: stockPolicyPreorder, Quantity: -5, MaxOversell: &limit, AvailableOn: unreleased}, productAvailabilityOutOfStock},
+		{"pre-ordered and since released", Product{StockPolicy: stockPolicyPreorder, Quantity: 2, AvailableOn: released}, productAvailabilityInStock},
+		{"pre-ordered and released without stock", Product{StockPolicy: stockPolicyPreorder, Quantity: -2, AvailableOn: released}, productAvailabilityOutOfStock},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.product.availability(now), "a product that's %s should be %s", tc.name, tc.expected)
+	}
+}
+
+func TestProductCanBeTakenTo(t *testing.T) {
+	t.Parallel()
+	now := generateExampleTimeForTests()
+	released := now.Add(-24 * time.Hour)
+	unreleased := now.Add(24 * time.Hour)
+	limit := uint32(5)
+
+	assert.True(t, (&Product{StockPolicy: stockPolicyDeny}).canBeTakenTo(0, now))
+	assert.False(t, (&Product{StockPolicy: stockPolicyDeny}).canBeTakenTo(-1, now))
+	assert.True(t, (&Product{StockPolicy: stockPolicyBackorder}).canBeTakenTo(-100, now))
+	assert.True(t, (&Product{StockPolicy: stockPolicyBackorder, MaxOversell: &limit}).canBeTakenTo(-5, now))
+	assert.False(t, (&Product{StockPolicy: stockPolicyBackorder, MaxOversell: &limit}).canBeTakenTo(-6, now))
+	assert.True(t, (&Product{StockPolicy: stockPolicyPreorder, AvailableOn: unreleased}).canBeTakenTo(-1, now))
+	assert.False(t, (&Product{StockPolicy: stockPolicyPreorder, AvailableOn: released}).canBeTakenTo(-1, now))
+}
+
+func TestDeriveProductAvailabilities(t *testing.T) {
+	t.Parallel()
+	products := []Product{
+		{StockPolicy: stockPolicyDeny, Quantity: 1},
+		{StockPolicy: stockPolicyBackorder},
+	}
+	deriveProductAvailabilities(products)
+	assert.Equal(t, productAvailabilityInStock, products[0].Availability)
+	assert.Equal(t, productAvailabilityBackorder, products[1].Availability)
+}
@@ -115,17 +115,59 @@ type UserUpdateInput struct {
 func validateSessionCookieMiddleware(res http.ResponseWriter, req *http.Request, store *sessions.CookieStore, next http.HandlerFunc) {
 	session, err := store.Get(req, dairycartCookieName)
 	if auth, ok := session.Values[sessionAuthorizedKeyName].(bool); !ok || !auth || err != nil {
-		res.WriteHeader(http.StatusUnauthorized)
-		errRes := &ErrorResponse{
-			Status:  http.StatusUnauthorized,
-			Message: "Unauthorized",
-		}
-		json.NewEncoder(res).Encode(errRes)
+		notifyOfUnauthorizedRequest(res)
 		return
 	}
 	next(res, req)
 }
 
+// buildAuthenticationMiddleware wraps validateSessionCookieMiddleware so it can be mounted on a chi router
+func buildAuthenticationMiddleware(store *sessions.CookieStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			validateSessionCookieMiddleware(res, req, store, next.ServeHTTP)
+		})
+	}
+}
+
+// buildAdminAuthorizationMiddleware only allows requests from logged in admin users through
+func buildAdminAuthorizationMiddleware(store *sessions.CookieStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			validateSessionCookieMiddleware(res, req, store, func(res http.ResponseWriter, req *http.Request) {
+				if !sessionBelongsToAdmin(req, store) {
+					notifyOfForbiddenRequest(res)
+					return
+				}
+				next.ServeHTTP(res, req)
+			})
+		})
+	}
+}
+
+// retrieveUserIDFromSession returns the ID of the logged in user making a request, if there is one
+func retrieveUserIDFromSession(req *http.Request, store *sessions.CookieStore) (uint64, bool) {
+	session, err := store.Get(req, dairycartCookieName)
+	if err != nil {
+		return 0, false
+	}
+	if auth, ok := session.Values[sessionAuthorizedKeyName].(bool); !ok || !auth {
+		return 0, false
+	}
+	userID, ok := session.Values[sessionUserIDKeyName].(uint64)
+	return userID, ok
+}
+
+func sessionBelongsToAdmin(req *http.Request, store *sessions.CookieStore) bool {
+	session, err := store.Get(req, dairycartCookieName)
+	if err != nil {
+		return false
+	}
+	auth, _ := session.Values[sessionAuthorizedKeyName].(bool)
+	admin, _ := session.Values[sessionAdminKeyName].(bool)
+	return auth && admin
+}
+
 func passwordIsValid(s string) bool {
 	var hasNumber bool
 	var hasUpper bool
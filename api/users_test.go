@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
@@ -190,6 +191,24 @@ func setExpectationsForLoginAttemptCreationQuery(mock sqlmock.Sqlmock, username
 		WillReturnError(err)
 }
 
+// attachSessionCookieToRequest logs a request in as the given user, the same way the login handler would
+func attachSessionCookieToRequest(t *testing.T, testUtil *TestUtil, req *http.Request, userID uint64, isAdmin bool) {
+	cookieReq, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	session, err := testUtil.Store.Get(cookieReq, dairycartCookieName)
+	assert.Nil(t, err)
+	session.Values[sessionUserIDKeyName] = userID
+	session.Values[sessionAuthorizedKeyName] = true
+	session.Values[sessionAdminKeyName] = isAdmin
+
+	recorder := httptest.NewRecorder()
+	err = session.Save(cookieReq, recorder)
+	assert.Nil(t, err)
+	for _, cookie := range (&http.Response{Header: recorder.Header()}).Cookies() {
+		req.AddCookie(cookie)
+	}
+}
+
 func TestValidateSessionCookieMiddleware(t *testing.T) {
 	t.Parallel()
 
@@ -229,6 +248,78 @@ func TestValidateSessionCookieMiddlewareWithInvalidCookie(t *testing.T) {
 	assert.False(t, handlerWasCalled)
 }
 
+func TestAdminAuthorizationMiddleware(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	handlerWasCalled := false
+	exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+		handlerWasCalled = true
+	}
+	middleware := buildAdminAuthorizationMiddleware(testUtil.Store)
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, true)
+
+	middleware(http.HandlerFunc(exampleHandler)).ServeHTTP(testUtil.Response, req)
+	assert.True(t, handlerWasCalled)
+}
+
+func TestAdminAuthorizationMiddlewareForNonAdminUser(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	handlerWasCalled := false
+	exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+		handlerWasCalled = true
+	}
+	middleware := buildAdminAuthorizationMiddleware(testUtil.Store)
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 1, false)
+
+	middleware(http.HandlerFunc(exampleHandler)).ServeHTTP(testUtil.Response, req)
+	assert.False(t, handlerWasCalled)
+	assert.Equal(t, http.StatusForbidden, testUtil.Response.Code, "status code should be 403")
+}
+
+func TestAdminAuthorizationMiddlewareWithoutSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	handlerWasCalled := false
+	exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+		handlerWasCalled = true
+	}
+	middleware := buildAdminAuthorizationMiddleware(testUtil.Store)
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+
+	middleware(http.HandlerFunc(exampleHandler)).ServeHTTP(testUtil.Response, req)
+	assert.False(t, handlerWasCalled)
+	assert.Equal(t, http.StatusUnauthorized, testUtil.Response.Code, "status code should be 401")
+}
+
+func TestRetrieveUserIDFromSession(t *testing.T) {
+	t.Parallel()
+	testUtil := setupTestVariables(t)
+
+	anonymousReq, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	_, ok := retrieveUserIDFromSession(anonymousReq, testUtil.Store)
+	assert.False(t, ok, "anonymous requests shouldn't have a user ID")
+
+	req, err := http.NewRequest(http.MethodGet, "", nil)
+	assert.Nil(t, err)
+	attachSessionCookieToRequest(t, testUtil, req, 123, false)
+	userID, ok := retrieveUserIDFromSession(req, testUtil.Store)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(123), userID)
+}
+
 func TestPasswordIsValid(t *testing.T) {
 	inputOutputMap := map[string]bool{
 		// the worst password ever
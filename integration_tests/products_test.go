@@ -34,7 +34,7 @@ func createProductCreationBody(sku string, upc string) string {
 			"price": 12.34,
 			"on_sale": true,
 			"sale_price": 10.00,
-			"cost": 5,
+			"cost": 5.00,
 			"product_weight": 9,
 			"product_height": 9,
 			"product_width": 9,
@@ -104,11 +104,14 @@ func TestProductRetrievalRoute(t *testing.T) {
 			"manufacturer": "Record Company",
 			"brand": "Your Favorite Band",
 			"quantity": 666,
+			"stock_policy": "deny",
+			"restock_on": "",
+			"availability": "in_stock",
 			"taxable": true,
-			"price": 20,
+			"price": 20.00,
 			"on_sale": false,
-			"sale_price": 0,
-			"cost": 10,
+			"sale_price": 0.00,
+			"cost": 10.00,
 			"product_weight": 1,
 			"product_height": 5,
 			"product_width": 5,
@@ -117,7 +120,10 @@ func TestProductRetrievalRoute(t *testing.T) {
 			"package_height": 5,
 			"package_width": 5,
 			"package_length": 5,
-			"quantity_per_package": 1
+			"quantity_per_package": 1,
+			"status": "published",
+			"discontinued_on": "",
+			"attributes": {}
 		}
 	`)
 	assert.Equal(t, expected, actual, "product retrieval response should contain a complete product")
@@ -145,11 +151,14 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"manufacturer": "Record Company",
 					"brand": "Your Favorite Band",
 					"quantity": 666,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
 					"taxable": true,
-					"price": 20,
+					"price": 20.00,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 10,
+					"sale_price": 0.00,
+					"cost": 10.00,
 					"product_weight": 1,
 					"product_height": 5,
 					"product_width": 5,
@@ -158,21 +167,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 5,
 					"package_width": 5,
 					"package_length": 5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Sleeping People - Sleeping People",
 					"subtitle": "A solid math rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "sleeping-people",
-					"upc": "656605908410",
+					"upc": "00656605908410",
 					"manufacturer": "Record Company",
 					"brand": "Sleeping People",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -181,21 +197,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Jaga Jazzist - One Armed Bandit",
 					"subtitle": "A solid jazz album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "one-armed-bandit",
-					"upc": "5021392578187",
+					"upc": "05021392578187",
 					"manufacturer": "Record Company",
 					"brand": "Jaga Jazzist",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -204,7 +227,10 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Cloudkicker - Let Yourself Be Huge",
 					"subtitle": "A solid instrumental album",
@@ -214,11 +240,15 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"manufacturer": "Record Company",
 					"brand": "Cloudkicker",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -227,21 +257,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Animals As Leaders - The Joy Of Motion",
 					"subtitle": "A solid prog metal album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "the-joy-of-motion",
-					"upc": "817424013895",
+					"upc": "00817424013895",
 					"manufacturer": "Record Company",
 					"brand": "Animals As Leaders",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -250,21 +287,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Mort Garson - Mother Earth's Plantasia",
 					"subtitle": "A solid synth album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "mother-earths-plantasia",
-					"upc": "5291103812552",
+					"upc": "05291103812552",
 					"manufacturer": "Record Company",
 					"brand": "Mort Garson",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -273,21 +317,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Camel - The Snow Goose",
 					"subtitle": "A solid prog rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "the-snow-goose",
-					"upc": "600753356661",
+					"upc": "00600753356661",
 					"manufacturer": "Record Company",
 					"brand": "Camel",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -296,7 +347,10 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Piglet - Lava Land",
 					"subtitle": "Another solid math rock album",
@@ -306,11 +360,15 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"manufacturer": "Record Company",
 					"brand": "Piglet",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -319,21 +377,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Tera Melos - Untitled",
 					"subtitle": "Yet another solid math rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "untitled",
-					"upc": "634457550513",
+					"upc": "00634457550513",
 					"manufacturer": "Record Company",
 					"brand": "Tera Melos",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -342,21 +407,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Frank Zappa - Jazz From Hell",
 					"subtitle": "A solid Zappa album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "jazz-from-hell",
-					"upc": "013347420516",
+					"upc": "00013347420516",
 					"manufacturer": "Record Company",
 					"brand": "Frank Zappa",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -365,21 +437,28 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "CHON - Newborn Sun",
 					"subtitle": "Yet another solid math rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "newborn-sun",
-					"upc": "794558090315",
+					"upc": "00794558090315",
 					"manufacturer": "Record Company",
 					"brand": "CHON",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -388,7 +467,10 @@ func TestProductListRouteWithDefaultFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}
 			]
 		}
@@ -418,15 +500,19 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"subtitle": "A solid synth album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "mother-earths-plantasia",
-					"upc": "5291103812552",
+					"upc": "05291103812552",
 					"manufacturer": "Record Company",
 					"brand": "Mort Garson",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -435,21 +521,28 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Camel - The Snow Goose",
 					"subtitle": "A solid prog rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "the-snow-goose",
-					"upc": "600753356661",
+					"upc": "00600753356661",
 					"manufacturer": "Record Company",
 					"brand": "Camel",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -458,7 +551,10 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Piglet - Lava Land",
 					"subtitle": "Another solid math rock album",
@@ -468,11 +564,15 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"manufacturer": "Record Company",
 					"brand": "Piglet",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -481,21 +581,28 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Tera Melos - Untitled",
 					"subtitle": "Yet another solid math rock album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "untitled",
-					"upc": "634457550513",
+					"upc": "00634457550513",
 					"manufacturer": "Record Company",
 					"brand": "Tera Melos",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -504,21 +611,28 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}, {
 					"name": "Frank Zappa - Jazz From Hell",
 					"subtitle": "A solid Zappa album",
 					"description": "Arbitrary description can go here because real product descriptions are technically copywritten.",
 					"sku": "jazz-from-hell",
-					"upc": "013347420516",
+					"upc": "00013347420516",
 					"manufacturer": "Record Company",
 					"brand": "Frank Zappa",
 					"quantity": 123,
+					"stock_policy": "deny",
+					"restock_on": "",
+					"availability": "in_stock",
+					"digital": true,
 					"taxable": true,
 					"price": 12.34,
 					"on_sale": false,
-					"sale_price": 0,
-					"cost": 5,
+					"sale_price": 0.00,
+					"cost": 5.00,
 					"product_weight": 1,
 					"product_height": 12,
 					"product_width": 12,
@@ -527,7 +641,10 @@ func TestProductListRouteWithCustomFilter(t *testing.T) {
 					"package_height": 12,
 					"package_width": 12,
 					"package_length": 0.5,
-					"quantity_per_package": 1
+					"quantity_per_package": 1,
+					"status": "published",
+					"discontinued_on": "",
+					"attributes": {}
 				}
 			]
 		}
@@ -553,11 +670,14 @@ func TestProductUpdateRoute(t *testing.T) {
 			"manufacturer": "Record Company",
 			"brand": "Your Favorite Band",
 			"quantity": 666,
+			"stock_policy": "deny",
+			"restock_on": "",
+			"availability": "in_stock",
 			"taxable": true,
-			"price": 20,
+			"price": 20.00,
 			"on_sale": false,
-			"sale_price": 0,
-			"cost": 10,
+			"sale_price": 0.00,
+			"cost": 10.00,
 			"product_weight": 1,
 			"product_height": 5,
 			"product_width": 5,
@@ -566,7 +686,10 @@ func TestProductUpdateRoute(t *testing.T) {
 			"package_height": 5,
 			"package_width": 5,
 			"package_length": 5,
-			"quantity_per_package": 1
+			"quantity_per_package": 1,
+			"status": "published",
+			"discontinued_on": "",
+			"attributes": {}
 		}
 	`)
 	assert.Equal(t, expected, actual, "product response upon update should reflect the updated fields")
@@ -613,7 +736,7 @@ func TestProductCreation(t *testing.T) {
 
 	testSKU := "test-product-creation"
 	testProductCreation := func(t *testing.T) {
-		newProductJSON := createProductCreationBody(testSKU, "0123456789")
+		newProductJSON := createProductCreationBody(testSKU, "036000291452")
 		resp, err := createProduct(newProductJSON)
 		assert.Nil(t, err)
 		assert.Equal(t, http.StatusCreated, resp.StatusCode, "creating a product that doesn't exist should respond 201")
@@ -627,15 +750,18 @@ func TestProductCreation(t *testing.T) {
 				"subtitle": "this is a product",
 				"description": "this product is neat or maybe its not who really knows for sure?",
 				"sku": "%s",
-				"upc": "0123456789",
+				"upc": "00036000291452",
 				"manufacturer": "Manufacturer",
 				"brand": "Brand",
 				"quantity": 123,
+				"stock_policy": "deny",
+				"restock_on": "",
+				"availability": "in_stock",
 				"taxable": false,
 				"price": 12.34,
 				"on_sale": true,
-				"sale_price": 10,
-				"cost": 5,
+				"sale_price": 10.00,
+				"cost": 5.00,
 				"product_weight": 9,
 				"product_height": 9,
 				"product_width": 9,
@@ -644,7 +770,10 @@ func TestProductCreation(t *testing.T) {
 				"package_height": 9,
 				"package_width": 9,
 				"package_length": 9,
-				"quantity_per_package": 3
+				"quantity_per_package": 3,
+				"status": "published",
+				"discontinued_on": "",
+				"attributes": {}
 			}
 		`, testSKU))
 		assert.Equal(t, expected, actual, "product creation route should respond with created product body")
@@ -698,10 +827,10 @@ func TestProductCreationWithAlreadyExistentSKU(t *testing.T) {
 			"brand": "Your Favorite Band",
 			"quantity": 666,
 			"taxable": true,
-			"price": 20,
+			"price": 20.00,
 			"on_sale": false,
-			"sale_price": 0,
-			"cost": 10,
+			"sale_price": 0.00,
+			"cost": 10.00,
 			"product_weight": 1,
 			"product_height": 5,
 			"product_width": 5,
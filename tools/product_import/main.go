@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// these mirror the response structs in the API, and are duplicated here so this tool can be built on its own
+type productImportRowError struct {
+	Row     uint64 `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type productImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	Upsert    bool                    `json:"upsert"`
+	Atomic    bool                    `json:"atomic"`
+	Processed uint64                  `json:"processed"`
+	Created   uint64                  `json:"created"`
+	Updated   uint64                  `json:"updated"`
+	Failed    uint64                  `json:"failed"`
+	Errors    []productImportRowError `json:"errors"`
+}
+
+func failIfErr(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func inferFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+	return ""
+}
+
+func main() {
+	host := flag.String("host", "http://localhost", "the Dairycart instance to import products into")
+	format := flag.String("format", "", "the format of the import file, either csv or ndjson (inferred from the file extension by default)")
+	profile := flag.String("profile", "", "a JSON file mapping CSV column headers to product fields")
+	dryRun := flag.Bool("dry-run", false, "validate every row without saving anything")
+	upsert := flag.Bool("upsert", false, "update products whose sku already exists instead of reporting an error")
+	atomic := flag.Bool("atomic", false, "only save products if every row in the file is valid")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] <file>\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	filename := flag.Arg(0)
+
+	if *format == "" {
+		*format = inferFormat(filename)
+		if *format == "" {
+			log.Fatalf("unable to determine the format of %s, please provide one with -format", filename)
+		}
+	}
+
+	body, err := ioutil.ReadFile(filename)
+	failIfErr(err)
+
+	params := url.Values{}
+	params.Set("format", *format)
+	params.Set("dry_run", strconv.FormatBool(*dryRun))
+	params.Set("upsert", strconv.FormatBool(*upsert))
+	params.Set("atomic", strconv.FormatBool(*atomic))
+	if *profile != "" {
+		mapping, err := ioutil.ReadFile(*profile)
+		failIfErr(err)
+		params.Set("mapping", string(mapping))
+	}
+
+	importURL := fmt.Sprintf("%s/v1/products/import?%s", strings.TrimRight(*host, "/"), params.Encode())
+	res, err := http.Post(importURL, "", bytes.NewReader(body))
+	failIfErr(err)
+	defer res.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(res.Body)
+	failIfErr(err)
+
+	// atomic imports that fail respond 400 with a report, anything else that isn't a 200 is an ordinary error
+	errRes := &struct {
+		Message string `json:"message"`
+	}{}
+	if res.StatusCode != http.StatusOK {
+		if json.Unmarshal(responseBody, errRes) == nil && errRes.Message != "" {
+			log.Fatalf("import failed with status %d: %s", res.StatusCode, errRes.Message)
+		}
+	}
+
+	report := &productImportReport{}
+	failIfErr(json.Unmarshal(responseBody, report))
+
+	verb := "imported"
+	if report.DryRun {
+		verb = "would have imported"
+	}
+	fmt.Printf("processed %d rows, %s %d new and %d existing products\n", report.Processed, verb, report.Created, report.Updated)
+	for _, rowErr := range report.Errors {
+		fmt.Printf("\trow %d (%s): %s\n", rowErr.Row, rowErr.SKU, rowErr.Message)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -69,10 +69,33 @@ func main() {
 		and this code doesn't matter anyway.
 	*/
 	codeFilesToTestFilesMap := map[string]string{
+		"api/barcodes.go":              "api/barcodes_test.go",
+		"api/blob_store.go":            "api/blob_store_test.go",
+		"api/currencies.go":            "api/currencies_test.go",
+		"api/customer_groups.go":       "api/customer_groups_test.go",
+		"api/digital_products.go":      "api/digital_products_test.go",
+		"api/gift_cards.go":            "api/gift_cards_test.go",
 		"api/helpers.go":               "api/helpers_test.go",
+		"api/inventory.go":             "api/inventory_test.go",
+		"api/inventory_movements.go":   "api/inventory_movements_test.go",
+		"api/reservations.go":          "api/reservations_test.go",
+		"api/low_stock.go":             "api/low_stock_test.go",
+		"api/stock_policies.go":        "api/stock_policies_test.go",
+		"api/money.go":                 "api/money_test.go",
 		"api/product_option_values.go": "api/product_option_values_test.go",
 		"api/product_options.go":       "api/product_options_test.go",
 		"api/products.go":              "api/products_test.go",
+		"api/product_imports.go":       "api/product_imports_test.go",
+		"api/product_exports.go":       "api/product_exports_test.go",
+		"api/product_reviews.go":       "api/product_reviews_test.go",
+		"api/product_prices.go":        "api/product_prices_test.go",
+		"api/product_price_tiers.go":   "api/product_price_tiers_test.go",
+		"api/product_bundles.go":       "api/product_bundles_test.go",
+		"api/product_relations.go":     "api/product_relations_test.go",
+		"api/product_archive.go":       "api/product_archive_test.go",
+		"api/product_revisions.go":     "api/product_revisions_test.go",
+		"api/product_attributes.go":    "api/product_attributes_test.go",
+		"api/product_translations.go":  "api/product_translations_test.go",
 		"api/queries.go":               "api/queries_test.go",
 		"api/discounts.go":             "api/discounts_test.go",
 	}